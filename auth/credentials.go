@@ -78,6 +78,10 @@ type Credential struct {
 }
 
 func (t *Credential) Refresh(ctx context.Context) error {
+	if err := CheckSecureURL((*url.URL)(&t.AuthURI)); err != nil {
+		return fmt.Errorf("refresh: %w", err)
+	}
+
 	switch t.Type {
 	case TypeApiKey:
 		req, err := http.NewRequestWithContext(ctx,
@@ -87,7 +91,7 @@ func (t *Credential) Refresh(ctx context.Context) error {
 		}
 		req.Header.Set("Authorization", "Bearer "+t.ApiKey)
 
-		rsp, err := http.DefaultClient.Do(req)
+		rsp, err := HTTPClient(ctx).Do(req)
 		if err != nil {
 			return fmt.Errorf("apikey refresh: %w", err)
 		}
@@ -398,7 +402,7 @@ func FetchColumnarLicense(ctx context.Context, cred *Credential) error {
 	}
 
 	req.Header.Add("authorization", "Bearer "+authToken)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := HTTPClient(ctx).Do(req)
 	if err != nil {
 		return err
 	}
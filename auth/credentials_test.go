@@ -170,6 +170,25 @@ func TestCredential_Refresh_ApiKey(t *testing.T) {
 		assert.Equal(t, "new-token", cred.Token)
 	})
 
+	t.Run("refresh uses the client from the context", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"access_token": "new-token"}`))
+		}))
+		defer server.Close()
+
+		serverURL, _ := url.Parse(server.URL)
+		cred := &Credential{
+			Type:    TypeApiKey,
+			AuthURI: Uri(*serverURL),
+			ApiKey:  "test-api-key",
+		}
+
+		// http.DefaultClient doesn't trust the test server's certificate.
+		assert.Error(t, cred.Refresh(t.Context()))
+		assert.NoError(t, cred.Refresh(WithHTTPClient(t.Context(), server.Client())))
+		assert.Equal(t, "new-token", cred.Token)
+	})
+
 	t.Run("failed refresh with apikey - server error", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusUnauthorized)
@@ -204,6 +223,44 @@ func TestCredential_Refresh_ApiKey(t *testing.T) {
 		err := cred.Refresh(t.Context())
 		assert.Error(t, err)
 	})
+
+	t.Run("plain http to a remote host", func(t *testing.T) {
+		cred := &Credential{
+			Type:    TypeApiKey,
+			AuthURI: Uri(url.URL{Scheme: "http", Host: "registry.example.com"}),
+			ApiKey:  "test-api-key",
+		}
+
+		err := cred.Refresh(t.Context())
+		assert.ErrorIs(t, err, ErrInsecureURL)
+	})
+}
+
+func TestCheckSecureURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		secure bool
+	}{
+		{"https://registry.example.com", true},
+		{"http://localhost:8080", true},
+		{"http://LOCALHOST", true},
+		{"http://127.0.0.1:8080", true},
+		{"http://[::1]:8080", true},
+		{"http://registry.example.com", false},
+		{"http://10.0.0.1", false},
+		{"http://localhost.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			if tt.secure {
+				assert.NoError(t, CheckSecureURL(u))
+			} else {
+				assert.ErrorIs(t, CheckSecureURL(u), ErrInsecureURL)
+			}
+		})
+	}
 }
 
 func TestLoadCreds(t *testing.T) {
@@ -0,0 +1,66 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrInsecureURL is returned for plain http:// URLs of hosts other than the
+// local machine, which credentials must never be sent to.
+var ErrInsecureURL = errors.New("credentials can only be sent over https, or over http to localhost")
+
+// CheckSecureURL returns ErrInsecureURL if u is an http:// URL of a host
+// other than the loopback interface. Local registries, such as those from
+// dbc registry serve, may use plain http.
+func CheckSecureURL(u *url.URL) error {
+	if !strings.EqualFold(u.Scheme, "http") || isLoopback(u.Hostname()) {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", u.Redacted(), ErrInsecureURL)
+}
+
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+type httpClientKey struct{}
+
+// WithHTTPClient returns a copy of ctx carrying hc. Requests this package
+// makes with the returned context, such as token refreshes, OpenID discovery
+// and license fetches, are sent through hc so they share the proxy, CA
+// bundle and timeouts of the registry they are made for.
+func WithHTTPClient(ctx context.Context, hc *http.Client) context.Context {
+	return context.WithValue(ctx, httpClientKey{}, hc)
+}
+
+// HTTPClient returns the client set on ctx with WithHTTPClient, or
+// http.DefaultClient if there is none.
+func HTTPClient(ctx context.Context) *http.Client {
+	if hc, ok := ctx.Value(httpClientKey{}).(*http.Client); ok && hc != nil {
+		return hc
+	}
+	return http.DefaultClient
+}
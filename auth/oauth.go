@@ -65,7 +65,7 @@ func fetch[T any](ctx context.Context, u *url.URL, dest *T) error {
 	if err != nil {
 		return err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := HTTPClient(ctx).Do(req)
 	if err != nil {
 		return err
 	}
@@ -110,7 +110,7 @@ func refreshOauth(ctx context.Context, cred *Credential) error {
 		return fmt.Errorf("failed to build token request: %w", err)
 	}
 	req.Header.Add("content-type", "application/x-www-form-urlencoded")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := HTTPClient(ctx).Do(req)
 	if err != nil {
 		return err
 	}
@@ -15,13 +15,16 @@
 package dbc
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/columnar-tech/dbc/auth"
 	"github.com/columnar-tech/dbc/internal"
@@ -45,6 +48,11 @@ type clientConfig struct {
 	// When true, registry-config options (global/project) are ignored so
 	// the caller's explicit registry set is used as-is.
 	explicitRegistries bool
+	indexCacheDir      string
+	packageCacheDir    string
+	offline            bool
+	registryTimeout    time.Duration
+	network            *NetworkConfig
 }
 
 type Option func(*clientConfig)
@@ -58,8 +66,20 @@ type Client struct {
 	uid                uuid.UUID
 	setupOnce          sync.Once
 	credentialResolver func(*url.URL) (*auth.Credential, error)
+	indexCache         indexCache
+	packageCache       packageCache
+	offline            bool
+	registryTimeout    time.Duration
+	// ociTokens caches bearer tokens issued by OCI registries, keyed by
+	// host and scope.
+	ociTokens sync.Map
 }
 
+// defaultRegistryTimeout bounds each registry index fetch when neither the
+// registry nor the client configures a timeout, so one unresponsive registry
+// cannot stall every command.
+const defaultRegistryTimeout = 60 * time.Second
+
 // NewClient creates a new driver registry client with the given options.
 func NewClient(opts ...Option) (*Client, error) {
 	cfg := &clientConfig{
@@ -67,25 +87,21 @@ func NewClient(opts ...Option) (*Client, error) {
 			{BaseURL: mustParseURL("https://dbc-cdn.columnar.tech")},
 			{BaseURL: mustParseURL("https://" + auth.DefaultOauthURI())},
 		},
-		userAgent: fmt.Sprintf("dbc-cli/%s (%s; %s)", Version, runtime.GOOS, runtime.GOARCH),
+		userAgent:       fmt.Sprintf("dbc-cli/%s (%s; %s)", Version, runtime.GOOS, runtime.GOARCH),
+		indexCacheDir:   defaultIndexCacheDir(),
+		packageCacheDir: defaultPackageCacheDir(),
 	}
 
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
-	httpClient := cfg.httpClient
-	if httpClient == nil {
-		httpClient = &http.Client{
-			Transport: &uaRoundTripper{
-				RoundTripper: http.DefaultTransport,
-				userAgent:    cfg.userAgent,
-			},
-		}
-	}
-
 	if cfg.baseURL != "" {
-		cfg.registries = []Registry{{BaseURL: mustParseURL(cfg.baseURL)}}
+		base, err := parseBaseURL(cfg.baseURL)
+		if err != nil {
+			return nil, err
+		}
+		cfg.registries = []Registry{{BaseURL: base}}
 	} else if cfg.explicitRegistries {
 		// WithRegistries was passed — use the caller's list verbatim and
 		// do not merge with global/project configuration.
@@ -113,6 +129,53 @@ func NewClient(opts ...Option) (*Client, error) {
 		cfg.registries = merged
 	}
 
+	var network NetworkConfig
+	if cfg.network != nil {
+		network = *cfg.network
+	} else if cfg.globalConfig != nil {
+		network = cfg.globalConfig.Network
+	}
+
+	httpClient := cfg.httpClient
+	if httpClient == nil {
+		var extra []RegistryEntry
+		if cfg.globalConfig != nil {
+			extra = cfg.globalConfig.Registries
+		}
+		certs, err := clientCertificates(cfg.registries, extra)
+		if err != nil {
+			return nil, err
+		}
+
+		rt, err := network.transport(certs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network settings: %w", err)
+		}
+		httpClient = newHTTPClient(rt, cfg.userAgent)
+
+		// Registries that override the network settings get a client of
+		// their own. A caller-supplied client is used as-is for everything.
+		cfg.registries = slices.Clone(cfg.registries)
+		for i, r := range cfg.registries {
+			if r.Network.IsZero() {
+				continue
+			}
+			rt, err := network.merge(r.Network).transport(certs)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s: invalid network settings: %w", r.BaseURL, err)
+			}
+			cfg.registries[i].httpClient = newHTTPClient(rt, cfg.userAgent)
+		}
+	}
+
+	registryTimeout := cfg.registryTimeout
+	if registryTimeout == 0 && cfg.globalConfig != nil {
+		registryTimeout = time.Duration(cfg.globalConfig.RegistryTimeout)
+	}
+	if registryTimeout == 0 {
+		registryTimeout = defaultRegistryTimeout
+	}
+
 	credResolver := cfg.credentialResolver
 	if credResolver == nil {
 		credResolver = auth.GetCredentials
@@ -123,6 +186,10 @@ func NewClient(opts ...Option) (*Client, error) {
 		registries:         cfg.registries,
 		userAgent:          cfg.userAgent,
 		credentialResolver: credResolver,
+		indexCache:         indexCache{dir: cfg.indexCacheDir},
+		packageCache:       packageCache{dir: cfg.packageCacheDir},
+		offline:            cfg.offline,
+		registryTimeout:    registryTimeout,
 	}, nil
 }
 
@@ -155,6 +222,28 @@ func (c *Client) setup() {
 
 func (c *Client) HTTPClient() *http.Client { return c.httpClient }
 
+// registryClient returns the HTTP client for requests to r, which is the
+// client's own unless r overrides its network settings.
+func (c *Client) registryClient(r *Registry) *http.Client {
+	if r != nil && r.httpClient != nil {
+		return r.httpClient
+	}
+	return c.httpClient
+}
+
+// requestClient returns the HTTP client chosen for ctx by fetchIndex or
+// openPackage, falling back to the client's own.
+func (c *Client) requestClient(ctx context.Context) *http.Client {
+	if hc := auth.HTTPClient(ctx); hc != http.DefaultClient {
+		return hc
+	}
+	return c.httpClient
+}
+
+// Offline reports whether the client only resolves drivers and packages from
+// its local caches.
+func (c *Client) Offline() bool { return c.offline }
+
 // Registries returns the list of driver registries configured for this client.
 func (c *Client) Registries() []Registry { return c.registries }
 
@@ -183,14 +272,54 @@ func WithRegistries(r []Registry) Option {
 	}
 }
 
-// WithBaseURL sets the base URL for the driver registry.
+// WithBaseURL sets the base URL for the driver registry. A local directory
+// path may be given instead of a URL.
 func WithBaseURL(u string) Option {
 	return func(cfg *clientConfig) { cfg.baseURL = u }
 }
 
+// WithNetwork sets the proxy, CA bundle and timeouts for requests, in place
+// of the global config's [network] section. Registries with their own
+// network settings override it. Like WithUserAgent, it has no effect when a
+// custom HTTP client is provided via WithHTTPClient.
+func WithNetwork(n NetworkConfig) Option {
+	return func(cfg *clientConfig) { cfg.network = &n }
+}
+
 // WithUserAgent sets the user agent string for requests. This only takes
 // effect when no custom HTTP client is provided via WithHTTPClient; if a
 // custom client is supplied its transport is used as-is.
 func WithUserAgent(ua string) Option {
 	return func(cfg *clientConfig) { cfg.userAgent = ua }
 }
+
+// WithIndexCacheDir sets the directory used to cache registry indexes between
+// runs. Cached indexes are revalidated with If-None-Match/If-Modified-Since on
+// every fetch, so a registry that answers 304 Not Modified is not downloaded
+// again. Defaults to a directory under the user config dir; pass an empty
+// string to disable the cache.
+func WithIndexCacheDir(dir string) Option {
+	return func(cfg *clientConfig) { cfg.indexCacheDir = dir }
+}
+
+// WithPackageCacheDir sets the directory where downloaded driver tarballs are
+// kept so they can be reinstalled offline. Defaults to a directory under the
+// user config dir; pass an empty string to disable the cache.
+func WithPackageCacheDir(dir string) Option {
+	return func(cfg *clientConfig) { cfg.packageCacheDir = dir }
+}
+
+// WithOffline makes the client resolve registry indexes and driver packages
+// only from its local caches, never touching the network. Anything that was
+// not cached by an earlier online run fails with an error wrapping
+// ErrNotCached.
+func WithOffline(offline bool) Option {
+	return func(cfg *clientConfig) { cfg.offline = offline }
+}
+
+// WithRegistryTimeout sets the default timeout for fetching each registry's
+// index. Registries with their own Timeout keep it. When unset, the global
+// config's registry_timeout is used, falling back to one minute.
+func WithRegistryTimeout(d time.Duration) Option {
+	return func(cfg *clientConfig) { cfg.registryTimeout = d }
+}
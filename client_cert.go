@@ -0,0 +1,114 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// loadClientCertificate loads the PEM certificate and private key at
+// certFile and keyFile. It returns nil if neither is set.
+func loadClientCertificate(certFile, keyFile string) (*tls.Certificate, error) {
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("client_cert and client_key must be set together")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+// certHost returns the key a client certificate is looked up by for
+// requests to u.
+func certHost(u *url.URL) string { return strings.ToLower(u.Host) }
+
+// clientCertificates loads the client certificates of registries, keyed by
+// the host of each registry. When several registries on one host have a
+// certificate, the first one's is used. Registries from extra are included
+// too, after registries, so auth flows that bypass registry resolution
+// still present the certificate of a registry in the global config.
+func clientCertificates(registries []Registry, extra []RegistryEntry) (map[string]tls.Certificate, error) {
+	certs := make(map[string]tls.Certificate)
+	add := func(u *url.URL, certFile, keyFile string) error {
+		if _, ok := certs[certHost(u)]; ok {
+			return nil
+		}
+		cert, err := loadClientCertificate(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("registry %s: %w", u, err)
+		}
+		if cert != nil {
+			certs[certHost(u)] = *cert
+		}
+		return nil
+	}
+
+	for _, r := range registries {
+		if r.BaseURL == nil {
+			continue
+		}
+		if err := add(r.BaseURL, r.ClientCert, r.ClientKey); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range extra {
+		u, err := parseRegistryURL(e.URL)
+		if err != nil {
+			continue
+		}
+		if err := add(u, e.ClientCert, e.ClientKey); err != nil {
+			return nil, err
+		}
+	}
+	return certs, nil
+}
+
+// clientCertTransport sends requests to a host with a client certificate
+// through a transport presenting that certificate, and all other requests
+// through base. Each host gets a transport of its own so connections made
+// with one certificate are never reused for another host.
+type clientCertTransport struct {
+	base  *http.Transport
+	hosts map[string]*http.Transport
+}
+
+func newClientCertTransport(base *http.Transport, certs map[string]tls.Certificate) *clientCertTransport {
+	t := &clientCertTransport{base: base, hosts: make(map[string]*http.Transport, len(certs))}
+	for host, cert := range certs {
+		ht := base.Clone()
+		if ht.TLSClientConfig == nil {
+			ht.TLSClientConfig = &tls.Config{}
+		}
+		ht.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		t.hosts[host] = ht
+	}
+	return t
+}
+
+func (t *clientCertTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if ht, ok := t.hosts[certHost(req.URL)]; ok {
+		return ht.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to
+// dir, returning their paths and the parsed certificate.
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dbc test client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+func TestClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, clientCert := writeClientCert(t, dir)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	srv := httptest.NewUnstartedServer(serveTestIndex(t))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	bundle := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: srv.Certificate().Raw,
+	}), 0o644))
+	network := dbc.NetworkConfig{CABundle: bundle}
+
+	search := func(t *testing.T, opts ...dbc.Option) error {
+		c, err := dbc.NewClient(append(opts, dbc.WithIndexCacheDir(""), dbc.WithNetwork(network))...)
+		require.NoError(t, err)
+		_, err = c.Search(t.Context(), "")
+		return err
+	}
+
+	t.Run("no certificate", func(t *testing.T) {
+		assert.Error(t, search(t, dbc.WithBaseURL(srv.URL)))
+	})
+
+	t.Run("registry certificate", func(t *testing.T) {
+		assert.NoError(t, search(t, dbc.WithGlobalConfig(&dbc.GlobalConfig{
+			ReplaceDefaults: true,
+			Registries: []dbc.RegistryEntry{{
+				URL:        srv.URL,
+				ClientCert: certFile,
+				ClientKey:  keyFile,
+			}},
+		})))
+	})
+
+	t.Run("matched by host", func(t *testing.T) {
+		// The certificate is presented to the registry's host even when the
+		// registry list is replaced, as DBC_BASE_URL and login do.
+		assert.NoError(t, search(t, dbc.WithBaseURL(srv.URL+"/"), dbc.WithGlobalConfig(&dbc.GlobalConfig{
+			Registries: []dbc.RegistryEntry{{
+				URL:        srv.URL + "/other",
+				ClientCert: certFile,
+				ClientKey:  keyFile,
+			}},
+		})))
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		_, err := dbc.NewClient(dbc.WithRegistries([]dbc.Registry{{
+			BaseURL:    mustParseURL(srv.URL),
+			ClientCert: certFile,
+		}}))
+		assert.ErrorContains(t, err, "client_cert and client_key must be set together")
+	})
+}
+
+func TestLoadGlobalConfigClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "certs"), 0o755))
+	writeClientCert(t, filepath.Join(dir, "certs"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"), []byte(`
+[[registries]]
+url = "https://private.example.com"
+client_cert = "certs/client.pem"
+client_key = "certs/client-key.pem"
+`), 0o644))
+
+	cfg, err := dbc.LoadGlobalConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "certs", "client.pem"), cfg.Registries[0].ClientCert)
+	assert.Equal(t, filepath.Join(dir, "certs", "client-key.pem"), cfg.Registries[0].ClientKey)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "certs", "client-key.pem")))
+	_, err = dbc.LoadGlobalConfig(dir)
+	assert.ErrorContains(t, err, "failed to load client certificate")
+}
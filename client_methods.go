@@ -15,6 +15,7 @@
 package dbc
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -24,15 +25,19 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/columnar-tech/dbc/auth"
 	"github.com/columnar-tech/dbc/config"
-	"github.com/go-faster/yaml"
 )
 
-func (c *Client) makeRequest(ctx context.Context, u string) (*http.Response, error) {
+// makeRequest issues an authenticated GET for u. Any headers in header are
+// added to the request, e.g. conditional headers for cached indexes.
+func (c *Client) makeRequest(ctx context.Context, u string, header http.Header) (*http.Response, error) {
 	c.setup()
 
 	uri, err := url.Parse(u)
@@ -55,8 +60,16 @@ func (c *Client) makeRequest(ctx context.Context, u string) (*http.Response, err
 		if err != nil {
 			return nil, err
 		}
-		if uri.Path == "/index.yaml" {
-			req.Header.Set("Accept", "application/yaml")
+		switch path.Ext(uri.Path) {
+		case ".yaml":
+			// Registries that can are welcome to answer with the JSON
+			// index instead, which decodes much faster.
+			req.Header.Set("Accept", "application/json, application/yaml;q=0.9")
+		case ".json":
+			req.Header.Set("Accept", "application/json")
+		}
+		for k, v := range header {
+			req.Header[k] = v
 		}
 		if token != "" {
 			req.Header.Set("Authorization", "Bearer "+token)
@@ -76,7 +89,7 @@ func (c *Client) makeRequest(ctx context.Context, u string) (*http.Response, err
 	if err != nil {
 		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.requestClient(ctx).Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +103,7 @@ func (c *Client) makeRequest(ctx context.Context, u string) (*http.Response, err
 		if err != nil {
 			return nil, fmt.Errorf("failed to build retry request: %w", err)
 		}
-		resp, err = c.httpClient.Do(req)
+		resp, err = c.requestClient(ctx).Do(req)
 		if err != nil {
 			return nil, err
 		}
@@ -103,42 +116,221 @@ func (c *Client) makeRequest(ctx context.Context, u string) (*http.Response, err
 			err = ErrUnauthorizedColumnar
 		}
 		resp.Body.Close()
-		return nil, fmt.Errorf("%s%s: %w", uri.Host, uri.Path, err)
+		return nil, fmt.Errorf("%s%s: %w", uri.Host, uri.Path,
+			&statusError{code: resp.StatusCode, status: resp.Status, err: err})
 	}
 
 	return resp, nil
 }
 
 func (c *Client) getDriverListFromIndex(ctx context.Context, index *Registry) ([]Driver, error) {
-	resp, err := c.makeRequest(ctx, index.BaseURL.JoinPath("/index.yaml").String())
+	data, err := c.fetchIndex(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	return decodeIndex(data, index)
+}
+
+// fetchIndex returns the index.yaml or index.json document of index,
+// checking its signature as the registry requires. Online, remote indexes
+// are revalidated against the index cache; offline, they are served from it.
+// index.yaml is requested first, so registries that negotiate the format can
+// answer with either, and index.json is tried if it isn't found.
+func (c *Client) fetchIndex(ctx context.Context, index *Registry) ([]byte, error) {
+	names := indexFiles
+	// A registry that only publishes index.json would otherwise cost a
+	// failed request for index.yaml on every fetch.
+	if meta, ok := c.indexCache.loadMeta(index.BaseURL); ok && meta.File == jsonIndexFile && !isUncachedIndex(ctx) {
+		names = []string{jsonIndexFile, indexFile}
+	}
+
+	var firstErr error
+	for _, name := range names {
+		data, err := c.fetchIndexFile(ctx, index, name)
+		if err == nil || !isNotFound(err) {
+			return data, err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+type uncachedIndexKey struct{}
+
+// withUncachedIndex returns a context under which indexes are always fetched
+// from their registry, even in offline mode, and neither read from nor
+// stored in the index cache.
+func withUncachedIndex(ctx context.Context) context.Context {
+	return context.WithValue(ctx, uncachedIndexKey{}, true)
+}
+
+func isUncachedIndex(ctx context.Context) bool {
+	uncached, _ := ctx.Value(uncachedIndexKey{}).(bool)
+	return uncached
+}
+
+// fetchIndexFile returns name, index.yaml, index.json or one of the
+// per-driver files of a sharded index, from the registry index, as
+// fetchIndex does. Each file is cached and signed on its own.
+func (c *Client) fetchIndexFile(ctx context.Context, index *Registry, name string) ([]byte, error) {
+	// Token refreshes and license fetches go through the registry's client
+	// too, so they share its proxy and CA bundle.
+	ctx = auth.WithHTTPClient(ctx, c.registryClient(index))
+	if isFileURL(index.BaseURL) {
+		data, err := readLocalIndex(index.BaseURL, name)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := readLocalIndexSignature(index.BaseURL, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIndexSignature(index, data, sig, false); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	// The index itself is cached under the registry's URL, for
+	// compatibility with caches written before sharded indexes, whichever
+	// file it was fetched from.
+	cacheKey := index.BaseURL
+	isIndex := slices.Contains(indexFiles, name)
+	if !isIndex {
+		cacheKey = index.BaseURL.JoinPath(name)
+	}
+
+	// Cached indexes were verified when they were stored, so only whether
+	// they were signed needs checking here.
+	var (
+		cached    []byte
+		meta      indexCacheMeta
+		haveCache bool
+	)
+	uncached := isUncachedIndex(ctx)
+	if !uncached {
+		cached, meta, haveCache = c.indexCache.load(cacheKey)
+	}
+	wasSigned := haveCache && meta.Signed
+	if c.offline && !uncached {
+		if !haveCache {
+			return nil, fmt.Errorf("no cached index: %w", ErrNotCached)
+		}
+		if index.RequireSignature && !meta.Signed {
+			return nil, ErrUnsignedIndex
+		}
+		return cached, nil
+	}
+
+	if isOCIURL(index.BaseURL) {
+		if !isIndex {
+			return nil, errors.New("sharded indexes are not supported in OCI registries")
+		}
+		// The manifest names the index layer, whichever format it has.
+		return c.fetchOCIIndex(ctx, index, cached, meta, haveCache)
+	}
+
+	// The validators of an index cached from the other file don't apply.
+	revalidate := haveCache && (!isIndex || cmp.Or(meta.File, indexFile) == name)
+
+	var header http.Header
+	if revalidate && (meta.ETag != "" || meta.LastModified != "") {
+		header = make(http.Header)
+		if meta.ETag != "" {
+			header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	resp, err := c.makeRequest(ctx, index.BaseURL.JoinPath(name).String(), header)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch drivers: %w", err)
 	}
+	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
+	var data []byte
+	switch {
+	case resp.StatusCode == http.StatusNotModified && header != nil:
+		data = cached
+		// Whether the registry signs its index was settled when the cached
+		// copy was stored, so an unchanged index needs no signature request.
+		if meta.Signed || !index.RequireSignature {
+			return data, nil
+		}
+	case resp.StatusCode == http.StatusOK:
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, fmt.Errorf("failed to fetch drivers: %w", err)
+		}
+		meta = indexCacheMeta{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			File:         name,
+			ContentType:  resp.Header.Get("Content-Type"),
+		}
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("failed to fetch drivers: %w", &statusError{code: resp.StatusCode, status: resp.Status})
+	default:
 		return nil, fmt.Errorf("failed to fetch drivers: %s", resp.Status)
 	}
 
-	defer resp.Body.Close()
+	sig, err := c.fetchIndexSignature(ctx, index, servedFile(name, meta.ContentType))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIndexSignature(index, data, sig, wasSigned); err != nil {
+		return nil, err
+	}
+
+	// The cache is an optimization; failing to write it must not fail the
+	// fetch.
+	meta.Signed = sig != nil
+	if !uncached {
+		_ = c.indexCache.store(cacheKey, data, meta)
+	}
+
+	return data, nil
+}
+
+// decodeIndex parses an index.yaml or index.json document and associates every driver in it
+// with index. The drivers of a sharded index are summaries, which LoadDriver
+// completes.
+func decodeIndex(data []byte, index *Registry) ([]Driver, error) {
 	drivers := struct {
-		Name    string   `yaml:"name"`
-		Drivers []Driver `yaml:"drivers"`
+		Name    string          `yaml:"name" json:"name"`
+		Layout  string          `yaml:"layout" json:"layout"`
+		Drivers []driverSummary `yaml:"drivers" json:"drivers"`
 	}{}
 
-	if err = yaml.NewDecoder(resp.Body).Decode(&drivers); err != nil {
+	if err := unmarshalIndex(data, &drivers); err != nil {
 		return nil, fmt.Errorf("failed to parse driver registry index: %s", err)
 	}
 
+	switch drivers.Layout {
+	case "", shardedLayout:
+	default:
+		return nil, fmt.Errorf("failed to parse driver registry index: unsupported layout %q", drivers.Layout)
+	}
+
 	if drivers.Name != "" {
 		index.Name = drivers.Name
 	}
 
-	for i := range drivers.Drivers {
-		drivers.Drivers[i].Registry = index
+	result := make([]Driver, len(drivers.Drivers))
+	for i, d := range drivers.Drivers {
+		if drivers.Layout == shardedLayout {
+			var err error
+			if d.Driver, err = d.summarize(indexExt(data)); err != nil {
+				return nil, fmt.Errorf("failed to parse driver registry index: %w", err)
+			}
+		}
+		result[i] = d.Driver
+		result[i].Registry = index
 	}
 
-	result := drivers.Drivers
 	sort.Slice(result, func(i, j int) bool {
 		return result[i].Path < result[j].Path
 	})
@@ -146,21 +338,65 @@ func (c *Client) getDriverListFromIndex(ctx context.Context, index *Registry) ([
 	return result, nil
 }
 
+// timeoutFor returns the index fetch timeout for r. A zero result means
+// no timeout, which only happens for clients not built with NewClient.
+func (c *Client) timeoutFor(r *Registry) time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
+	}
+	return c.registryTimeout
+}
+
+// fetchRegistry fetches the index of r, bounded by its timeout.
+func (c *Client) fetchRegistry(ctx context.Context, r *Registry) ([]Driver, error) {
+	timeout := c.timeoutFor(r)
+	if timeout <= 0 {
+		return c.getDriverListFromIndex(ctx, r)
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	drivers, err := c.getDriverListFromIndex(fetchCtx, r)
+	if err != nil && ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+	return drivers, err
+}
+
 // Search searches for drivers matching the given pattern across all registries.
+// Registries are fetched concurrently, but results keep registry priority
+// order. Drivers from registries that could be fetched are returned alongside
+// the joined errors of those that could not. A driver published by several
+// registries is returned once per registry. SearchWithConflicts returns
+// these collisions as DriverConflicts along with the drivers; FindConflicts
+// finds them in drivers that were already fetched.
 func (c *Client) Search(ctx context.Context, pattern string) ([]Driver, error) {
+	type result struct {
+		drivers []Driver
+		err     error
+	}
+
+	results := make([]result, len(c.registries))
+	var wg sync.WaitGroup
+	for i := range c.registries {
+		wg.Go(func() {
+			results[i].drivers, results[i].err = c.fetchRegistry(ctx, &c.registries[i])
+		})
+	}
+	wg.Wait()
+
 	var (
 		allDrivers []Driver
 		totalErr   error
 	)
 
-	for i := range c.registries {
-		drivers, err := c.getDriverListFromIndex(ctx, &c.registries[i])
-		if err != nil {
-			totalErr = errors.Join(totalErr, fmt.Errorf("registry %s: %w", c.registries[i].BaseURL, err))
+	for i, res := range results {
+		if res.err != nil {
+			totalErr = errors.Join(totalErr, fmt.Errorf("registry %s: %w", c.registries[i].BaseURL.Redacted(), res.err))
 			continue
 		}
-		c.registries[i].Drivers = drivers
-		allDrivers = append(allDrivers, drivers...)
+		c.registries[i].Drivers = res.drivers
+		allDrivers = append(allDrivers, res.drivers...)
 	}
 
 	if pattern == "" {
@@ -179,24 +415,82 @@ func (c *Client) Search(ctx context.Context, pattern string) ([]Driver, error) {
 	return filtered, totalErr
 }
 
-func (c *Client) downloadPackage(ctx context.Context, pkg PkgInfo) (*os.File, error) {
+// openPackage returns the tarball for pkg as a stream along with its size, or
+// -1 if unknown. Online, the body is tee'd into the package cache as it is
+// read; offline, it is served from the cache. Packages in file:// registries
+// are always read straight from disk, and packages in oci:// registries are
+// pulled through the distribution API. If the index lists a digest or size
+// for pkg, the stream is verified against it as it is read.
+func (c *Client) openPackage(ctx context.Context, pkg PkgInfo) (io.ReadCloser, int64, error) {
 	if pkg.Path == nil {
-		return nil, fmt.Errorf("cannot download package for %s: no url set", pkg.Driver.Title)
+		return nil, 0, fmt.Errorf("cannot download package for %s: no url set", pkg.Driver.Title)
 	}
 
-	location := pkg.Path.String()
-	rsp, err := c.makeRequest(ctx, location)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download driver: %w", err)
+	if isFileURL(pkg.Path) {
+		f, size, err := openLocalPackage(pkg.Path)
+		if err != nil {
+			return nil, 0, err
+		}
+		body, err := verifyPackage(pkg, f, size)
+		return body, size, err
 	}
 
+	if c.offline {
+		f, err := c.packageCache.open(pkg.Path)
+		if err != nil {
+			return nil, 0, err
+		}
+		size := int64(-1)
+		if fi, err := f.Stat(); err == nil {
+			size = fi.Size()
+		}
+		body, err := verifyPackage(pkg, f, size)
+		return body, size, err
+	}
+
+	ctx = auth.WithHTTPClient(ctx, c.registryClient(pkg.Driver.Registry))
+	if isOCIURL(pkg.Path) {
+		body, size, err := c.openOCIPackage(ctx, pkg)
+		if err != nil {
+			return nil, 0, err
+		}
+		return c.packageCache.tee(pkg.Path, body), size, nil
+	}
+
+	rsp, err := c.makeRequest(ctx, pkg.Path.String(), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to download %s: %w", pkg.Path, err)
+	}
 	if rsp.StatusCode != http.StatusOK {
-		rsp.Body.Close()
-		return nil, fmt.Errorf("failed to download driver %s: %s", location, rsp.Status)
+		defer rsp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		if len(body) > 0 {
+			return nil, 0, fmt.Errorf("failed to download %s: %s: %s", pkg.Path, rsp.Status, body)
+		}
+		return nil, 0, fmt.Errorf("failed to download %s: %s", pkg.Path, rsp.Status)
+	}
+	// Verify before caching, so a package that fails verification is never
+	// committed to the cache.
+	body, err := verifyPackage(pkg, rsp.Body, rsp.ContentLength)
+	if err != nil {
+		return nil, 0, err
 	}
-	defer rsp.Body.Close()
+	return c.packageCache.tee(pkg.Path, body), rsp.ContentLength, nil
+}
 
-	fname := path.Base(location)
+// DownloadPackage fetches the tarball for pkg into a new temporary directory
+// and returns the open file, reporting progress to prog if it is non-nil. The
+// caller is responsible for closing the file and removing its directory. A
+// package that doesn't match the digest or size listed in the index is
+// removed and a *DigestMismatchError returned.
+func (c *Client) DownloadPackage(ctx context.Context, pkg PkgInfo, prog ProgressFunc) (*os.File, error) {
+	body, size, err := c.openPackage(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	fname := path.Base(pkg.Path.Path)
 	tmpdir, err := os.MkdirTemp(os.TempDir(), "adbc-drivers-*")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp dir: %w", err)
@@ -209,14 +503,18 @@ func (c *Client) downloadPackage(ctx context.Context, pkg PkgInfo) (*os.File, er
 		}
 	}()
 
-	output, err = os.Create(path.Join(tmpdir, fname))
+	output, err = os.Create(filepath.Join(tmpdir, fname))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp file to download to: %w", err)
 	}
 
-	if _, err = io.Copy(output, rsp.Body); err != nil {
+	pw := &progressWriter{w: output, total: size, fn: prog}
+	if _, err = io.Copy(pw, body); err != nil {
 		output.Close()
 		output = nil
+		if mismatch := (*DigestMismatchError)(nil); errors.As(err, &mismatch) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to write driver file: %w", err)
 	}
 
@@ -226,24 +524,16 @@ func (c *Client) downloadPackage(ctx context.Context, pkg PkgInfo) (*os.File, er
 // Download fetches the tarball for pkg and returns its contents as an
 // io.ReadCloser. The caller is responsible for closing the returned body.
 // Auth credentials are resolved and injected automatically, including token
-// refresh on 401.
+// refresh on 401. In offline mode the tarball is read from the package cache.
+//
+// If the index lists a sha256 digest or size for pkg, the body is verified
+// as it is read: reading it to the end returns a *DigestMismatchError rather
+// than io.EOF if it doesn't match. Callers that must not act on a mismatched
+// package before it is fully read should use DownloadPackage, which only
+// returns verified files.
 func (c *Client) Download(ctx context.Context, pkg PkgInfo) (io.ReadCloser, error) {
-	if pkg.Path == nil {
-		return nil, fmt.Errorf("cannot download package for %s: no url set", pkg.Driver.Title)
-	}
-	rsp, err := c.makeRequest(ctx, pkg.Path.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to download %s: %w", pkg.Path, err)
-	}
-	if rsp.StatusCode != http.StatusOK {
-		defer rsp.Body.Close()
-		body, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
-		if len(body) > 0 {
-			return nil, fmt.Errorf("failed to download %s: %s: %s", pkg.Path, rsp.Status, body)
-		}
-		return nil, fmt.Errorf("failed to download %s: %s", pkg.Path, rsp.Status)
-	}
-	return rsp.Body, nil
+	body, _, err := c.openPackage(ctx, pkg)
+	return body, err
 }
 
 // Install installs a driver with the given name to the specified configuration.
@@ -267,12 +557,17 @@ func (c *Client) Install(ctx context.Context, cfg config.Config, driverName stri
 		return nil, fmt.Errorf("driver %q not found", driverName)
 	}
 
-	pkg, err := found.GetPackage(nil, config.PlatformTuple(), false)
+	drv, err := c.LoadDriver(ctx, *found)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := drv.GetPackage(nil, config.PlatformTuple(), false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get package for driver %s: %w", driverName, err)
 	}
 
-	f, err := c.downloadPackage(ctx, pkg)
+	f, err := c.DownloadPackage(ctx, pkg, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download driver %s: %w", driverName, err)
 	}
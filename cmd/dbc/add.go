@@ -63,7 +63,7 @@ func driverListPath(path string) (string, error) {
 }
 
 type AddCmd struct {
-	Driver []string `arg:"positional,required" help:"One or more drivers to add, optionally with a version constraint (for example: mysql, mysql=0.1.0, mysql>=1,<2)"`
+	Driver []string `arg:"positional,required" help:"One or more drivers to add, optionally with a registry and a version constraint (for example: mysql, mysql=0.1.0, mysql>=1,<2, acme/mysql)"`
 	Path   string   `arg:"-p" placeholder:"FILE" default:"./dbc.toml" help:"Driver list to add to"`
 	Pre    bool     `arg:"--pre" help:"Allow pre-release versions implicitly"`
 	Json   bool     `arg:"--json" help:"Print output as JSON instead of plaintext"`
@@ -108,8 +108,9 @@ type addModel struct {
 
 func (m addModel) Init() tea.Cmd {
 	type driverInput struct {
-		Name string
-		Vers *semver.Constraints
+		Registry string
+		Name     string
+		Vers     *semver.Constraints
 	}
 
 	var specs []driverInput
@@ -119,7 +120,8 @@ func (m addModel) Init() tea.Cmd {
 			return errCmd("invalid driver constraint '%s': %w", d, err)
 		}
 
-		specs = append(specs, driverInput{Name: driverName, Vers: vers})
+		registry, driverName := splitDriverRef(driverName)
+		specs = append(specs, driverInput{Registry: registry, Name: driverName, Vers: vers})
 	}
 
 	return func() tea.Msg {
@@ -154,6 +156,7 @@ func (m addModel) Init() tea.Cmd {
 		}
 		f.Close()
 		readLock.Release()
+		m.list.dir = filepath.Dir(p)
 
 		if err := applyProjectRegistries(m.list); err != nil {
 			return err
@@ -178,10 +181,13 @@ func (m addModel) Init() tea.Cmd {
 				result += "\n"
 			}
 
-			drv, err := findDriver(spec.Name, drivers)
+			drv, err := findRegistryDriver(spec.Registry, spec.Name, drivers)
 			if err != nil {
 				return wrapWithRegistryContext(err, registryErrors)
 			}
+			if drv, err = loadDriver(drv); err != nil {
+				return err
+			}
 
 			if spec.Vers != nil {
 				spec.Vers.IncludePrerelease = m.Pre
@@ -207,9 +213,9 @@ func (m addModel) Init() tea.Cmd {
 			}
 
 			current, ok := m.list.Drivers[spec.Name]
-			m.list.Drivers[spec.Name] = driverSpec{Version: spec.Vers}
+			m.list.Drivers[spec.Name] = driverSpec{Registry: spec.Registry, Version: spec.Vers}
 			if m.Pre {
-				m.list.Drivers[spec.Name] = driverSpec{Version: spec.Vers, Prerelease: "allow"}
+				m.list.Drivers[spec.Name] = driverSpec{Registry: spec.Registry, Version: spec.Vers, Prerelease: "allow"}
 			}
 
 			new := m.list.Drivers[spec.Name]
@@ -231,6 +237,9 @@ func (m addModel) Init() tea.Cmd {
 			}
 
 			result += nameStyle.Render("added", spec.Name, "to driver list")
+			if spec.Registry != "" {
+				result += nameStyle.Render(" from registry", spec.Registry)
+			}
 			if spec.Vers != nil {
 				result += nameStyle.Render(" with constraint", spec.Vers.String())
 			}
@@ -253,6 +262,7 @@ func (m addModel) Init() tea.Cmd {
 				return fmt.Errorf("error re-reading driver list under lock: %w", decodeErr)
 			}
 			rf.Close()
+			current.dir = filepath.Dir(p)
 		} else if !errors.Is(err, os.ErrNotExist) {
 			return fmt.Errorf("error re-reading driver list at %s: %w", m.Path, err)
 		}
@@ -325,12 +335,14 @@ func (m addModel) FinalOutput() string {
 		drivers := make([]jsonschema.AddResponseDriver, 0, len(m.Driver))
 		for _, d := range m.Driver {
 			driverName, constraint, _ := parseDriverConstraint(d)
+			registry, driverName := splitDriverRef(driverName)
 			var constraintStr string
 			if constraint != nil {
 				constraintStr = constraint.String()
 			}
 			drivers = append(drivers, jsonschema.AddResponseDriver{
 				Name:              driverName,
+				Registry:          registry,
 				VersionConstraint: constraintStr,
 			})
 		}
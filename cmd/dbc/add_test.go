@@ -454,6 +454,45 @@ func (suite *SubcommandTestSuite) TestAdd_JSON() {
 	suite.NotEmpty(resp.DriverListPath)
 }
 
+func (suite *SubcommandTestSuite) TestAddFromRegistry() {
+	m := InitCmd{Path: filepath.Join(suite.tempdir, "dbc.toml")}.GetModel()
+	suite.runCmd(m)
+
+	m = AddCmd{
+		Path:   filepath.Join(suite.tempdir, "dbc.toml"),
+		Driver: []string{"acme/test-driver-1=1.0.0"},
+	}.GetModelCustom(
+		baseModel{getDriverRegistry: getMultiTestDriverRegistry, downloadPkg: downloadTestPkg})
+	suite.Equal("added test-driver-1 to driver list from registry acme with constraint =1.0.0\nuse `dbc sync` to install the drivers in the list",
+		suite.runCmd(m))
+
+	data, err := os.ReadFile(filepath.Join(suite.tempdir, "dbc.toml"))
+	suite.Require().NoError(err)
+	suite.Contains(string(data), "[drivers.test-driver-1]\nregistry = 'acme'\nversion = '=1.0.0'")
+
+	m = AddCmd{
+		Path:   filepath.Join(suite.tempdir, "dbc.toml"),
+		Driver: []string{"acme/test-driver-1>=1.1"},
+	}.GetModelCustom(
+		baseModel{getDriverRegistry: getMultiTestDriverRegistry, downloadPkg: downloadTestPkg})
+	suite.Contains(suite.runCmdErr(m), "error getting driver")
+
+	m = AddCmd{
+		Path:   filepath.Join(suite.tempdir, "dbc.toml"),
+		Driver: []string{"acme/test-driver-1"},
+		Json:   true,
+	}.GetModelCustom(
+		baseModel{getDriverRegistry: getMultiTestDriverRegistry, downloadPkg: downloadTestPkg})
+	out := suite.runCmd(m)
+
+	var env jsonschema.Envelope
+	suite.Require().NoError(json.Unmarshal([]byte(out), &env), "output must be valid JSON: %s", out)
+	var resp jsonschema.AddResponse
+	suite.Require().NoError(json.Unmarshal(env.Payload, &resp))
+	suite.Require().Len(resp.Drivers, 1)
+	suite.Equal(jsonschema.AddResponseDriver{Name: "test-driver-1", Registry: "acme"}, resp.Drivers[0])
+}
+
 func (suite *SubcommandTestSuite) TestAdd_JSON_Constraint() {
 	m := InitCmd{Path: filepath.Join(suite.tempdir, "dbc.toml")}.GetModel()
 	suite.runCmd(m)
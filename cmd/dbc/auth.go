@@ -32,8 +32,11 @@ import (
 	"github.com/columnar-tech/dbc/internal/jsonschema"
 )
 
+// ensureHTTPS defaults uri to https when it has no scheme. An explicit
+// http:// is kept so local registries, e.g. from dbc registry serve, can be
+// used; callers reject it for other hosts with auth.CheckSecureURL.
 func ensureHTTPS(uri string) string {
-	if !strings.HasPrefix(uri, "https://") {
+	if !strings.HasPrefix(uri, "https://") && !strings.HasPrefix(uri, "http://") {
 		return "https://" + uri
 	}
 	return uri
@@ -134,6 +137,9 @@ func (m loginModel) Init() tea.Cmd {
 	if err != nil {
 		return errCmd("invalid URI provided: %w", err)
 	}
+	if err := auth.CheckSecureURL(u); err != nil {
+		return errCmd("%w", err)
+	}
 
 	return tea.Batch(m.spinner.Tick, func() tea.Msg {
 		return u
@@ -142,7 +148,7 @@ func (m loginModel) Init() tea.Cmd {
 
 func (m loginModel) authConfig() tea.Cmd {
 	return func() tea.Msg {
-		cfg, err := auth.GetOpenIDConfig(context.TODO(), m.parsedURI)
+		cfg, err := auth.GetOpenIDConfig(authContext(m.parsedURI), m.parsedURI)
 		if err != nil {
 			return fmt.Errorf("failed to get OpenID configuration: %w", err)
 		}
@@ -152,7 +158,7 @@ func (m loginModel) authConfig() tea.Cmd {
 
 func (m loginModel) requestDeviceCode(cfg auth.OpenIDConfig) tea.Cmd {
 	return func() tea.Msg {
-		rsp, err := device.RequestCode(authHTTPClient(), cfg.DeviceAuthorizationEndpoint.String(),
+		rsp, err := device.RequestCode(authHTTPClient(m.parsedURI), cfg.DeviceAuthorizationEndpoint.String(),
 			m.oauthClientID, []string{"openid", "offline_access"})
 		if err != nil {
 			return fmt.Errorf("failed to request device code: %w", err)
@@ -172,7 +178,7 @@ func (m loginModel) apiKeyToToken() tea.Cmd {
 			ApiKey:      m.apiKey,
 		}
 
-		if err := cred.Refresh(context.TODO()); err != nil {
+		if err := cred.Refresh(authContext(m.parsedURI)); err != nil {
 			return fmt.Errorf("failed to obtain access token using provided API key: %w", err)
 		}
 
@@ -208,7 +214,7 @@ func (m loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		waitCmd := func() tea.Msg {
 			browser.OpenURL(msg.VerificationURIComplete)
-			accessToken, err := device.Wait(context.TODO(), authHTTPClient(), m.tokenURI.String(), device.WaitOptions{
+			accessToken, err := device.Wait(context.TODO(), authHTTPClient(m.parsedURI), m.tokenURI.String(), device.WaitOptions{
 				ClientID:   m.oauthClientID,
 				DeviceCode: msg,
 			})
@@ -244,7 +250,7 @@ func (m loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.storedCred = &msg.cred
 		return m, func() tea.Msg {
 			if auth.IsColumnarPrivateRegistry((*url.URL)(&msg.cred.RegistryURL)) {
-				if err := auth.FetchColumnarLicense(context.TODO(), &msg.cred); err != nil {
+				if err := auth.FetchColumnarLicense(authContext(m.parsedURI), &msg.cred); err != nil {
 					return err
 				}
 			}
@@ -333,6 +339,9 @@ func (m logoutModel) Init() tea.Cmd {
 	if err != nil {
 		return errCmd("invalid URI provided: %w", err)
 	}
+	if err := auth.CheckSecureURL(u); err != nil {
+		return errCmd("%w", err)
+	}
 
 	return func() tea.Msg {
 		return u
@@ -138,6 +138,15 @@ func (suite *SubcommandTestSuite) TestLoginCmdInvalidURL() {
 	suite.Contains(out, "Error:")
 }
 
+func (suite *SubcommandTestSuite) TestLoginCmdInsecureURL() {
+	cmd := LoginCmd{RegistryURL: "http://registry.example.com", ApiKey: "test-api-key"}
+	out := suite.runCmdErr(cmd.GetModelCustom(baseModel{
+		getDriverRegistry: getTestDriverRegistry,
+		downloadPkg:       downloadTestPkg,
+	}))
+	suite.Contains(out, "credentials can only be sent over https")
+}
+
 func (suite *SubcommandTestSuite) TestLoginCmdApiKeyAuthFails() {
 	// Setup temp credential path
 	tmpDir := suite.T().TempDir()
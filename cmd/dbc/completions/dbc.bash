@@ -4,7 +4,7 @@ _dbc() {
     local cur prev words cword
     _init_completion || return
 
-    local subcommands="install uninstall list init add sync search info docs remove completion auth"
+    local subcommands="install uninstall list init add sync lock upgrade outdated mirror search info docs remove completion auth registry"
     local global_opts="--help -h --version --quiet -q"
 
     # If we're completing the first argument (subcommand)
@@ -35,6 +35,18 @@ _dbc() {
         sync)
             _dbc_sync_completions
             ;;
+        lock)
+            _dbc_lock_completions
+            ;;
+        upgrade)
+            _dbc_upgrade_completions
+            ;;
+        outdated)
+            _dbc_outdated_completions
+            ;;
+        mirror)
+            _dbc_mirror_completions
+            ;;
         search)
             _dbc_search_completions
             ;;
@@ -53,6 +65,9 @@ _dbc() {
         auth)
             _dbc_auth_completions
             ;;
+        registry)
+            _dbc_registry_completions
+            ;;
         *)
             COMPREPLY=()
             ;;
@@ -185,7 +200,118 @@ _dbc_sync_completions() {
     esac
 
     if [[ "$cur" == -* ]]; then
-        COMPREPLY=($(compgen -W "-h --level -l --path -p --no-verify --json --json-stream-progress" -- "$cur"))
+        COMPREPLY=($(compgen -W "-h --level -l --path -p --no-verify --locked --frozen --json --json-stream-progress" -- "$cur"))
+        return 0
+    fi
+
+    COMPREPLY=()
+}
+
+_dbc_lock_completions() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    case "$prev" in
+        --path|-p)
+            # Complete .toml files
+            COMPREPLY=($(compgen -f -X '!*.toml' -- "$cur"))
+            if [[ -d "$cur" ]]; then
+                COMPREPLY+=($(compgen -d -- "$cur"))
+            fi
+            return 0
+            ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "-h --path -p --upgrade --json" -- "$cur"))
+        return 0
+    fi
+
+    COMPREPLY=()
+}
+
+_dbc_upgrade_completions() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    case "$prev" in
+        --level|-l)
+            COMPREPLY=($(compgen -W "user system" -- "$cur"))
+            return 0
+            ;;
+        --path|-p)
+            # Complete .toml files
+            COMPREPLY=($(compgen -f -X '!*.toml' -- "$cur"))
+            if [[ -d "$cur" ]]; then
+                COMPREPLY+=($(compgen -d -- "$cur"))
+            fi
+            return 0
+            ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "-h --level -l --path -p --no-verify --dry-run --json" -- "$cur"))
+        return 0
+    fi
+
+    # Driver name completion (no specific completion available)
+    COMPREPLY=()
+}
+
+_dbc_outdated_completions() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    case "$prev" in
+        --path|-p)
+            # Complete .toml files
+            COMPREPLY=($(compgen -f -X '!*.toml' -- "$cur"))
+            if [[ -d "$cur" ]]; then
+                COMPREPLY+=($(compgen -d -- "$cur"))
+            fi
+            return 0
+            ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "-h --path -p --json" -- "$cur"))
+        return 0
+    fi
+
+    COMPREPLY=()
+}
+
+_dbc_mirror_completions() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    case "$prev" in
+        --path|-p)
+            # Complete .toml files
+            COMPREPLY=($(compgen -f -X '!*.toml' -- "$cur"))
+            if [[ -d "$cur" ]]; then
+                COMPREPLY+=($(compgen -d -- "$cur"))
+            fi
+            return 0
+            ;;
+        --platform)
+            COMPREPLY=($(compgen -W "linux_amd64 linux_arm64 macos_amd64 macos_arm64 windows_amd64 windows_arm64" -- "$cur"))
+            return 0
+            ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "-h --help --path -p --platform --pre --no-verify --json" -- "$cur"))
+        return 0
+    fi
+
+    # The first argument is the mirror directory
+    if [[ $COMP_CWORD -eq 2 ]]; then
+        COMPREPLY=($(compgen -d -- "$cur"))
         return 0
     fi
 
@@ -397,6 +523,78 @@ _dbc_auth_license_install_completions() {
     fi
 }
 
+_dbc_registry_completions() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    # If we're at position 2 (right after "registry"), suggest subcommands
+    if [[ $COMP_CWORD -eq 2 ]]; then
+        if [[ "$cur" == -* ]]; then
+            COMPREPLY=($(compgen -W "-h --help" -- "$cur"))
+        else
+            COMPREPLY=($(compgen -W "build serve" -- "$cur"))
+        fi
+        return 0
+    fi
+
+    local registry_subcommand="${COMP_WORDS[2]}"
+
+    case "$registry_subcommand" in
+        build)
+            _dbc_registry_build_completions
+            ;;
+        serve)
+            _dbc_registry_serve_completions
+            ;;
+        *)
+            COMPREPLY=()
+            ;;
+    esac
+}
+
+_dbc_registry_build_completions() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    case "$prev" in
+        --output|-o)
+            COMPREPLY=($(compgen -f -- "$cur"))
+            return 0
+            ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "-h --help --output -o --sharded --json" -- "$cur"))
+        return 0
+    fi
+
+    # Complete registry directories
+    COMPREPLY=($(compgen -d -- "$cur"))
+}
+
+_dbc_registry_serve_completions() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    case "$prev" in
+        --addr|--token)
+            COMPREPLY=()
+            return 0
+            ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "-h --help --addr --token" -- "$cur"))
+        return 0
+    fi
+
+    # Complete registry directories
+    COMPREPLY=($(compgen -d -- "$cur"))
+}
+
 # Register the completion function
 complete -F _dbc dbc
 
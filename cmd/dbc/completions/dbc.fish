@@ -34,12 +34,17 @@ complete -f -c dbc -n '__fish_dbc_needs_command' -a 'list' -d 'List all currentl
 complete -f -c dbc -n '__fish_dbc_needs_command' -a 'init' -d 'Create new driver list'
 complete -f -c dbc -n '__fish_dbc_needs_command' -a 'add' -d 'Add one or more drivers to the driver list'
 complete -f -c dbc -n '__fish_dbc_needs_command' -a 'sync' -d 'Install all drivers in the driver list'
+complete -f -c dbc -n '__fish_dbc_needs_command' -a 'lock' -d 'Write the lockfile without installing drivers'
+complete -f -c dbc -n '__fish_dbc_needs_command' -a 'upgrade' -d 'Upgrade drivers in the driver list to the newest allowed versions'
+complete -f -c dbc -n '__fish_dbc_needs_command' -a 'outdated' -d 'Check installed and locked drivers for newer versions'
+complete -f -c dbc -n '__fish_dbc_needs_command' -a 'mirror' -d 'Copy drivers into a local registry'
 complete -f -c dbc -n '__fish_dbc_needs_command' -a 'search' -d 'Search for drivers'
 complete -f -c dbc -n '__fish_dbc_needs_command' -a 'remove' -d 'Remove a driver from the driver list'
 complete -f -c dbc -n '__fish_dbc_needs_command' -a 'info' -d 'Get detailed information about a specific driver'
 complete -f -c dbc -n '__fish_dbc_needs_command' -a 'docs' -d 'Open driver documentation in a web browser'
 complete -f -c dbc -n '__fish_dbc_needs_command' -a 'completion' -d 'Generate shell completions'
 complete -f -c dbc -n '__fish_dbc_needs_command' -a 'auth' -d 'Authenticate with a driver registry'
+complete -f -c dbc -n '__fish_dbc_needs_command' -a 'registry' -d 'Build and manage driver registries'
 
 # install subcommand
 complete -f -c dbc -n '__fish_dbc_using_subcommand install' -s h -d 'Show Help'
@@ -77,9 +82,43 @@ complete -f -c dbc -n '__fish_dbc_using_subcommand sync' -l help -d 'Help'
 complete -f -c dbc -n '__fish_dbc_using_subcommand sync' -l level -s l -d 'Installation level' -xa 'user system'
 complete -c dbc -n '__fish_dbc_using_subcommand sync' -l path -s p -r -F -a '*.toml' -d 'Driver list to sync'
 complete -f -c dbc -n '__fish_dbc_using_subcommand sync' -l no-verify -d 'Do not verify the driver after installation'
+complete -f -c dbc -n '__fish_dbc_using_subcommand sync' -l locked -d 'Fail if the lockfile is missing or out of date'
+complete -f -c dbc -n '__fish_dbc_using_subcommand sync' -l frozen -d 'Install exactly the versions in the lockfile'
 complete -f -c dbc -n '__fish_dbc_using_subcommand sync' -l json -d 'Print output as JSON instead of plaintext'
 complete -f -c dbc -n '__fish_dbc_using_subcommand sync' -l json-stream-progress -d 'Stream progress events as JSON lines (implies --json)'
 
+# lock subcommand
+complete -f -c dbc -n '__fish_dbc_using_subcommand lock' -s h -d 'Help'
+complete -f -c dbc -n '__fish_dbc_using_subcommand lock' -l help -d 'Help'
+complete -c dbc -n '__fish_dbc_using_subcommand lock' -l path -s p -r -F -a '*.toml' -d 'Driver list to lock'
+complete -f -c dbc -n '__fish_dbc_using_subcommand lock' -l upgrade -d 'Ignore the versions in the lockfile'
+complete -f -c dbc -n '__fish_dbc_using_subcommand lock' -l json -d 'Print output as JSON instead of plaintext'
+
+# upgrade subcommand
+complete -f -c dbc -n '__fish_dbc_using_subcommand upgrade' -s h -d 'Help'
+complete -f -c dbc -n '__fish_dbc_using_subcommand upgrade' -l help -d 'Help'
+complete -f -c dbc -n '__fish_dbc_using_subcommand upgrade' -l level -s l -d 'Installation level' -xa 'user system'
+complete -c dbc -n '__fish_dbc_using_subcommand upgrade' -l path -s p -r -F -a '*.toml' -d 'Driver list to upgrade'
+complete -f -c dbc -n '__fish_dbc_using_subcommand upgrade' -l no-verify -d 'Do not verify the driver after installation'
+complete -f -c dbc -n '__fish_dbc_using_subcommand upgrade' -l dry-run -d 'Show the upgrades without installing them'
+complete -f -c dbc -n '__fish_dbc_using_subcommand upgrade' -l json -d 'Print output as JSON instead of plaintext'
+
+# outdated subcommand
+complete -f -c dbc -n '__fish_dbc_using_subcommand outdated' -s h -d 'Help'
+complete -f -c dbc -n '__fish_dbc_using_subcommand outdated' -l help -d 'Help'
+complete -c dbc -n '__fish_dbc_using_subcommand outdated' -l path -s p -r -F -a '*.toml' -d 'Driver list whose lockfile to check'
+complete -f -c dbc -n '__fish_dbc_using_subcommand outdated' -l json -d 'Print output as JSON instead of plaintext'
+
+# mirror subcommand
+complete -f -c dbc -n '__fish_dbc_using_subcommand mirror' -s h -d 'Help'
+complete -f -c dbc -n '__fish_dbc_using_subcommand mirror' -l help -d 'Help'
+complete -c dbc -n '__fish_dbc_using_subcommand mirror' -l path -s p -r -F -a '*.toml' -d 'Driver list to mirror'
+complete -f -c dbc -n '__fish_dbc_using_subcommand mirror' -l platform -d 'Only mirror packages for this platform' -xa 'linux_amd64 linux_arm64 macos_amd64 macos_arm64 windows_amd64 windows_arm64'
+complete -f -c dbc -n '__fish_dbc_using_subcommand mirror' -l pre -d 'Include pre-release versions'
+complete -f -c dbc -n '__fish_dbc_using_subcommand mirror' -l no-verify -d 'Allow mirroring drivers without a valid signature'
+complete -f -c dbc -n '__fish_dbc_using_subcommand mirror' -l json -d 'Print output as JSON instead of plaintext'
+complete -c dbc -n '__fish_dbc_using_subcommand mirror' -x -a '(__fish_complete_directories)' -d 'Mirror directory'
+
 # search subcommand
 complete -f -c dbc -n '__fish_dbc_using_subcommand search' -s h -d 'Help'
 complete -f -c dbc -n '__fish_dbc_using_subcommand search' -l help -d 'Help'
@@ -180,3 +219,46 @@ complete -f -c dbc -n '__fish_dbc_auth_license_using_subcommand install' -s h -d
 complete -f -c dbc -n '__fish_dbc_auth_license_using_subcommand install' -l help -d 'Help'
 complete -f -c dbc -n '__fish_dbc_auth_license_using_subcommand install' -l force -d 'Overwrite existing license and skip filename check'
 complete -c dbc -n '__fish_dbc_auth_license_using_subcommand install' -F -a '*.lic' -d 'License file to install'
+
+# Helper function to check if we're using registry subcommand and need a nested subcommand
+function __fish_dbc_registry_needs_subcommand
+    set -l cmd (commandline -opc)
+    if test (count $cmd) -eq 2
+        if test $cmd[2] = "registry"
+            return 0
+        end
+    end
+    return 1
+end
+
+# Helper function to check if we're using a specific registry subcommand
+function __fish_dbc_registry_using_subcommand
+    set -l cmd (commandline -opc)
+    if test (count $cmd) -gt 2
+        if test $cmd[2] = "registry" -a $argv[1] = $cmd[3]
+            return 0
+        end
+    end
+    return 1
+end
+
+# registry subcommand
+complete -f -c dbc -n '__fish_dbc_using_subcommand registry' -s h -d 'Help'
+complete -f -c dbc -n '__fish_dbc_using_subcommand registry' -l help -d 'Help'
+complete -f -c dbc -n '__fish_dbc_registry_needs_subcommand' -a 'build' -d 'Generate or update a registry index.yaml from driver tarballs'
+complete -f -c dbc -n '__fish_dbc_registry_needs_subcommand' -a 'serve' -d 'Serve a registry directory over HTTP'
+
+# registry build subcommand
+complete -f -c dbc -n '__fish_dbc_registry_using_subcommand build' -s h -d 'Help'
+complete -f -c dbc -n '__fish_dbc_registry_using_subcommand build' -l help -d 'Help'
+complete -f -c dbc -n '__fish_dbc_registry_using_subcommand build' -l json -d 'Print output as JSON instead of plaintext'
+complete -c dbc -n '__fish_dbc_registry_using_subcommand build' -l output -s o -r -F -d 'Path of the index to write'
+complete -f -c dbc -n '__fish_dbc_registry_using_subcommand build' -l sharded -d 'Write a sharded index with a file per driver'
+complete -c dbc -n '__fish_dbc_registry_using_subcommand build' -x -a '(__fish_complete_directories)' -d 'Registry directory'
+
+# registry serve subcommand
+complete -f -c dbc -n '__fish_dbc_registry_using_subcommand serve' -s h -d 'Help'
+complete -f -c dbc -n '__fish_dbc_registry_using_subcommand serve' -l help -d 'Help'
+complete -f -c dbc -n '__fish_dbc_registry_using_subcommand serve' -l addr -x -d 'Address to listen on'
+complete -f -c dbc -n '__fish_dbc_registry_using_subcommand serve' -l token -x -d 'Require this bearer token on every request'
+complete -c dbc -n '__fish_dbc_registry_using_subcommand serve' -x -a '(__fish_complete_directories)' -d 'Registry directory'
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -33,8 +35,34 @@ type DriversList struct {
 	// ReplaceDefaults is a tri-state: nil means "inherit from global config",
 	// &true replaces both global and built-in default registries, &false forces
 	// defaults back on even when the global config set replace_defaults = true.
-	ReplaceDefaults *bool                 `toml:"replace_defaults,omitempty"`
-	Drivers         map[string]driverSpec `toml:"drivers" comment:"dbc driver list"`
+	ReplaceDefaults *bool `toml:"replace_defaults,omitempty"`
+	// Platforms lists the platforms, besides the one sync runs on, whose
+	// packages are recorded in the lockfile.
+	Platforms []string              `toml:"platforms,omitempty"`
+	Drivers   map[string]driverSpec `toml:"drivers" comment:"dbc driver list"`
+
+	// dir is the directory containing the dbc.toml this list was read
+	// from. Relative registry paths are resolved against it.
+	dir string
+}
+
+// registryEntries returns the list's registries with relative local paths
+// resolved against the dbc.toml's directory.
+func (l DriversList) registryEntries() []dbc.RegistryEntry {
+	return dbc.ResolveRegistryPaths(l.Registries, l.dir)
+}
+
+// targetPlatforms returns the platforms the lockfile records packages for:
+// exactly those the list targets or, if it doesn't name any, the one dbc is
+// running on. Which machine resolves the list doesn't change the lockfile
+// when it names its platforms.
+func (l DriversList) targetPlatforms() []string {
+	if len(l.Platforms) == 0 {
+		return []string{config.PlatformTuple()}
+	}
+	platforms := slices.Clone(l.Platforms)
+	slices.Sort(platforms)
+	return slices.Compact(platforms)
 }
 
 // registriesChanged reports whether two DriversList values would produce
@@ -74,7 +102,7 @@ func registriesChanged(a, b DriversList) bool {
 // and built-in defaults. It builds a throwaway client via newDBCClient
 // so merge semantics stay in sync with what NewClient actually uses.
 func effectiveRegistryURLs(list DriversList) ([]string, error) {
-	c, err := newDBCClient(list.Registries, list.ReplaceDefaults)
+	c, err := newDBCClient(list.registryEntries(), list.ReplaceDefaults)
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +149,7 @@ func applyProjectRegistries(list DriversList) error {
 	if len(list.Registries) == 0 && list.ReplaceDefaults == nil {
 		return nil
 	}
-	c, err := newDBCClient(list.Registries, list.ReplaceDefaults)
+	c, err := newDBCClient(list.registryEntries(), list.ReplaceDefaults)
 	if err != nil {
 		return fmt.Errorf("error configuring project registries: %w", err)
 	}
@@ -171,10 +199,14 @@ func applyProjectRegistriesFromCWD() error {
 	if err := toml.NewDecoder(f).Decode(&list); err != nil {
 		return fmt.Errorf("error decoding driver list at %s: %w", p, err)
 	}
+	list.dir = filepath.Dir(p)
 	return applyProjectRegistries(list)
 }
 
 type driverSpec struct {
+	// Registry pins the driver to the registry with this name or URL.
+	// When empty, the first registry publishing the driver is used.
+	Registry   string              `toml:"registry,omitempty"`
 	Prerelease string              `toml:"prerelease,omitempty"`
 	Version    *semver.Constraints `toml:"version"`
 }
@@ -189,13 +221,14 @@ func GetDriverList(fname string) ([]dbc.PkgInfo, error) {
 	if err = toml.NewDecoder(f).Decode(&m); err != nil {
 		return nil, fmt.Errorf("error decoding driver list %s: %w", fname, err)
 	}
+	m.dir = filepath.Dir(fname)
 
 	// Build a per-call client scoped to this list's registry overrides so
 	// repeated calls in the same process don't leak configuration from one
 	// dbc.toml to another. Unlike add/sync (which own the process for one
 	// command), GetDriverList is a library helper that may be called
 	// multiple times.
-	client, err := newDBCClient(m.Registries, m.ReplaceDefaults)
+	client, err := newDBCClient(m.registryEntries(), m.ReplaceDefaults)
 	if err != nil {
 		return nil, fmt.Errorf("error configuring project registries: %w", err)
 	}
@@ -204,17 +237,14 @@ func GetDriverList(fname string) ([]dbc.PkgInfo, error) {
 		return nil, err
 	}
 
-	// create mapping to avoid multiple loops through
-	dmap := make(map[string]dbc.Driver)
-	for _, driver := range drivers {
-		dmap[driver.Path] = driver
-	}
-
 	var pkgs []dbc.PkgInfo
 	for name, spec := range m.Drivers {
-		drv, ok := dmap[name]
-		if !ok {
-			return nil, fmt.Errorf("driver `%s` not found", name)
+		drv, err := findRegistryDriver(spec.Registry, name, drivers)
+		if err != nil {
+			return nil, err
+		}
+		if drv, err = client.LoadDriver(context.Background(), drv); err != nil {
+			return nil, err
 		}
 
 		pkg, err := drv.GetWithConstraint(spec.Version, config.PlatformTuple())
@@ -20,9 +20,15 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
 	"github.com/columnar-tech/dbc/internal/fslock"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
 	"github.com/pelletier/go-toml/v2"
 )
 
@@ -57,6 +63,74 @@ func wrapWithRegistryContext(err, registryErr error) error {
 	return err
 }
 
+// deprecationWarning returns a warning for d if its registry deprecated it.
+func deprecationWarning(d dbc.Driver) (jsonschema.DriverWarning, bool) {
+	if !d.Deprecated {
+		return jsonschema.DriverWarning{}, false
+	}
+	return jsonschema.DriverWarning{
+		Driver:     d.Path,
+		Kind:       "deprecated",
+		Reason:     d.DeprecatedReason,
+		ReplacedBy: d.ReplacedBy,
+	}, true
+}
+
+// yankedWarning returns a warning if version v of d was yanked.
+func yankedWarning(d dbc.Driver, v *semver.Version) (jsonschema.DriverWarning, bool) {
+	reason, yanked := d.Yanked(v)
+	if !yanked {
+		return jsonschema.DriverWarning{}, false
+	}
+	return jsonschema.DriverWarning{
+		Driver:  d.Path,
+		Kind:    "yanked",
+		Version: v.String(),
+		Reason:  reason,
+	}, true
+}
+
+// installedWarnings returns the warnings for d: whether it is deprecated, and
+// whether a version of it installed at any config level was yanked.
+func installedWarnings(d dbc.Driver, cfg map[config.ConfigLevel]config.Config) []jsonschema.DriverWarning {
+	var warnings []jsonschema.DriverWarning
+	if w, ok := deprecationWarning(d); ok {
+		warnings = append(warnings, w)
+	}
+
+	var versions []*semver.Version
+	for _, level := range []config.ConfigLevel{config.ConfigEnv, config.ConfigUser, config.ConfigSystem} {
+		if drv, ok := cfg[level].Drivers[d.Path]; ok && drv.Version != nil &&
+			!slices.ContainsFunc(versions, drv.Version.Equal) {
+			versions = append(versions, drv.Version)
+		}
+	}
+	for _, v := range versions {
+		if w, ok := yankedWarning(d, v); ok {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// formatWarning renders w as a line of plain output.
+func formatWarning(w jsonschema.DriverWarning) string {
+	var b strings.Builder
+	b.WriteString(warningStyle.Render("Warning: "))
+	if w.Kind == "yanked" {
+		fmt.Fprintf(&b, "%s %s has been yanked", w.Driver, w.Version)
+	} else {
+		fmt.Fprintf(&b, "%s is deprecated", w.Driver)
+	}
+	if w.Reason != "" {
+		b.WriteString(": " + w.Reason)
+	}
+	if w.ReplacedBy != "" {
+		fmt.Fprintf(&b, "; use %s instead", w.ReplacedBy)
+	}
+	return b.String()
+}
+
 func defaultBaseModel() baseModel {
 	return baseModel{
 		getDriverRegistry: getDriverRegistry,
@@ -83,5 +157,6 @@ func openAndDecodeDriverList(path string) (DriversList, error) {
 	if err := toml.NewDecoder(f).Decode(&list); err != nil {
 		return DriversList{}, err
 	}
+	list.dir = filepath.Dir(p)
 	return list, nil
 }
@@ -21,6 +21,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
 	"github.com/columnar-tech/dbc/internal/jsonschema"
 )
 
@@ -47,6 +48,14 @@ type infoModel struct {
 	driver     string
 	jsonOutput bool
 	drv        dbc.Driver
+	// conflict lists every registry publishing the driver, if there are
+	// several
+	conflict *dbc.DriverConflict
+}
+
+type driverInfoMsg struct {
+	drv      dbc.Driver
+	conflict *dbc.DriverConflict
 }
 
 func (m infoModel) Init() tea.Cmd {
@@ -64,12 +73,21 @@ func (m infoModel) Init() tea.Cmd {
 		if err != nil {
 			return wrapWithRegistryContext(err, registryErr)
 		}
+		if drv, err = loadDriver(drv); err != nil {
+			return err
+		}
 
-		return drv
+		msg := driverInfoMsg{drv: drv}
+		for _, c := range dbc.FindConflicts(drivers) {
+			if c.Path == drv.Path {
+				msg.conflict = &c
+			}
+		}
+		return msg
 	}
 }
 
-func formatDriverInfo(drv dbc.Driver) string {
+func formatDriverInfo(drv dbc.Driver, conflict *dbc.DriverConflict) string {
 	if len(drv.PkgInfo) == 0 {
 		return ""
 	}
@@ -89,11 +107,20 @@ func formatDriverInfo(drv dbc.Driver) string {
 	for _, pkg := range info.Packages {
 		b.WriteString("   - " + descStyle.Render(pkg.Platform) + "\n")
 	}
+	if conflict != nil {
+		b.WriteString("\n" + formatConflict(*conflict, true) + "\n")
+	}
+	if warnings := installedWarnings(drv, config.Get()); len(warnings) > 0 {
+		b.WriteString("\n")
+		for _, w := range warnings {
+			b.WriteString(formatWarning(w) + "\n")
+		}
+	}
 
 	return strings.TrimSuffix(b.String(), "\n")
 }
 
-func driverInfoJSON(drv dbc.Driver) string {
+func driverInfoJSON(drv dbc.Driver, conflict *dbc.DriverConflict) string {
 	info, ok := drv.MaxVersion()
 	if !ok {
 		return "{}"
@@ -109,6 +136,10 @@ func driverInfoJSON(drv dbc.Driver) string {
 	for _, pkg := range info.Packages {
 		driverInfo.Packages = append(driverInfo.Packages, pkg.Platform)
 	}
+	if conflict != nil {
+		driverInfo.Sources = driverSources(*conflict, true)
+	}
+	driverInfo.Warnings = installedWarnings(drv, config.Get())
 
 	payloadBytes, err := json.Marshal(driverInfo)
 	if err != nil {
@@ -131,6 +162,9 @@ func (m infoModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dbc.Driver:
 		m.drv = msg
 		return m, tea.Quit
+	case driverInfoMsg:
+		m.drv, m.conflict = msg.drv, msg.conflict
+		return m, tea.Quit
 	case error:
 		m.status = 1
 		m.err = msg
@@ -155,9 +189,9 @@ func (m infoModel) FinalOutput() string {
 		return ""
 	}
 	if m.jsonOutput {
-		return driverInfoJSON(m.drv)
+		return driverInfoJSON(m.drv, m.conflict)
 	}
-	return formatDriverInfo(m.drv)
+	return formatDriverInfo(m.drv, m.conflict)
 }
 
 func (m infoModel) View() tea.View {
@@ -135,3 +135,55 @@ func (suite *SubcommandTestSuite) TestInfo_JSON_RegistryFailure() {
 	out := suite.runCmdErr(m)
 	suite.assertJSONErrorEnvelope(out, "info_failed", "network unreachable")
 }
+
+func (suite *SubcommandTestSuite) TestInfoConflicts() {
+	multiRegistry := baseModel{getDriverRegistry: getMultiTestDriverRegistry, downloadPkg: downloadTestPkg}
+
+	m := InfoCmd{Driver: "acme/test-driver-1"}.GetModelCustom(multiRegistry)
+	out := suite.runCmd(m)
+	suite.Contains(out, "Version: 1.0.0\n")
+	suite.Contains(out, "\n\ntest-driver-1 is published by 2 registries; `dbc install test-driver-1` uses the first:\n"+
+		"  1. https://registry.columnar.tech: 1.0.0, 1.1.0\n"+
+		"  2. acme (https://acme.example.com/drivers): 1.0.0")
+
+	m = InfoCmd{Driver: "test-driver-1", Json: true}.GetModelCustom(multiRegistry)
+	out = suite.runCmd(m)
+	var env jsonschema.Envelope
+	suite.Require().NoError(json.Unmarshal([]byte(out), &env), "output must be valid JSON: %s", out)
+	var info jsonschema.DriverInfo
+	suite.Require().NoError(json.Unmarshal(env.Payload, &info))
+	suite.Equal("1.1.0", info.Version)
+	suite.Equal([]jsonschema.DriverSource{
+		{URL: "https://registry.columnar.tech", Versions: []string{"1.0.0", "1.1.0"}, Selected: true},
+		{Registry: "acme", URL: "https://acme.example.com/drivers", Versions: []string{"1.0.0"}},
+	}, info.Sources)
+
+	m = InfoCmd{Driver: "test-driver-2", Json: true}.GetModelCustom(multiRegistry)
+	suite.NotContains(suite.runCmd(m), "sources")
+}
+
+func (suite *SubcommandTestSuite) TestInfoYanked() {
+	m := InstallCmd{Driver: "test-driver-1", Level: suite.configLevel}.GetModelCustom(testBaseModel())
+	suite.runCmd(m)
+
+	yankedRegistry := baseModel{getDriverRegistry: getYankedTestDriverRegistry, downloadPkg: downloadTestPkg}
+	m = InfoCmd{Driver: "test-driver-1"}.GetModelCustom(yankedRegistry)
+	out := suite.runCmd(m)
+	suite.Contains(out, "Version: 1.0.0\n")
+	suite.Contains(out, "\n\nWarning: test-driver-1 is deprecated: unmaintained; use test-driver-2 instead\n"+
+		"Warning: test-driver-1 1.1.0 has been yanked: broken build")
+
+	m = InfoCmd{Driver: "test-driver-1", Json: true}.GetModelCustom(yankedRegistry)
+	out = suite.runCmd(m)
+	var env jsonschema.Envelope
+	suite.Require().NoError(json.Unmarshal([]byte(out), &env), "output must be valid JSON: %s", out)
+	var info jsonschema.DriverInfo
+	suite.Require().NoError(json.Unmarshal(env.Payload, &info))
+	suite.Equal([]jsonschema.DriverWarning{
+		{Driver: "test-driver-1", Kind: "deprecated", Reason: "unmaintained", ReplacedBy: "test-driver-2"},
+		{Driver: "test-driver-1", Kind: "yanked", Version: "1.1.0", Reason: "broken build"},
+	}, info.Warnings)
+
+	m = InfoCmd{Driver: "test-driver-2", Json: true}.GetModelCustom(yankedRegistry)
+	suite.NotContains(suite.runCmd(m), "warnings")
+}
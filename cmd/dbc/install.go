@@ -76,6 +76,7 @@ type InstallCmd struct {
 func (InstallCmd) Description() string {
 	return "Install a driver.\n\n" +
 		"`DRIVER` may include a version constraint, for example `dbc install mysql`, `dbc install \"mysql=0.1.0\"`, or `dbc install \"mysql>=1,<2\"`.\n" +
+		"Prefix it with a registry name or URL to install from that registry only, for example `dbc install acme/mysql`.\n" +
 		"See https://docs.columnar.tech/dbc/guides/installing/#version-constraints for more on version constraint syntax."
 }
 
@@ -111,7 +112,10 @@ func (c InstallCmd) GetModel() tea.Model {
 	return c.GetModelCustom(defaultBaseModel())
 }
 
-func verifySignature(m config.Manifest, noVerify bool) error {
+// verifySignature checks the signature of the driver library installed for
+// m against the keys trusted for reg, the registry it was downloaded from.
+// reg is nil for packages installed from a local file.
+func verifySignature(m config.Manifest, reg *dbc.Registry, noVerify bool) error {
 	if m.Files.Driver == "" || noVerify {
 		return nil
 	}
@@ -138,7 +142,7 @@ func verifySignature(m config.Manifest, noVerify bool) error {
 	}
 	defer sig.Close()
 
-	if err := dbc.SignedByColumnar(lib, sig); err != nil {
+	if err := reg.VerifySignature(lib, sig); err != nil {
 		return fmt.Errorf("signature verification failed: %w", err)
 	}
 
@@ -402,8 +406,9 @@ func (m progressiveInstallModel) searchForDriver(list []dbc.Driver) (tea.Model,
 		return m, errCmd("%w", err)
 	}
 
+	registry, driverName := splitDriverRef(driverName)
 	m.Driver = driverName
-	d, err := findDriver(m.Driver, list)
+	d, err := findRegistryDriver(registry, m.Driver, list)
 	if err != nil {
 		// If we have registry errors, enhance the error message
 		if m.registryErrors != nil {
@@ -413,6 +418,11 @@ func (m progressiveInstallModel) searchForDriver(list []dbc.Driver) (tea.Model,
 	}
 
 	return m, func() tea.Msg {
+		d, err := loadDriver(d)
+		if err != nil {
+			return err
+		}
+
 		if vers != nil {
 			vers.IncludePrerelease = m.Pre
 			pkg, err := d.GetWithConstraint(vers, config.PlatformTuple())
@@ -513,6 +523,11 @@ func (m progressiveInstallModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		progressCmd := m.p.SetPercent(msg.written, msg.total)
 		return m, progressCmd
+	case downloadRetryMsg:
+		m = m.addEvent("download.retry", func(e *jsonschema.InstallProgressEvent) {
+			e.Retry = msg.info()
+		})
+		return m, nil
 	case progress.FrameMsg:
 		var cmd tea.Cmd
 		m.p, cmd = m.p.Update(msg)
@@ -557,7 +572,7 @@ func (m progressiveInstallModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m = m.addEvent("extract.complete")
 		m = m.addEvent("verify.start")
 		return m, func() tea.Msg {
-			if err := verifySignature(msg, m.NoVerify); err != nil {
+			if err := verifySignature(msg, m.DriverPackage.Driver.Registry, m.NoVerify); err != nil {
 				path := filepath.Dir(msg.Driver.Shared.Get(config.PlatformTuple()))
 				_ = os.RemoveAll(path)
 				return err
@@ -19,11 +19,14 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
 	"github.com/columnar-tech/dbc"
 	"github.com/columnar-tech/dbc/config"
 	"github.com/columnar-tech/dbc/internal/jsonschema"
@@ -46,6 +49,20 @@ func (suite *SubcommandTestSuite) TestInstallDriverNotFound() {
 	suite.driverIsNotInstalled("test-driver-1")
 }
 
+func (suite *SubcommandTestSuite) TestInstallFromRegistry() {
+	multiRegistry := baseModel{getDriverRegistry: getMultiTestDriverRegistry, downloadPkg: downloadTestPkg}
+
+	m := InstallCmd{Driver: "acme/test-driver-1", Level: suite.configLevel}.
+		GetModelCustom(multiRegistry)
+	suite.validateOutput("\r[✓] searching\r\n[✓] downloading\r\n[✓] installing\r\n[✓] verifying signature\r\n",
+		"\nInstalled test-driver-1 1.0.0 to "+suite.Dir(), suite.runCmd(m))
+	suite.driverIsInstalled("test-driver-1", true)
+
+	m = InstallCmd{Driver: "acme/test-driver-2", Level: suite.configLevel}.
+		GetModelCustom(multiRegistry)
+	suite.Contains(suite.runCmdErr(m), "driver `test-driver-2` not found in registry `acme`")
+}
+
 func (suite *SubcommandTestSuite) TestInstallWithVersion() {
 	tests := []struct {
 		driver          string
@@ -199,6 +216,68 @@ func (suite *SubcommandTestSuite) TestInstallDriverNoSignature() {
 		"\nInstalled test-driver-no-sig 1.0.0 to "+suite.tempdir, suite.runCmd(m))
 }
 
+func (suite *SubcommandTestSuite) TestInstallRegistrySigningKey() {
+	pgp := crypto.PGP()
+	key, err := pgp.KeyGeneration().AddUserId("acme", "drivers@acme.example.com").New().GenerateKey()
+	suite.Require().NoError(err)
+	pub, err := key.ToPublic()
+	suite.Require().NoError(err)
+	armored, err := pub.Armor()
+	suite.Require().NoError(err)
+
+	lib := []byte("acme driver library")
+	signer, err := pgp.Sign().SigningKey(key).Detached().New()
+	suite.Require().NoError(err)
+	sig, err := signer.Sign(lib, crypto.Bytes)
+	suite.Require().NoError(err)
+
+	packagePath := filepath.Join(suite.T().TempDir(), "acme.tar.gz")
+	f, err := os.Create(packagePath)
+	suite.Require().NoError(err)
+	gzw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gzw)
+	for name, data := range map[string][]byte{
+		"MANIFEST": []byte(`name = "Test Driver 1"
+version = "1.1.0"
+
+[Files]
+driver = "acme.so"
+`),
+		"acme.so":     lib,
+		"acme.so.sig": sig,
+	} {
+		suite.Require().NoError(tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}))
+		_, err = tw.Write(data)
+		suite.Require().NoError(err)
+	}
+	suite.Require().NoError(tw.Close())
+	suite.Require().NoError(gzw.Close())
+	suite.Require().NoError(f.Close())
+
+	registryWithKeys := func(keys ...string) baseModel {
+		reg := testRegistry
+		reg.SigningKeys = keys
+		return baseModel{
+			getDriverRegistry: func() ([]dbc.Driver, error) {
+				drivers, err := getTestDriverRegistry()
+				for i := range drivers {
+					drivers[i].Registry = &reg
+				}
+				return drivers, err
+			},
+			downloadPkg: func(dbc.PkgInfo) (*os.File, error) { return os.Open(packagePath) },
+		}
+	}
+
+	out := suite.runCmdErr(InstallCmd{Driver: "test-driver-1"}.GetModelCustom(registryWithKeys()))
+	suite.Contains(out, "signature verification failed")
+	suite.NoDirExists(filepath.Join(suite.tempdir, "test-driver-1"))
+
+	m := InstallCmd{Driver: "test-driver-1"}.GetModelCustom(registryWithKeys(armored))
+	suite.validateOutput("\r[✓] searching\r\n[✓] downloading\r\n[✓] installing\r\n[✓] verifying signature\r\n",
+		"\nInstalled test-driver-1 1.1.0 to "+suite.tempdir, suite.runCmd(m))
+}
+
 func (suite *SubcommandTestSuite) TestInstallGitignoreDefaultBehavior() {
 	driver_path := filepath.Join(suite.tempdir, "driver_path")
 	ignorePath := filepath.Join(driver_path, ".gitignore")
@@ -581,6 +660,49 @@ func (suite *SubcommandTestSuite) TestInstall_JSONProgressStream() {
 	suite.True(hasDownloadStart, "expected download.start event")
 }
 
+// downloadTestPkgWithRetry reports a retry, as a download interrupted once
+// would, before returning the test package.
+func downloadTestPkgWithRetry(pkg dbc.PkgInfo) (*os.File, error) {
+	prog.Send(downloadRetryMsg{
+		Attempt:    1,
+		MaxRetries: 3,
+		Wait:       500 * time.Millisecond,
+		Offset:     1024,
+		Err:        io.ErrUnexpectedEOF,
+	})
+	return downloadTestPkg(pkg)
+}
+
+func (suite *SubcommandTestSuite) TestInstall_JSONProgressStreamRetry() {
+	m := InstallCmd{Driver: "test-driver-1", Level: suite.configLevel, JsonStreamProgress: true}.
+		GetModelCustom(baseModel{getDriverRegistry: getTestDriverRegistry, downloadPkg: downloadTestPkgWithRetry})
+	out := suite.runCmd(m)
+
+	var retries []jsonschema.InstallProgressEvent
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		var env jsonschema.Envelope
+		suite.Require().NoError(json.Unmarshal([]byte(line), &env), "line must be valid JSON: %s", line)
+		var evt jsonschema.InstallProgressEvent
+		if env.Kind == "install.progress" && json.Unmarshal(env.Payload, &evt) == nil && evt.Event == "download.retry" {
+			retries = append(retries, evt)
+		}
+	}
+	suite.Equal([]jsonschema.InstallProgressEvent{{
+		Event:  "download.retry",
+		Driver: "test-driver-1",
+		Retry: jsonschema.RetryInfo{
+			Attempt:     1,
+			MaxAttempts: 3,
+			WaitMs:      500,
+			Offset:      1024,
+			Error:       "unexpected EOF",
+		},
+	}}, retries)
+}
+
 // TestInstallJSON_AlreadyInstalledChecksumFailure is a regression test for the
 // fix that gates FinalOutput() on m.status. When the driver binary is missing
 // the checksum computation fails, the model exits with status 1, and
@@ -0,0 +1,167 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+)
+
+type LockCmd struct {
+	Path    string `arg:"-p" placeholder:"FILE" default:"./dbc.toml" help:"Driver list to lock"`
+	Upgrade bool   `arg:"--upgrade" help:"Ignore the versions in the lockfile and lock the newest versions the driver list allows"`
+	Json    bool   `arg:"--json" help:"Print output as JSON instead of plaintext"`
+}
+
+func (LockCmd) Description() string {
+	return "Resolve the drivers in the driver list and write the lockfile without installing them.\n\n" +
+		"Versions already in the lockfile are kept as long as the driver list allows them; with --upgrade, " +
+		"the newest versions the driver list allows are locked instead. No drivers are installed or removed at any config level. " +
+		"Packages whose registry lists no sha256 digest are downloaded to record one."
+}
+
+func (c LockCmd) GetModelCustom(baseModel baseModel) tea.Model {
+	return lockModel{
+		syncModel: syncModel{
+			baseModel:  baseModel,
+			Path:       c.Path,
+			jsonOutput: c.Json,
+			upgradeAll: c.Upgrade,
+		},
+	}
+}
+
+func (c LockCmd) GetModel() tea.Model {
+	return c.GetModelCustom(defaultBaseModel())
+}
+
+// lockModel resolves a driver list the way sync does, but writes the
+// lockfile instead of installing the drivers.
+type lockModel struct {
+	syncModel
+}
+
+func (m lockModel) WithJSONWriter(w io.Writer) tea.Model {
+	m.jsonOut = w
+	return m
+}
+
+func (m lockModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case []installItem:
+		m.installItems = msg
+		m.warnings = syncWarnings(msg)
+		return m, func() tea.Msg {
+			entries, err := m.lockEntries(msg)
+			if err != nil {
+				return err
+			}
+			return lockEntriesMsg(entries)
+		}
+	case lockEntriesMsg:
+		m.locked.Drivers = msg
+		if err := m.writeLockFile(); err != nil {
+			return m, errCmd("%w", err)
+		}
+		m.done = true
+		return m, tea.Quit
+	case error:
+		if m.jsonOutput {
+			m.status, m.err = 1, msg
+			return m, tea.Sequence(tea.Println(marshalEnvelope("error", jsonschema.ErrorResponse{
+				Code:    "lock_failed",
+				Message: msg.Error(),
+			})), tea.Quit)
+		}
+	}
+
+	sm, cmd := m.syncModel.Update(msg)
+	m.syncModel = sm.(syncModel)
+	return m, cmd
+}
+
+// lockEntriesMsg holds the lockfile entries of the resolved drivers.
+type lockEntriesMsg []lockInfo
+
+// lockEntries returns the lockfile entries for items. The packages whose
+// registry lists no sha256 digest are downloaded and hashed, so that
+// `dbc sync --locked` and `--frozen` can check their downloads against the
+// lockfile on every platform it locks.
+func (m lockModel) lockEntries(items []installItem) ([]lockInfo, error) {
+	entries := make([]lockInfo, 0, len(items))
+	for _, item := range items {
+		entry := item.lockEntry(item.Package.Version, item.Checksum, "")
+		for i, p := range entry.Packages {
+			if p.SHA256 != "" {
+				continue
+			}
+			pkg, err := item.Driver.GetPackage(item.Package.Version, p.Platform, true)
+			if err != nil {
+				return nil, fmt.Errorf("cannot lock %s %s for platform %s: %w", item.Driver.Path, item.Package.Version, p.Platform, err)
+			}
+			f, err := m.downloadPkg(pkg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download %s %s for platform %s: %w", item.Driver.Path, item.Package.Version, p.Platform, err)
+			}
+			digest, err := checksum(f.Name())
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			entry.Packages[i].SHA256 = digest
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (m lockModel) View() tea.View {
+	if m.status != 0 || m.done {
+		return tea.NewView("")
+	}
+	return tea.NewView("Resolving drivers...")
+}
+
+func (m lockModel) FinalOutput() string {
+	if m.status != 0 {
+		return ""
+	}
+
+	locked := make([]jsonschema.SyncedDriver, 0, len(m.locked.Drivers))
+	for _, d := range m.locked.Drivers {
+		locked = append(locked, jsonschema.SyncedDriver{Name: d.Name, Version: d.Version.String()})
+	}
+	if m.jsonOutput {
+		return marshalEnvelope("lock.status", jsonschema.LockStatus{
+			Lockfile: m.LockFilePath,
+			Locked:   locked,
+			Warnings: m.warnings,
+		})
+	}
+
+	var b strings.Builder
+	for _, w := range m.warnings {
+		b.WriteString(formatWarning(w) + "\n")
+	}
+	for _, d := range locked {
+		fmt.Fprintf(&b, "%s %s-%s\n", checkMark, d.Name, d.Version)
+	}
+	b.WriteString(msgStyle.Render(fmt.Sprintf("Locked %d driver(s) in %s", len(locked), m.LockFilePath)))
+	return b.String()
+}
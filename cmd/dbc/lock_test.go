@@ -0,0 +1,103 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+)
+
+func (suite *SubcommandTestSuite) TestLock() {
+	listPath := filepath.Join(suite.tempdir, "dbc.toml")
+	lockPath := filepath.Join(suite.tempdir, "dbc.lock")
+
+	suite.runCmd(InitCmd{Path: listPath}.GetModel())
+	suite.runCmd(AddCmd{Path: listPath, Driver: []string{"test-driver-1=1.0.0"}}.GetModel())
+
+	out := suite.runCmd(LockCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+	suite.Contains(out, "test-driver-1-1.0.0")
+	suite.Contains(out, "Locked 1 driver(s) in "+lockPath)
+	suite.Equal(map[string]string{"test-driver-1": "1.0.0"}, suite.lockedVersions(lockPath))
+	suite.NoFileExists(filepath.Join(suite.tempdir, "test-driver-1.toml"))
+
+	lf, err := loadLockFile(lockPath)
+	suite.Require().NoError(err)
+	pkg, ok := lf.Drivers[0].pkg(config.PlatformTuple())
+	suite.Require().True(ok)
+	suite.NotEmpty(pkg.URL)
+	suite.Empty(pkg.Checksum)
+	// The test registry lists no digests, so lock downloads the package to
+	// record one.
+	digest, err := checksum(filepath.Join("testdata", "test-driver-1.tar.gz"))
+	suite.Require().NoError(err)
+	suite.Equal(digest, pkg.SHA256)
+
+	// Dropping the constraint keeps the locked version unless --upgrade is given.
+	suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\n"), 0o644))
+	suite.runCmd(LockCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+	suite.Equal(map[string]string{"test-driver-1": "1.0.0"}, suite.lockedVersions(lockPath))
+
+	out = suite.runCmd(LockCmd{Path: listPath, Upgrade: true, Json: true}.GetModelCustom(testBaseModel()))
+	var env jsonschema.Envelope
+	suite.Require().NoError(json.Unmarshal([]byte(strings.TrimSpace(out)), &env))
+	suite.Equal("lock.status", env.Kind)
+	var status jsonschema.LockStatus
+	suite.Require().NoError(json.Unmarshal(env.Payload, &status))
+	suite.Equal(lockPath, status.Lockfile)
+	suite.Equal([]jsonschema.SyncedDriver{{Name: "test-driver-1", Version: "1.1.0"}}, status.Locked)
+	suite.Equal(map[string]string{"test-driver-1": "1.1.0"}, suite.lockedVersions(lockPath))
+	suite.NoFileExists(filepath.Join(suite.tempdir, "test-driver-1.toml"))
+
+	suite.Run("every locked platform gets a digest", func() {
+		suite.Require().NoError(os.WriteFile(listPath, []byte("platforms = ['windows_amd64', 'macos_arm64']\n\n[drivers.test-driver-1]\n"), 0o644))
+		suite.runCmd(LockCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+
+		digest, err := checksum(filepath.Join("testdata", "test-driver-1.1.tar.gz"))
+		suite.Require().NoError(err)
+		lf, err := loadLockFile(lockPath)
+		suite.Require().NoError(err)
+		suite.Require().Len(lf.Drivers[0].Packages, 2)
+		for _, p := range lf.Drivers[0].Packages {
+			suite.Equal(digest, p.SHA256, p.Platform)
+		}
+	})
+
+	suite.Run("download failure", func() {
+		failing := baseModel{
+			getDriverRegistry: getTestDriverRegistry,
+			downloadPkg: func(dbc.PkgInfo) (*os.File, error) {
+				return nil, errors.New("connection refused")
+			},
+		}
+		suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-no-sig]\n"), 0o644))
+		out := suite.runCmdErr(LockCmd{Path: listPath}.GetModelCustom(failing))
+		suite.Contains(out, "failed to download test-driver-no-sig 1.0.0 for platform "+config.PlatformTuple())
+		suite.Equal(map[string]string{"test-driver-1": "1.1.0"}, suite.lockedVersions(lockPath))
+	})
+
+	suite.Run("unknown driver", func() {
+		suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.not-a-driver]\n"), 0o644))
+		out := suite.runCmdErr(LockCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		suite.Contains(out, "not-a-driver")
+		suite.Equal(map[string]string{"test-driver-1": "1.1.0"}, suite.lockedVersions(lockPath))
+	})
+}
@@ -19,18 +19,45 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/pelletier/go-toml/v2"
 )
 
-const lockFileVersion = 1
+const lockFileVersion = 2
+
+// lockPackage is the package a locked driver version resolves to on one of
+// the platforms the driver list targets.
+type lockPackage struct {
+	Platform string `toml:"platform"`
+	URL      string `toml:"url,omitempty"`
+	// SHA256 is the hex-encoded sha256 digest of the package, from the
+	// registry index or from downloading it.
+	SHA256 string `toml:"sha256,omitempty"`
+	// Checksum is the sha256 of the installed driver library, recorded by
+	// the last sync on this platform.
+	Checksum string `toml:"checksum,omitempty"`
+}
 
 type lockInfo struct {
-	Name     string          `toml:"name"`
-	Version  *semver.Version `toml:"version"`
-	Platform string          `toml:"platform,omitempty"`
-	Checksum string          `toml:"checksum,omitempty"`
+	Name    string          `toml:"name"`
+	Version *semver.Version `toml:"version"`
+	// Platform and Checksum are only read from version 1 lockfiles, which
+	// recorded the one platform they were synced on.
+	Platform string        `toml:"platform,omitempty"`
+	Registry string        `toml:"registry,omitempty"`
+	Checksum string        `toml:"checksum,omitempty"`
+	Packages []lockPackage `toml:"packages,omitempty"`
+}
+
+// pkg returns the package locked for platform.
+func (l lockInfo) pkg(platform string) (lockPackage, bool) {
+	idx := slices.IndexFunc(l.Packages, func(p lockPackage) bool { return p.Platform == platform })
+	if idx == -1 {
+		return lockPackage{}, false
+	}
+	return l.Packages[idx], true
 }
 
 type LockFile struct {
@@ -51,9 +78,18 @@ func loadLockFile(p string) (LockFile, error) {
 	if err := toml.NewDecoder(f).Decode(&lf); err != nil {
 		return lf, fmt.Errorf("error decoding lock file %s: %w", p, err)
 	}
+	if lf.Version > lockFileVersion {
+		return lf, fmt.Errorf("lock file %s has version %d, but this version of dbc only supports up to version %d; upgrade dbc to use it",
+			p, lf.Version, lockFileVersion)
+	}
 
 	lf.lockinfo = make(map[string]lockInfo)
-	for _, d := range lf.Drivers {
+	for i, d := range lf.Drivers {
+		if len(d.Packages) == 0 && d.Platform != "" {
+			d.Packages = []lockPackage{{Platform: d.Platform, Checksum: d.Checksum}}
+		}
+		d.Platform, d.Checksum = "", ""
+		lf.Drivers[i] = d
 		lf.lockinfo[d.Name] = d
 	}
 
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"slices"
 	"strings"
@@ -32,6 +33,7 @@ import (
 	"github.com/columnar-tech/dbc/cmd/dbc/completions"
 	"github.com/columnar-tech/dbc/config"
 	"github.com/columnar-tech/dbc/internal"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
 	"github.com/mattn/go-isatty"
 )
 
@@ -85,6 +87,10 @@ var (
 	// built in this process, including clients rebuilt after reading a project's
 	// dbc.toml registry section.
 	globalRegistryConfig *dbc.GlobalConfig
+
+	// offlineMode mirrors the global --offline flag (or DBC_OFFLINE) so every
+	// client built in this process resolves only from local caches.
+	offlineMode bool
 )
 
 func newDefaultClient() (*dbc.Client, error) {
@@ -92,26 +98,46 @@ func newDefaultClient() (*dbc.Client, error) {
 }
 
 // authHTTPClient returns an *http.Client suitable for auth flows (device-code
-// login, license fetch, etc.) that do not need driver registries resolved.
-// It bypasses dbc.NewClient so registry misconfiguration — a valid state for
-// a user who is about to run `dbc auth login` to fix it — cannot break login.
-func authHTTPClient() *http.Client {
-	c, err := dbc.NewClient(dbc.WithBaseURL("https://placeholder.invalid"))
+// login, license fetch, etc.) with registry that do not need driver
+// registries resolved. It bypasses registry resolution so registry
+// misconfiguration — a valid state for a user who is about to run
+// `dbc auth login` to fix it — cannot break login, but it still applies the
+// network settings and client certificates config.toml gives for registry.
+func authHTTPClient(registry *url.URL) *http.Client {
+	opts := []dbc.Option{dbc.WithBaseURL("https://placeholder.invalid")}
+	if globalRegistryConfig != nil {
+		opts = append(opts,
+			dbc.WithGlobalConfig(globalRegistryConfig),
+			dbc.WithNetwork(globalRegistryConfig.NetworkFor(registry)))
+	}
+	c, err := dbc.NewClient(opts...)
 	if err != nil {
 		// Impossible in practice: WithBaseURL short-circuits all registry
-		// validation. Fall back to http.DefaultClient so auth still works.
+		// validation, and LoadGlobalConfig already rejected invalid network
+		// settings. Fall back to http.DefaultClient so auth still works.
 		return http.DefaultClient
 	}
 	return c.HTTPClient()
 }
 
+// authContext returns a context whose auth requests to registry, such as
+// token refreshes and license fetches, go through authHTTPClient.
+func authContext(registry *url.URL) context.Context {
+	return auth.WithHTTPClient(context.Background(), authHTTPClient(registry))
+}
+
 // newDBCClient builds a client with the given project registry overrides.
 // Callers pass nil/nil for process-wide operations (search, info, install);
 // project commands (add, sync, remove) pass the values parsed from dbc.toml.
 func newDBCClient(projectRegs []dbc.RegistryEntry, projectReplaceDefaults *bool) (*dbc.Client, error) {
-	var opts []dbc.Option
+	opts := []dbc.Option{dbc.WithOffline(offlineMode)}
 	if val := os.Getenv("DBC_BASE_URL"); val != "" {
 		opts = append(opts, dbc.WithBaseURL(val))
+		if globalRegistryConfig != nil {
+			// The base URL replaces the configured registries, but their
+			// network settings and client certificates still apply.
+			opts = append(opts, dbc.WithGlobalConfig(globalRegistryConfig))
+		}
 	} else {
 		if globalRegistryConfig != nil {
 			opts = append(opts, dbc.WithGlobalConfig(globalRegistryConfig))
@@ -146,6 +172,7 @@ func resetClientState() {
 	dbcClientErr = nil
 	dbcClientOnce = &sync.Once{}
 	globalRegistryConfig = nil
+	offlineMode = false
 }
 
 func initDBCClient() error {
@@ -167,16 +194,86 @@ var getDriverRegistry = func() ([]dbc.Driver, error) {
 	return dbcClient.Search(context.Background(), "")
 }
 
+// loadDriver fetches the rest of a driver that a sharded index only
+// summarizes, which resolving a package needs. Other drivers are returned as
+// they are.
+func loadDriver(d dbc.Driver) (dbc.Driver, error) {
+	if !d.IsSummary() {
+		return d, nil
+	}
+	if err := initDBCClient(); err != nil {
+		return dbc.Driver{}, fmt.Errorf("failed to initialize client: %w", err)
+	}
+	return dbcClient.LoadDriver(context.Background(), d)
+}
+
+// findDriver locates a driver by name, or by a `registry/driver` reference
+// naming the registry it must come from. A bare name resolves to the first
+// registry that publishes it.
+func findDriver(ref string, drivers []dbc.Driver) (dbc.Driver, error) {
+	registry, name := splitDriverRef(ref)
+	return findRegistryDriver(registry, name, drivers)
+}
 
-func findDriver(name string, drivers []dbc.Driver) (dbc.Driver, error) {
+// findRegistryDriver locates the driver called name in the registry
+// identified by registry, a registry name or URL. An empty registry matches
+// the first registry that publishes the driver.
+func findRegistryDriver(registry, name string, drivers []dbc.Driver) (dbc.Driver, error) {
 	idx := slices.IndexFunc(drivers, func(d dbc.Driver) bool {
-		return d.Path == name
+		return d.Path == name && (registry == "" || registryMatches(d.Registry, registry))
 	})
+	if idx != -1 {
+		return drivers[idx], nil
+	}
+
+	if registry != "" {
+		if !slices.ContainsFunc(drivers, func(d dbc.Driver) bool { return registryMatches(d.Registry, registry) }) {
+			return dbc.Driver{}, fmt.Errorf("registry `%s` not found; check the registry name or URL in your configuration", registry)
+		}
+		return dbc.Driver{}, fmt.Errorf("driver `%s` not found in registry `%s`; try: `dbc search` to list available drivers", name, registry)
+	}
+	return dbc.Driver{}, fmt.Errorf("driver `%s` not found in driver registry index; try: `dbc search` to list available drivers", name)
+}
 
-	if idx == -1 {
-		return dbc.Driver{}, fmt.Errorf("driver `%s` not found in driver registry index; try: `dbc search` to list available drivers", name)
+// splitDriverRef splits a `registry/driver` reference at its last slash, so
+// the registry may be given by name or by URL. The registry is empty for a
+// bare driver name.
+func splitDriverRef(ref string) (registry, name string) {
+	i := strings.LastIndex(ref, "/")
+	if i == -1 {
+		return "", ref
 	}
-	return drivers[idx], nil
+	return ref[:i], ref[i+1:]
+}
+
+// registryMatches reports whether r is the registry identified by id, either
+// its configured name or its URL. URLs are compared without credentials.
+func registryMatches(r *dbc.Registry, id string) bool {
+	if r == nil {
+		return false
+	}
+	if r.Name != "" && r.Name == id {
+		return true
+	}
+	return r.BaseURL != nil && registryOrigin(r) == normalizeRegistryURL(stripUserinfo(id))
+}
+
+// registryOrigin returns the identifier recorded in the lockfile for drivers
+// resolved from r: its normalized URL without credentials.
+func registryOrigin(r *dbc.Registry) string {
+	if r == nil || r.BaseURL == nil {
+		return ""
+	}
+	return normalizeRegistryURL(stripUserinfo(r.BaseURL.String()))
+}
+
+func stripUserinfo(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	u.User = nil
+	return u.String()
 }
 
 type progressMsg struct {
@@ -184,8 +281,28 @@ type progressMsg struct {
 	written int64
 }
 
+// downloadRetryMsg reports that a package download failed and is about to
+// be retried.
+type downloadRetryMsg dbc.RetryEvent
+
+func (msg downloadRetryMsg) info() jsonschema.RetryInfo {
+	return jsonschema.RetryInfo{
+		Attempt:     msg.Attempt,
+		MaxAttempts: msg.MaxRetries,
+		WaitMs:      msg.Wait.Milliseconds(),
+		Offset:      msg.Offset,
+		Error:       msg.Err.Error(),
+	}
+}
+
 func downloadPkg(p dbc.PkgInfo) (*os.File, error) {
-	return p.DownloadPackage(func(written, total int64) {
+	if err := initDBCClient(); err != nil {
+		return nil, fmt.Errorf("failed to initialize client: %w", err)
+	}
+	ctx := dbc.WithRetryNotify(context.Background(), func(e dbc.RetryEvent) {
+		prog.Send(downloadRetryMsg(e))
+	})
+	return dbcClient.DownloadPackage(ctx, p, func(written, total int64) {
 		prog.Send(progressMsg{total: total, written: written})
 	})
 }
@@ -242,9 +359,15 @@ type cmds struct {
 	Add        *AddCmd          `arg:"subcommand" help:"Add a driver to the driver list"`
 	Remove     *RemoveCmd       `arg:"subcommand" help:"Remove a driver from the driver list"`
 	Sync       *SyncCmd         `arg:"subcommand" help:"Sync installed drivers with drivers in the driver list"`
+	Lock       *LockCmd         `arg:"subcommand" help:"Resolve the driver list and write the lockfile without installing drivers"`
+	Upgrade    *UpgradeCmd      `arg:"subcommand" help:"Upgrade drivers to the newest versions allowed by the driver list"`
+	Outdated   *OutdatedCmd     `arg:"subcommand" help:"Check installed and locked drivers for newer versions"`
+	Mirror     *MirrorCmd       `arg:"subcommand" help:"Copy drivers from the configured registries into a local registry"`
 	Auth       *AuthCmd         `arg:"subcommand" help:"Manage driver registry credentials"`
+	Registry   *RegistryCmd     `arg:"subcommand" help:"Build and manage driver registries"`
 	Completion *completions.Cmd `arg:"subcommand,hidden"`
 	Quiet      bool             `arg:"-q,--quiet" help:"Suppress all output"`
+	Offline    bool             `arg:"--offline,env:OFFLINE" help:"Only use cached driver registry indexes and packages (can also be set via DBC_OFFLINE)"`
 }
 
 func (cmds) Version() string {
@@ -262,6 +385,12 @@ func formatErr(err error) string {
 	case errors.Is(err, dbc.ErrUnauthorized):
 		return errStyle.Render(err.Error()) + "\n" +
 			msgStyle.Render("Did you run `dbc auth login`?")
+	case errors.Is(err, dbc.ErrNotCached):
+		return errStyle.Render(err.Error()) + "\n" +
+			msgStyle.Render("Run this command once with network access (without --offline) to populate the local cache.")
+	case errors.Is(err, dbc.ErrInvalidIndexSignature), errors.Is(err, dbc.ErrUnsignedIndex):
+		return errStyle.Render(err.Error()) + "\n" +
+			msgStyle.Render("The driver registry index could not be verified, so it was not used. Contact the registry's maintainer if this persists.")
 	case errors.Is(err, dbc.ErrUnauthorizedColumnar):
 		return errStyle.Render(err.Error()) + "\n" +
 			msgStyle.Render("Installing this driver requires a license. Verify you have an active license at https://console.columnar.tech/licenses and try this command again. Contact support@columnar.tech if you believe this is an error.")
@@ -329,7 +458,7 @@ const (
 	// startupNoSubcommand means the user provided no subcommand.
 	startupNoSubcommand
 	// startupHelpOnlyCmd is a subcommand that only prints its help text
-	// (AuthCmd, LicenseCmd, bare completions.Cmd).
+	// (AuthCmd, LicenseCmd, RegistryCmd, bare completions.Cmd).
 	startupHelpOnlyCmd
 	// startupCompletionShell means the user asked for a completion script.
 	startupCompletionShell
@@ -397,8 +526,10 @@ func runStartup(configDir string, argv []string) startupResult {
 		return startupResult{kind: startupNoSubcommand, parser: p, args: args}
 	}
 
+	offlineMode = args.Offline
+
 	switch sub := p.Subcommand().(type) {
-	case *AuthCmd, *LicenseCmd, *completions.Cmd:
+	case *AuthCmd, *LicenseCmd, *RegistryCmd, *completions.Cmd:
 		return startupResult{kind: startupHelpOnlyCmd, parser: p, args: args}
 	case completions.ShellImpl:
 		return startupResult{kind: startupCompletionShell, parser: p, args: args, shellScript: sub.GetScript()}
@@ -486,6 +617,9 @@ func main() {
 
 	var runErr error
 	if m, runErr = prog.Run(); runErr != nil {
+		if errors.Is(runErr, tea.ErrInterrupted) {
+			os.Exit(130)
+		}
 		fmt.Fprintln(os.Stderr, "Error running program:", runErr)
 		os.Exit(1)
 	}
@@ -0,0 +1,511 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/Masterminds/semver/v3"
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/columnar-tech/dbc/internal"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+)
+
+type MirrorCmd struct {
+	Dir      string   `arg:"positional,required" placeholder:"DIR" help:"Directory to write the mirror to"`
+	Driver   []string `arg:"positional" help:"Drivers to mirror, optionally with a version constraint (for example: mysql, mysql>=1,<2) [default: the drivers in the driver list]"`
+	Path     string   `arg:"-p" placeholder:"FILE" default:"./dbc.toml" help:"Driver list to mirror when no drivers are given"`
+	Platform []string `arg:"--platform,separate" help:"Only mirror packages for this platform (for example: linux_amd64); may be repeated [default: all platforms]"`
+	Pre      bool     `arg:"--pre" help:"Include pre-release versions"`
+	NoVerify bool     `arg:"--no-verify" help:"Allow mirroring drivers without a valid signature"`
+	Json     bool     `arg:"--json" help:"Print output as JSON instead of plaintext"`
+}
+
+func (MirrorCmd) Description() string {
+	return "Copy drivers from the configured registries into a local registry directory.\n\n" +
+		"Every version matching each driver's constraint is mirrored. Packages already in the mirror are not downloaded again, " +
+		"so re-running the command refreshes the mirror incrementally. Serve the result with `dbc registry serve` or any static file server."
+}
+
+func (c MirrorCmd) GetModelCustom(baseModel baseModel) tea.Model {
+	return mirrorModel{
+		baseModel:  baseModel,
+		dir:        c.Dir,
+		drivers:    c.Driver,
+		listPath:   c.Path,
+		platforms:  c.Platform,
+		pre:        c.Pre,
+		noVerify:   c.NoVerify,
+		jsonOutput: c.Json,
+		openPkg:    openPkg,
+	}
+}
+
+func (c MirrorCmd) GetModel() tea.Model {
+	return c.GetModelCustom(defaultBaseModel())
+}
+
+// openPkg streams a package from its registry. Unlike downloadPkg it does not
+// stage the tarball in a temporary directory, so callers can write it where
+// it belongs.
+func openPkg(p dbc.PkgInfo) (io.ReadCloser, error) {
+	if err := initDBCClient(); err != nil {
+		return nil, fmt.Errorf("failed to initialize client: %w", err)
+	}
+	return dbcClient.Download(context.Background(), p)
+}
+
+// mirrorFilter selects the versions of one driver to mirror.
+type mirrorFilter struct {
+	// registry, if set, is the name or URL of the registry the driver must
+	// come from, as pinned in the driver list or given as `registry/driver`.
+	registry    string
+	name        string
+	constraints *semver.Constraints
+	pre         bool
+}
+
+func (f mirrorFilter) matches(v *semver.Version) bool {
+	if f.constraints != nil {
+		return f.constraints.Check(v)
+	}
+	return f.pre || v.Prerelease() == ""
+}
+
+type mirroredPackage struct {
+	dbc.IndexEntry
+	// New is true if the package was downloaded by this run.
+	New bool
+}
+
+type mirrorDoneMsg struct {
+	indexPath string
+	packages  []mirroredPackage
+}
+
+type mirrorModel struct {
+	baseModel
+
+	dir        string
+	drivers    []string
+	listPath   string
+	platforms  []string
+	pre        bool
+	noVerify   bool
+	jsonOutput bool
+
+	openPkg func(dbc.PkgInfo) (io.ReadCloser, error)
+
+	indexPath string
+	packages  []mirroredPackage
+}
+
+func (m mirrorModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		filters, err := m.filters()
+		if err != nil {
+			return err
+		}
+
+		drivers, registryErr := m.getDriverRegistry()
+		if len(drivers) == 0 && registryErr != nil {
+			return fmt.Errorf("error getting driver list: %w", registryErr)
+		}
+
+		var packages []mirroredPackage
+		for _, f := range filters {
+			pkgs, err := m.mirrorDriver(f, drivers)
+			if err != nil {
+				return wrapWithRegistryContext(err, registryErr)
+			}
+			packages = append(packages, pkgs...)
+		}
+
+		indexPath := filepath.Join(m.dir, "index.yaml")
+		existing, err := os.ReadFile(indexPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error reading existing index %s: %w", indexPath, err)
+		}
+
+		entries := make([]dbc.IndexEntry, len(packages))
+		for i, p := range packages {
+			entries[i] = p.IndexEntry
+		}
+		data, err := dbc.UpdateIndex(existing, entries)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(m.dir, 0o755); err != nil {
+			return err
+		}
+		if err := internal.WriteFileAtomic(indexPath, data, 0o644); err != nil {
+			return fmt.Errorf("error writing index %s: %w", indexPath, err)
+		}
+		return mirrorDoneMsg{indexPath: indexPath, packages: packages}
+	}
+}
+
+// filters returns what to mirror: the drivers given on the command line or,
+// if there are none, those in the driver list. The driver list's registries
+// are applied as they are for dbc sync.
+func (m mirrorModel) filters() ([]mirrorFilter, error) {
+	if len(m.drivers) > 0 {
+		if err := applyProjectRegistriesFromCWD(); err != nil {
+			return nil, err
+		}
+		filters := make([]mirrorFilter, 0, len(m.drivers))
+		for _, d := range m.drivers {
+			ref, constraints, err := parseDriverConstraint(d)
+			if err != nil {
+				return nil, err
+			}
+			if constraints != nil && m.pre {
+				constraints.IncludePrerelease = true
+			}
+			registry, name := splitDriverRef(ref)
+			filters = append(filters, mirrorFilter{registry: registry, name: name, constraints: constraints, pre: m.pre})
+		}
+		return filters, nil
+	}
+
+	p, err := driverListPath(m.listPath)
+	if err != nil {
+		return nil, err
+	}
+	list, err := loadDriverList(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProjectRegistries(list); err != nil {
+		return nil, err
+	}
+
+	filters := make([]mirrorFilter, 0, len(list.Drivers))
+	for name, spec := range list.Drivers {
+		pre := m.pre || spec.Prerelease == "allow"
+		if spec.Version != nil && pre {
+			spec.Version.IncludePrerelease = true
+		}
+		filters = append(filters, mirrorFilter{registry: spec.Registry, name: name, constraints: spec.Version, pre: pre})
+	}
+	slices.SortFunc(filters, func(a, b mirrorFilter) int { return strings.Compare(a.name, b.name) })
+	return filters, nil
+}
+
+// mirrorDriver copies every package of the driver selected by f into the
+// mirror, skipping those that are already there and still match the upstream
+// index. The digest and size of each package are recorded for the mirror's
+// index.
+func (m mirrorModel) mirrorDriver(f mirrorFilter, drivers []dbc.Driver) ([]mirroredPackage, error) {
+	drv, err := findRegistryDriver(f.registry, f.name, drivers)
+	if err != nil {
+		return nil, err
+	}
+	if drv, err = loadDriver(drv); err != nil {
+		return nil, err
+	}
+
+	var packages []mirroredPackage
+	for _, v := range drv.AllVersions() {
+		if !f.matches(v.Version) {
+			continue
+		}
+		for _, p := range v.Packages {
+			if len(m.platforms) > 0 && !slices.Contains(m.platforms, p.Platform) {
+				continue
+			}
+
+			rel := path.Join(drv.Path, v.Version.String(),
+				drv.Path+"_"+p.Platform+"-"+v.Version.String()+".tar.gz")
+			pkg := mirroredPackage{IndexEntry: dbc.IndexEntry{
+				Driver:       drv,
+				Version:      v.Version,
+				Platform:     p.Platform,
+				URL:          rel,
+				Yanked:       v.Yanked,
+				YankedReason: v.YankedReason,
+			}}
+
+			info, err := drv.GetPackage(v.Version, p.Platform, true)
+			if err != nil {
+				return nil, fmt.Errorf("driver %s %s on %s: %w", drv.Path, v.Version, p.Platform, err)
+			}
+			dest := filepath.Join(m.dir, filepath.FromSlash(rel))
+			ok, err := m.isMirrored(info, dest)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				if err := m.fetch(info, dest); err != nil {
+					return nil, fmt.Errorf("failed to mirror %s %s on %s: %w", drv.Path, v.Version, p.Platform, err)
+				}
+				pkg.New = true
+			}
+
+			fi, err := os.Stat(dest)
+			if err != nil {
+				return nil, err
+			}
+			if pkg.SHA256, err = checksum(dest); err != nil {
+				return nil, err
+			}
+			pkg.Size = fi.Size()
+			packages = append(packages, pkg)
+		}
+	}
+
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("no packages of driver `%s` match the requested versions and platforms", drv.Path)
+	}
+	return packages, nil
+}
+
+// isMirrored reports whether dest already holds pkg. A package whose digest
+// or size differs from the upstream index's, or that fails the checks of a
+// download when the index lists neither, was truncated or tampered with and
+// is downloaded again rather than published in the mirror's index.
+func (m mirrorModel) isMirrored(pkg dbc.PkgInfo, dest string) (bool, error) {
+	fi, err := os.Stat(dest)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if pkg.Size > 0 && fi.Size() != pkg.Size {
+		return false, nil
+	}
+	if pkg.SHA256 != "" {
+		sum, err := checksum(dest)
+		if err != nil {
+			return false, err
+		}
+		return strings.EqualFold(sum, pkg.SHA256), nil
+	}
+	if pkg.Size > 0 {
+		return true, nil
+	}
+	return m.checkPackage(pkg, dest) == nil, nil
+}
+
+// fetch downloads pkg to dest. The package is staged next to dest and only
+// moved into place once it has been checked, so an interrupted run never
+// leaves a partial or unverified tarball in the mirror.
+func (m mirrorModel) fetch(pkg dbc.PkgInfo, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	body, err := m.openPkg(pkg)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := m.checkPackage(pkg, tmp.Name()); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// checkPackage checks that the tarball at p is a package of pkg's version,
+// signed unless the mirror is built with --no-verify.
+func (m mirrorModel) checkPackage(pkg dbc.PkgInfo, p string) error {
+	var manifest config.Manifest
+	var err error
+	if m.noVerify {
+		manifest, err = readPackageManifest(p)
+	} else {
+		manifest, err = verifyPackageSignature(p, pkg.Driver.Registry)
+	}
+	if err != nil {
+		return err
+	}
+	if manifest.Version == nil || !manifest.Version.Equal(pkg.Version) {
+		return fmt.Errorf("package MANIFEST has version %v, expected %s", manifest.Version, pkg.Version)
+	}
+	return nil
+}
+
+func readPackageManifest(p string) (config.Manifest, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return config.Manifest{}, err
+	}
+	defer f.Close()
+	return config.ReadTarballManifest(f)
+}
+
+// readTarballEntry calls fn with the contents of the file called name in the
+// tarball at p. It returns an error wrapping fs.ErrNotExist if there is no
+// such file.
+func readTarballEntry(p, name string, fn func(io.Reader) error) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("could not create gzip reader: %w", err)
+	}
+	defer gz.Close()
+
+	t := tar.NewReader(gz)
+	for {
+		hdr, err := t.Next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tarball: %w", err)
+		}
+		if hdr.Name == name {
+			return fn(t)
+		}
+	}
+}
+
+// verifyPackageSignature checks the signature of the driver library in the
+// tarball at p, downloaded from reg, without extracting it, and returns the
+// package's MANIFEST. Like verifySignature, packages whose MANIFEST names no
+// driver file are accepted as is.
+func verifyPackageSignature(p string, reg *dbc.Registry) (config.Manifest, error) {
+	manifest, err := readPackageManifest(p)
+	if err != nil {
+		return config.Manifest{}, err
+	}
+	if manifest.Files.Driver == "" {
+		return manifest, nil
+	}
+
+	sigFile := manifest.Files.Signature
+	if sigFile == "" {
+		sigFile = manifest.Files.Driver + ".sig"
+	}
+
+	var sig []byte
+	err = readTarballEntry(p, sigFile, func(r io.Reader) (err error) {
+		sig, err = io.ReadAll(r)
+		return err
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return config.Manifest{}, fmt.Errorf("signature file '%s' for driver is missing", sigFile)
+	} else if err != nil {
+		return config.Manifest{}, err
+	}
+
+	err = readTarballEntry(p, manifest.Files.Driver, func(lib io.Reader) error {
+		return reg.VerifySignature(lib, bytes.NewReader(sig))
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return config.Manifest{}, fmt.Errorf("driver file '%s' is missing", manifest.Files.Driver)
+	} else if err != nil {
+		return config.Manifest{}, fmt.Errorf("signature verification failed: %w", err)
+	}
+	return manifest, nil
+}
+
+func (m mirrorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case mirrorDoneMsg:
+		m.indexPath, m.packages = msg.indexPath, msg.packages
+		return m, tea.Quit
+	}
+
+	base, cmd := m.baseModel.Update(msg)
+	m.baseModel = base.(baseModel)
+	return m, cmd
+}
+
+func (m mirrorModel) IsJSONMode() bool { return m.jsonOutput }
+
+func (m mirrorModel) FinalOutput() string {
+	if m.status != 0 {
+		if m.jsonOutput {
+			return marshalEnvelope("error", jsonschema.ErrorResponse{
+				Code:    "mirror_failed",
+				Message: m.err.Error(),
+			})
+		}
+		return ""
+	}
+
+	if m.jsonOutput {
+		resp := jsonschema.MirrorResponse{
+			IndexPath: m.indexPath,
+			Packages:  make([]jsonschema.RegistryPackage, 0, len(m.packages)),
+		}
+		for _, p := range m.packages {
+			resp.Packages = append(resp.Packages, jsonschema.RegistryPackage{
+				Driver:   p.Driver.Path,
+				Version:  p.Version.String(),
+				Platform: p.Platform,
+				URL:      p.URL,
+				SHA256:   p.SHA256,
+				Size:     p.Size,
+				New:      p.New,
+			})
+		}
+		return marshalEnvelope("mirror.response", resp)
+	}
+
+	var b strings.Builder
+	added := 0
+	for _, p := range m.packages {
+		if p.New {
+			added++
+			fmt.Fprintf(&b, "+ %s %s %s\n", nameStyle.Render(p.Driver.Path), p.Version, msgStyle.Render(p.Platform))
+		}
+	}
+	fmt.Fprintf(&b, "Mirrored %d package(s), %d new, to %s\n", len(m.packages), added, filepath.Dir(m.indexPath))
+	return b.String()
+}
+
+func (m mirrorModel) View() tea.View { return tea.NewView("") }
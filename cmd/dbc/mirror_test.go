@@ -0,0 +1,265 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMirrorModel returns the model for cmd wired to the test registry.
+// Downloads are served by open, or by downloadTestPkg if open is nil.
+func testMirrorModel(cmd MirrorCmd, open func(dbc.PkgInfo) (io.ReadCloser, error)) mirrorModel {
+	m := cmd.GetModelCustom(testBaseModel()).(mirrorModel)
+	m.openPkg = open
+	if open == nil {
+		m.openPkg = func(p dbc.PkgInfo) (io.ReadCloser, error) { return downloadTestPkg(p) }
+	}
+	return m
+}
+
+func TestMirror(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mirror")
+
+	m, out := runRegistryModel(t, testMirrorModel(MirrorCmd{
+		Dir:      dir,
+		Driver:   []string{"test-driver-1>=1.1"},
+		Platform: []string{"linux_amd64", "macos_arm64"},
+	}, nil))
+	require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+	assert.Contains(t, out, "+ test-driver-1 1.1.0 linux_amd64")
+	assert.Contains(t, out, "+ test-driver-1 1.1.0 macos_arm64")
+	assert.Contains(t, out, "Mirrored 2 package(s), 2 new")
+	assert.FileExists(t, filepath.Join(dir, "test-driver-1", "1.1.0", "test-driver-1_linux_amd64-1.1.0.tar.gz"))
+
+	c, err := dbc.NewClient(dbc.WithBaseURL(dir), dbc.WithIndexCacheDir(""))
+	require.NoError(t, err)
+	drivers, err := c.Search(t.Context(), "test-driver-1")
+	require.NoError(t, err)
+	require.Len(t, drivers, 1)
+	assert.Equal(t, "This is a test driver", drivers[0].Desc)
+	versions := drivers[0].Versions("linux_amd64")
+	require.Len(t, versions, 1)
+	assert.Equal(t, "1.1.0", versions[0].String())
+	assert.Empty(t, drivers[0].Versions("windows_amd64"))
+
+	pkg, err := drivers[0].GetPackage(nil, "macos_arm64", false)
+	require.NoError(t, err)
+	assert.Len(t, pkg.SHA256, 64, "mirrored packages are indexed with their digest")
+	assert.NotZero(t, pkg.Size)
+	body, err := c.Download(t.Context(), pkg)
+	require.NoError(t, err)
+	body.Close()
+
+	t.Run("refresh is incremental", func(t *testing.T) {
+		var opened []string
+		open := func(p dbc.PkgInfo) (io.ReadCloser, error) {
+			opened = append(opened, p.Version.String()+" "+p.PlatformTuple)
+			return downloadTestPkg(p)
+		}
+
+		m, out := runRegistryModel(t, testMirrorModel(MirrorCmd{
+			Dir:      dir,
+			Driver:   []string{"test-driver-1"},
+			Platform: []string{"linux_amd64", "macos_arm64"},
+			Json:     true,
+		}, open))
+		require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+		assert.ElementsMatch(t, []string{"1.0.0 linux_amd64", "1.0.0 macos_arm64"}, opened)
+
+		var env jsonschema.Envelope
+		require.NoError(t, json.Unmarshal([]byte(out), &env))
+		assert.Equal(t, "mirror.response", env.Kind)
+		var resp jsonschema.MirrorResponse
+		require.NoError(t, json.Unmarshal(env.Payload, &resp))
+		assert.Equal(t, filepath.Join(dir, "index.yaml"), resp.IndexPath)
+		require.Len(t, resp.Packages, 4)
+		assert.True(t, resp.Packages[0].New)
+		assert.False(t, resp.Packages[2].New)
+		assert.Equal(t, "test-driver-1/1.1.0/test-driver-1_linux_amd64-1.1.0.tar.gz", resp.Packages[2].URL)
+
+		c, err := dbc.NewClient(dbc.WithBaseURL(dir), dbc.WithIndexCacheDir(""))
+		require.NoError(t, err)
+		drivers, err := c.Search(t.Context(), "test-driver-1")
+		require.NoError(t, err)
+		assert.Len(t, drivers[0].Versions("linux_amd64"), 2)
+	})
+
+	t.Run("damaged packages are downloaded again", func(t *testing.T) {
+		damaged := filepath.Join(dir, "test-driver-1", "1.1.0", "test-driver-1_linux_amd64-1.1.0.tar.gz")
+		require.NoError(t, os.WriteFile(damaged, []byte("truncated"), 0o644))
+
+		var opened []string
+		open := func(p dbc.PkgInfo) (io.ReadCloser, error) {
+			opened = append(opened, p.Version.String()+" "+p.PlatformTuple)
+			return downloadTestPkg(p)
+		}
+		m, _ := runRegistryModel(t, testMirrorModel(MirrorCmd{
+			Dir:      dir,
+			Driver:   []string{"test-driver-1"},
+			Platform: []string{"linux_amd64", "macos_arm64"},
+		}, open))
+		require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+		assert.Equal(t, []string{"1.1.0 linux_amd64"}, opened)
+
+		want, err := checksum(filepath.Join("testdata", "test-driver-1.1.tar.gz"))
+		require.NoError(t, err)
+		got, err := checksum(damaged)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestMirrorIsMirrored(t *testing.T) {
+	src := filepath.Join("testdata", "test-driver-1.1.tar.gz")
+	sum, err := checksum(src)
+	require.NoError(t, err)
+	fi, err := os.Stat(src)
+	require.NoError(t, err)
+
+	m := mirrorModel{}
+	tests := []struct {
+		name string
+		pkg  dbc.PkgInfo
+		want bool
+	}{
+		{"matching digest and size", dbc.PkgInfo{SHA256: strings.ToUpper(sum), Size: fi.Size()}, true},
+		{"digest mismatch", dbc.PkgInfo{SHA256: strings.Repeat("0", 64), Size: fi.Size()}, false},
+		{"size mismatch", dbc.PkgInfo{SHA256: sum, Size: fi.Size() + 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := m.isMirrored(tt.pkg, src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ok)
+		})
+	}
+
+	ok, err := m.isMirrored(dbc.PkgInfo{}, filepath.Join(t.TempDir(), "missing.tar.gz"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMirrorFromDriverList(t *testing.T) {
+	project := t.TempDir()
+	listPath := filepath.Join(project, "dbc.toml")
+	require.NoError(t, os.WriteFile(listPath, []byte(`[drivers]
+[drivers.test-driver-1]
+version = '=1.0.0'
+`), 0o644))
+
+	dir := filepath.Join(t.TempDir(), "mirror")
+	m, out := runRegistryModel(t, testMirrorModel(MirrorCmd{
+		Dir:      dir,
+		Path:     listPath,
+		Platform: []string{"windows_amd64"},
+	}, nil))
+	require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+	assert.Contains(t, out, "+ test-driver-1 1.0.0 windows_amd64")
+	assert.Contains(t, out, "Mirrored 1 package(s), 1 new")
+
+	t.Run("registry pin", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(listPath, []byte(`[drivers]
+[drivers.test-driver-1]
+registry = 'other'
+`), 0o644))
+		m, _ := runRegistryModel(t, testMirrorModel(MirrorCmd{
+			Dir:      t.TempDir(),
+			Path:     listPath,
+			Platform: []string{"windows_amd64"},
+		}, nil))
+		assert.Equal(t, 1, m.(HasStatus).Status())
+		assert.ErrorContains(t, m.(HasStatus).Err(), "registry `other` not found")
+	})
+}
+
+func TestMirrorErrors(t *testing.T) {
+	openFile := func(name string) func(dbc.PkgInfo) (io.ReadCloser, error) {
+		return func(dbc.PkgInfo) (io.ReadCloser, error) {
+			return os.Open(filepath.Join("testdata", name))
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cmd     MirrorCmd
+		open    func(dbc.PkgInfo) (io.ReadCloser, error)
+		wantErr string
+	}{
+		{
+			name:    "unknown driver",
+			cmd:     MirrorCmd{Driver: []string{"no-such-driver"}},
+			wantErr: "driver `no-such-driver` not found",
+		},
+		{
+			name:    "no matching packages",
+			cmd:     MirrorCmd{Driver: []string{"test-driver-1"}, Platform: []string{"plan9_amd64"}},
+			wantErr: "no packages of driver `test-driver-1` match",
+		},
+		{
+			name:    "missing signature",
+			cmd:     MirrorCmd{Driver: []string{"test-driver-1=1.1.0"}, Platform: []string{"linux_amd64"}},
+			open:    openFile("test-driver-no-sig.tar.gz"),
+			wantErr: "signature file 'test-driver-1-not-valid.so.sig' for driver is missing",
+		},
+		{
+			name:    "version mismatch",
+			cmd:     MirrorCmd{Driver: []string{"test-driver-1=1.0.0"}, Platform: []string{"linux_amd64"}, NoVerify: true},
+			open:    openFile("test-driver-1.1.tar.gz"),
+			wantErr: "package MANIFEST has version 1.1.0, expected 1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.cmd.Dir = dir
+			m, _ := runRegistryModel(t, testMirrorModel(tt.cmd, tt.open))
+			assert.Equal(t, 1, m.(HasStatus).Status())
+			assert.ErrorContains(t, m.(HasStatus).Err(), tt.wantErr)
+
+			// Nothing is left behind by a failed download.
+			assert.NoFileExists(t, filepath.Join(dir, "index.yaml"))
+			entries, err := os.ReadDir(filepath.Join(dir, "test-driver-1"))
+			if err == nil {
+				for _, e := range entries {
+					files, _ := os.ReadDir(filepath.Join(dir, "test-driver-1", e.Name()))
+					assert.Empty(t, files)
+				}
+			}
+		})
+	}
+
+	t.Run("json error", func(t *testing.T) {
+		m, out := runRegistryModel(t, testMirrorModel(MirrorCmd{
+			Dir: t.TempDir(), Driver: []string{"no-such-driver"}, Json: true,
+		}, nil))
+		assert.Equal(t, 1, m.(HasStatus).Status())
+
+		var env jsonschema.Envelope
+		require.NoError(t, json.Unmarshal([]byte(out), &env))
+		assert.Equal(t, "error", env.Kind)
+		assert.Contains(t, string(env.Payload), "mirror_failed")
+	})
+}
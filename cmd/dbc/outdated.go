@@ -0,0 +1,366 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/Masterminds/semver/v3"
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+)
+
+type OutdatedCmd struct {
+	Path string `arg:"-p" placeholder:"FILE" default:"./dbc.toml" help:"Driver list whose lockfile to check"`
+	Json bool   `arg:"--json" help:"Print output as JSON instead of plaintext"`
+}
+
+func (OutdatedCmd) Description() string {
+	return "Check installed and locked drivers for newer versions.\n\n" +
+		"Every driver installed at the user, system or environment config level, and every driver in the lockfile " +
+		"next to the driver list, is compared with the newest version its driver list constraints allow and the newest " +
+		"version its registry publishes. Exits with status 1 if any driver is out of date."
+}
+
+func (c OutdatedCmd) GetModelCustom(baseModel baseModel) tea.Model {
+	return outdatedModel{
+		baseModel:  baseModel,
+		path:       c.Path,
+		jsonOutput: c.Json,
+	}
+}
+
+func (c OutdatedCmd) GetModel() tea.Model {
+	return c.GetModelCustom(defaultBaseModel())
+}
+
+// outdatedSource is an installed or locked driver to check.
+type outdatedSource struct {
+	name     string
+	source   string
+	location string
+	version  *semver.Version
+	registry string
+	spec     driverSpec
+}
+
+type outdatedMsg []jsonschema.OutdatedDriver
+
+type outdatedModel struct {
+	baseModel
+
+	path       string
+	jsonOutput bool
+	drivers    []jsonschema.OutdatedDriver
+}
+
+// lockedSources returns the drivers locked for the driver list at p, with
+// their constraints from the list. A missing driver list or lockfile has no
+// locked drivers.
+func lockedSources(p string) ([]outdatedSource, error) {
+	if _, err := os.Stat(p); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	list, err := openAndDecodeDriverList(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProjectRegistries(list); err != nil {
+		return nil, err
+	}
+
+	lockPath := strings.TrimSuffix(p, filepath.Ext(p)) + ".lock"
+	lf, err := loadLockFile(lockPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	sources := make([]outdatedSource, 0, len(lf.Drivers))
+	for _, info := range lf.Drivers {
+		spec := list.Drivers[info.Name]
+		registry := spec.Registry
+		if registry == "" {
+			registry = info.Registry
+		}
+		sources = append(sources, outdatedSource{
+			name:     info.Name,
+			source:   "lockfile",
+			location: lockPath,
+			version:  info.Version,
+			registry: registry,
+			spec:     spec,
+		})
+	}
+	return sources, nil
+}
+
+// installedSources returns the drivers installed at every config level.
+func installedSources() ([]outdatedSource, error) {
+	var sources []outdatedSource
+	for lvl, cfg := range config.Get() {
+		if cfg.Err != nil {
+			return nil, fmt.Errorf("failed to list drivers at %s level: %w", lvl, cfg.Err)
+		}
+		for _, d := range cfg.Drivers {
+			if d.Version == nil {
+				continue
+			}
+			sources = append(sources, outdatedSource{
+				name:     d.ID,
+				source:   lvl.String(),
+				location: d.FilePath,
+				version:  d.Version,
+			})
+		}
+	}
+	return sources, nil
+}
+
+// row returns the report row for src, before it is checked.
+func (src outdatedSource) row() jsonschema.OutdatedDriver {
+	return jsonschema.OutdatedDriver{
+		Driver:   src.name,
+		Source:   src.source,
+		Location: src.location,
+		Current:  src.version.String(),
+	}
+}
+
+// checkOutdated compares src with the newest versions of drv that aren't
+// yanked and have a package for this platform. Pre-releases are only
+// considered if the driver list allows them or src is one. A driver that
+// can't be checked is reported with its error rather than failing the
+// whole report.
+func checkOutdated(src outdatedSource, drv dbc.Driver) jsonschema.OutdatedDriver {
+	d := src.row()
+	allowPre := src.spec.Prerelease == "allow" || src.version.Prerelease() != ""
+
+	anyVersion, err := semver.NewConstraint("*")
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	anyVersion.IncludePrerelease = allowPre
+	latest, err := drv.GetWithConstraint(anyVersion, config.PlatformTuple())
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	d.Latest = latest.Version.String()
+
+	wanted := latest
+	if src.spec.Version != nil {
+		c := *src.spec.Version
+		c.IncludePrerelease = allowPre
+		if wanted, err = drv.GetWithConstraint(&c, config.PlatformTuple()); err != nil {
+			d.Error = err.Error()
+			return d
+		}
+	}
+	d.Wanted = wanted.Version.String()
+	d.Outdated = src.version.LessThan(wanted.Version) || src.version.LessThan(latest.Version)
+	return d
+}
+
+func (m outdatedModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		p, err := driverListPath(m.path)
+		if err != nil {
+			return err
+		}
+		locked, err := lockedSources(p)
+		if err != nil {
+			return err
+		}
+		installed, err := installedSources()
+		if err != nil {
+			return err
+		}
+		if len(locked)+len(installed) == 0 {
+			return outdatedMsg(nil)
+		}
+
+		index, registryErr := m.getDriverRegistry()
+		if len(index) == 0 && registryErr != nil {
+			return fmt.Errorf("error getting driver list: %w", registryErr)
+		}
+
+		var drivers []jsonschema.OutdatedDriver
+		for _, src := range locked {
+			drv, err := findRegistryDriver(src.registry, src.name, index)
+			if err != nil && src.spec.Registry == "" {
+				// the registry it was locked from may have been removed
+				drv, err = findRegistryDriver("", src.name, index)
+			}
+			if err == nil {
+				drv, err = loadDriver(drv)
+			}
+			if err != nil {
+				d := src.row()
+				d.Error = wrapWithRegistryContext(err, registryErr).Error()
+				drivers = append(drivers, d)
+				continue
+			}
+			drivers = append(drivers, checkOutdated(src, drv))
+		}
+		for _, src := range installed {
+			// drivers that weren't installed from a registry have nothing to
+			// compare with
+			drv, err := findRegistryDriver("", src.name, index)
+			if err != nil {
+				continue
+			}
+			if drv, err = loadDriver(drv); err != nil {
+				d := src.row()
+				d.Error = err.Error()
+				drivers = append(drivers, d)
+				continue
+			}
+			drivers = append(drivers, checkOutdated(src, drv))
+		}
+
+		sort.SliceStable(drivers, func(i, j int) bool {
+			if drivers[i].Source != drivers[j].Source {
+				return outdatedSourceRank(drivers[i].Source) < outdatedSourceRank(drivers[j].Source)
+			}
+			return drivers[i].Driver < drivers[j].Driver
+		})
+		return outdatedMsg(drivers)
+	}
+}
+
+// outdatedSourceRank orders the lockfile before the config levels, which
+// are in the order `dbc list` shows them.
+func outdatedSourceRank(source string) int {
+	switch source {
+	case "lockfile":
+		return 0
+	case config.ConfigEnv.String():
+		return 1
+	case config.ConfigUser.String():
+		return 2
+	default:
+		return 3
+	}
+}
+
+func (m outdatedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case outdatedMsg:
+		m.drivers = msg
+		for _, d := range m.drivers {
+			if d.Outdated {
+				m.status = 1
+			}
+		}
+		return m, tea.Quit
+	}
+
+	bm, cmd := m.baseModel.Update(msg)
+	m.baseModel = bm.(baseModel)
+	return m, cmd
+}
+
+func (m outdatedModel) View() tea.View { return tea.NewView("") }
+
+func (m outdatedModel) IsJSONMode() bool { return m.jsonOutput }
+
+func (m outdatedModel) FinalOutput() string {
+	if m.err != nil {
+		if m.jsonOutput {
+			return marshalEnvelope("error", jsonschema.ErrorResponse{
+				Code:    "outdated_failed",
+				Message: m.err.Error(),
+			})
+		}
+		return ""
+	}
+
+	if m.jsonOutput {
+		drivers := m.drivers
+		if drivers == nil {
+			drivers = []jsonschema.OutdatedDriver{}
+		}
+		return marshalEnvelope("outdated.response", jsonschema.OutdatedResponse{Drivers: drivers})
+	}
+	return formatOutdated(m.drivers)
+}
+
+// formatOutdated renders the current, wanted and latest version of each
+// driver as a table, marking those that are out of date.
+func formatOutdated(drivers []jsonschema.OutdatedDriver) string {
+	if len(drivers) == 0 {
+		return "No installed or locked drivers found."
+	}
+
+	t := table.New().Border(lipgloss.HiddenBorder()).
+		BorderTop(false).BorderBottom(false).BorderLeft(false).BorderRight(false).
+		Headers("DRIVER", "SOURCE", "CURRENT", "WANTED", "LATEST")
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	sourceStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63"))
+	versionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	t.StyleFunc(func(row, col int) lipgloss.Style {
+		if row == table.HeaderRow {
+			return headerStyle
+		}
+		switch col {
+		case 0:
+			return nameStyle
+		case 1:
+			return sourceStyle
+		case 2:
+			if drivers[row].Outdated {
+				return warningStyle
+			}
+			return versionStyle
+		}
+		return versionStyle
+	})
+
+	outdated := 0
+	var problems []string
+	for _, d := range drivers {
+		t.Row(d.Driver, d.Source, d.Current, cmp.Or(d.Wanted, "-"), cmp.Or(d.Latest, "-"))
+		if d.Outdated {
+			outdated++
+		}
+		if d.Error != "" {
+			problems = append(problems, warningStyle.Render("Warning: ")+
+				fmt.Sprintf("could not check %s (%s): %s", d.Driver, d.Source, d.Error))
+		}
+	}
+
+	out := strings.TrimRight(t.String(), "\n") + "\n"
+	for _, p := range problems {
+		out += p + "\n"
+	}
+	if outdated == 0 {
+		return out + msgStyle.Render("All drivers are up to date.")
+	}
+	return out + msgStyle.Render(fmt.Sprintf("%d driver(s) out of date.", outdated))
+}
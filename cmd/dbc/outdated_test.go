@@ -0,0 +1,117 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/columnar-tech/dbc/config"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+)
+
+func (suite *SubcommandTestSuite) outdatedResponse(out string) jsonschema.OutdatedResponse {
+	var env jsonschema.Envelope
+	suite.Require().NoError(json.Unmarshal([]byte(strings.TrimSpace(out)), &env))
+	suite.Equal("outdated.response", env.Kind)
+	var resp jsonschema.OutdatedResponse
+	suite.Require().NoError(json.Unmarshal(env.Payload, &resp))
+	return resp
+}
+
+func (suite *SubcommandTestSuite) TestOutdated() {
+	listPath := filepath.Join(suite.tempdir, "project", "dbc.toml")
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(listPath), 0o755))
+
+	suite.Run("nothing installed", func() {
+		out := suite.runCmd(OutdatedCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		suite.Contains(out, "No installed or locked drivers found.")
+	})
+
+	suite.runCmd(InstallCmd{Driver: "test-driver-1=1.1.0", Level: config.ConfigEnv}.GetModelCustom(testBaseModel()))
+	suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\nversion = '<1.1.0'\n\n[drivers.test-driver-2]\n"), 0o644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(filepath.Dir(listPath), "dbc.lock"), []byte(`version = 1
+
+[[drivers]]
+name = 'test-driver-1'
+version = '1.0.0'
+
+[[drivers]]
+name = 'test-driver-2'
+version = '2.1.0'
+`), 0o644))
+
+	out := suite.runCmdErr(OutdatedCmd{Path: listPath, Json: true}.GetModelCustom(testBaseModel()))
+	lockPath := filepath.Join(filepath.Dir(listPath), "dbc.lock")
+	drivers := suite.outdatedResponse(out).Drivers
+	suite.Require().Len(drivers, 3)
+	suite.Equal(jsonschema.OutdatedDriver{Driver: "test-driver-1", Source: "lockfile", Location: lockPath,
+		Current: "1.0.0", Wanted: "1.0.0", Latest: "1.1.0", Outdated: true}, drivers[0])
+	suite.Equal(jsonschema.OutdatedDriver{Driver: "test-driver-2", Source: "lockfile", Location: lockPath,
+		Current: "2.1.0", Wanted: "2.1.0", Latest: "2.1.0"}, drivers[1])
+	suite.Equal("test-driver-1", drivers[2].Driver)
+	suite.Equal("env", drivers[2].Source)
+	suite.Equal("1.1.0", drivers[2].Current)
+	suite.False(drivers[2].Outdated)
+
+	out = suite.runCmdErr(OutdatedCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+	suite.Regexp(`test-driver-1\s+lockfile\s+1\.0\.0\s+1\.0\.0\s+1\.1\.0`, out)
+	suite.Regexp(`test-driver-1\s+env\s+1\.1\.0\s+1\.1\.0\s+1\.1\.0`, out)
+	suite.Contains(out, "1 driver(s) out of date.")
+
+	suite.Run("up to date", func() {
+		suite.Require().NoError(os.WriteFile(lockPath, []byte("version = 1\n"), 0o644))
+		out := suite.runCmd(OutdatedCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		suite.Regexp(`test-driver-1\s+env\s+1\.1\.0`, out)
+		suite.Contains(out, "All drivers are up to date.")
+	})
+
+	suite.Run("yanked versions are not latest", func() {
+		yankedRegistry := baseModel{getDriverRegistry: getYankedTestDriverRegistry, downloadPkg: downloadTestPkg}
+		out := suite.runCmd(OutdatedCmd{Path: listPath, Json: true}.GetModelCustom(yankedRegistry))
+		drivers := suite.outdatedResponse(out).Drivers
+		suite.Require().Len(drivers, 1)
+		suite.Equal("1.0.0", drivers[0].Latest)
+		suite.False(drivers[0].Outdated)
+	})
+
+	suite.Run("problems are reported per driver", func() {
+		suite.Require().NoError(os.WriteFile(lockPath, []byte(`version = 1
+
+[[drivers]]
+name = 'not-a-driver'
+version = '1.0.0'
+
+[[drivers]]
+name = 'test-driver-1'
+version = '1.0.0'
+`), 0o644))
+		out := suite.runCmdErr(OutdatedCmd{Path: listPath, Json: true}.GetModelCustom(testBaseModel()))
+		drivers := suite.outdatedResponse(out).Drivers
+		suite.Require().Len(drivers, 3)
+		suite.Equal("not-a-driver", drivers[0].Driver)
+		suite.Contains(drivers[0].Error, "not-a-driver")
+		suite.Empty(drivers[0].Latest)
+		suite.Equal(jsonschema.OutdatedDriver{Driver: "test-driver-1", Source: "lockfile", Location: lockPath,
+			Current: "1.0.0", Wanted: "1.0.0", Latest: "1.1.0", Outdated: true}, drivers[1])
+
+		out = suite.runCmdErr(OutdatedCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		suite.Regexp(`not-a-driver\s+lockfile\s+1\.0\.0\s+-\s+-`, out)
+		suite.Contains(out, "could not check not-a-driver (lockfile)")
+		suite.Contains(out, "1 driver(s) out of date.")
+	})
+}
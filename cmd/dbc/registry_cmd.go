@@ -0,0 +1,185 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/internal"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+)
+
+type RegistryCmd struct {
+	Build *RegistryBuildCmd `arg:"subcommand" help:"Generate or update a registry index.yaml from driver tarballs"`
+	Lint  *RegistryLintCmd  `arg:"subcommand" help:"Check a registry index and its packages for problems"`
+	Serve *RegistryServeCmd `arg:"subcommand" help:"Serve a registry directory over HTTP"`
+}
+
+type RegistryBuildCmd struct {
+	Dir     string `arg:"positional" default:"." help:"Registry directory containing driver tarballs"`
+	Output  string `arg:"-o,--output" help:"Path of the index to write [default: <dir>/index.yaml]"`
+	Sharded bool   `arg:"--sharded" help:"Write a sharded index: a summary index.yaml plus drivers/<driver>.yaml for each driver"`
+	Json    bool   `arg:"--json" help:"Print output as JSON instead of plaintext"`
+}
+
+func (c RegistryBuildCmd) GetModel() tea.Model {
+	return registryBuildModel{dir: c.Dir, output: c.Output, sharded: c.Sharded, jsonOutput: c.Json}
+}
+
+type registryBuildDoneMsg struct {
+	path     string
+	packages []dbc.BuiltPackage
+}
+
+type registryBuildModel struct {
+	dir        string
+	output     string
+	sharded    bool
+	jsonOutput bool
+
+	indexPath string
+	packages  []dbc.BuiltPackage
+
+	status int
+	err    error
+}
+
+func (m registryBuildModel) Status() int { return m.status }
+func (m registryBuildModel) Err() error  { return m.err }
+
+func (m registryBuildModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		output := m.output
+		if output == "" {
+			output = filepath.Join(m.dir, "index.yaml")
+		}
+
+		// Package URLs are relative to the directory of the index, and the
+		// files of a sharded index sit next to its index.yaml.
+		dir, err := filepath.Abs(m.dir)
+		if err != nil {
+			return err
+		}
+		indexDir, err := filepath.Abs(filepath.Dir(output))
+		if err != nil {
+			return err
+		}
+		existing, err := os.ReadFile(output)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error reading existing index %s: %w", output, err)
+		}
+		if existing != nil {
+			existing, err = dbc.JoinIndex(existing, func(name string) ([]byte, error) {
+				return os.ReadFile(filepath.Join(indexDir, filepath.FromSlash(name)))
+			})
+			if err != nil {
+				return fmt.Errorf("error reading existing index %s: %w", output, err)
+			}
+		}
+
+		data, pkgs, err := dbc.BuildIndex(dir, indexDir, existing)
+		if err != nil {
+			return err
+		}
+
+		if m.sharded {
+			var files map[string][]byte
+			if data, files, err = dbc.ShardIndex(data); err != nil {
+				return err
+			}
+			// Write the driver files first, so the summary never lists a
+			// driver whose file isn't there yet.
+			for name, contents := range files {
+				p := filepath.Join(indexDir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+					return fmt.Errorf("error writing index %s: %w", p, err)
+				}
+				if err := internal.WriteFileAtomic(p, contents, 0o644); err != nil {
+					return fmt.Errorf("error writing index %s: %w", p, err)
+				}
+			}
+		}
+
+		if err := internal.WriteFileAtomic(output, data, 0o644); err != nil {
+			return fmt.Errorf("error writing index %s: %w", output, err)
+		}
+		return registryBuildDoneMsg{path: output, packages: pkgs}
+	}
+}
+
+func (m registryBuildModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case registryBuildDoneMsg:
+		m.indexPath, m.packages = msg.path, msg.packages
+		return m, tea.Quit
+	case error:
+		m.status, m.err = 1, msg
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m registryBuildModel) IsJSONMode() bool { return m.jsonOutput }
+
+func (m registryBuildModel) FinalOutput() string {
+	if m.status != 0 {
+		if m.jsonOutput {
+			return marshalEnvelope("error", jsonschema.ErrorResponse{
+				Code:    "registry_build_failed",
+				Message: m.err.Error(),
+			})
+		}
+		return ""
+	}
+
+	if m.jsonOutput {
+		resp := jsonschema.RegistryBuildResponse{
+			IndexPath: m.indexPath,
+			Packages:  make([]jsonschema.RegistryPackage, 0, len(m.packages)),
+		}
+		for _, p := range m.packages {
+			resp.Packages = append(resp.Packages, jsonschema.RegistryPackage{
+				Driver:   p.Driver,
+				Version:  p.Version.String(),
+				Platform: p.Platform,
+				URL:      p.URL,
+				SHA256:   p.SHA256,
+				Size:     p.Size,
+				New:      p.New,
+			})
+		}
+		return marshalEnvelope("registry.build.response", resp)
+	}
+
+	var b strings.Builder
+	added := 0
+	for _, p := range m.packages {
+		if p.New {
+			added++
+			fmt.Fprintf(&b, "+ %s %s %s\n", nameStyle.Render(p.Driver), p.Version, msgStyle.Render(p.Platform))
+		}
+	}
+	fmt.Fprintf(&b, "Indexed %d package(s), %d new, in %s\n", len(m.packages), added, m.indexPath)
+	return b.String()
+}
+
+func (m registryBuildModel) View() tea.View { return tea.NewView("") }
@@ -0,0 +1,172 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runRegistryModel runs m to completion and returns the final model and its
+// FinalOutput, if any, with ANSI styling stripped.
+func runRegistryModel(t *testing.T, m tea.Model) (tea.Model, string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+
+	var out bytes.Buffer
+	p := tea.NewProgram(m, tea.WithInput(nil), tea.WithOutput(&out),
+		tea.WithoutRenderer(), tea.WithContext(ctx))
+	final, err := p.Run()
+	require.NoError(t, err)
+	if fo, ok := final.(HasFinalOutput); ok {
+		return final, ansi.Strip(fo.FinalOutput())
+	}
+	return final, ""
+}
+
+// newRegistryDir lays out a registry directory holding the test-driver-1
+// tarball for linux_amd64.
+func newRegistryDir(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "test-driver-1.tar.gz"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "test-driver-1", "1.0.0", "test-driver-1_linux_amd64-1.0.0.tar.gz")
+	require.NoError(t, os.MkdirAll(filepath.Dir(pkg), 0o755))
+	require.NoError(t, os.WriteFile(pkg, data, 0o644))
+	return dir
+}
+
+func TestRegistryBuild(t *testing.T) {
+	dir := newRegistryDir(t)
+
+	m, out := runRegistryModel(t, RegistryBuildCmd{Dir: dir}.GetModel())
+	require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+	assert.Contains(t, out, "+ test-driver-1 1.0.0 linux_amd64")
+	assert.Contains(t, out, "Indexed 1 package(s), 1 new")
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "url: test-driver-1/1.0.0/test-driver-1_linux_amd64-1.0.0.tar.gz")
+
+	// A rebuild with a hand-edited description keeps it and reports nothing new.
+	edited := bytes.Replace(index, []byte("    license: MIT\n"),
+		[]byte("    description: Edited by hand\n    license: MIT\n"), 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), edited, 0o644))
+
+	m, out = runRegistryModel(t, RegistryBuildCmd{Dir: dir, Json: true}.GetModel())
+	require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+
+	var env jsonschema.Envelope
+	require.NoError(t, json.Unmarshal([]byte(out), &env))
+	assert.Equal(t, "registry.build.response", env.Kind)
+	var resp jsonschema.RegistryBuildResponse
+	require.NoError(t, json.Unmarshal(env.Payload, &resp))
+	assert.Equal(t, filepath.Join(dir, "index.yaml"), resp.IndexPath)
+	require.Len(t, resp.Packages, 1)
+	assert.False(t, resp.Packages[0].New)
+
+	index, err = os.ReadFile(filepath.Join(dir, "index.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "description: Edited by hand")
+}
+
+func TestRegistryBuildSharded(t *testing.T) {
+	dir := newRegistryDir(t)
+
+	m, _ := runRegistryModel(t, RegistryBuildCmd{Dir: dir, Sharded: true}.GetModel())
+	require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "layout: sharded")
+	assert.NotContains(t, string(index), "pkginfo")
+	driver, err := os.ReadFile(filepath.Join(dir, "drivers", "test-driver-1.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(driver), "url: test-driver-1/1.0.0/test-driver-1_linux_amd64-1.0.0.tar.gz")
+
+	// Hand-edited fields in the driver's own file survive a rebuild.
+	edited := bytes.Replace(driver, []byte("license: MIT\n"),
+		[]byte("description: Edited by hand\nlicense: MIT\n"), 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "drivers", "test-driver-1.yaml"), edited, 0o644))
+
+	m, out := runRegistryModel(t, RegistryBuildCmd{Dir: dir, Sharded: true}.GetModel())
+	require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+	assert.Contains(t, out, "Indexed 1 package(s), 0 new")
+	driver, err = os.ReadFile(filepath.Join(dir, "drivers", "test-driver-1.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(driver), "description: Edited by hand")
+	index, err = os.ReadFile(filepath.Join(dir, "index.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "description: Edited by hand")
+}
+func TestRegistryBuildOutputAndErrors(t *testing.T) {
+	t.Run("custom output path", func(t *testing.T) {
+		dir := newRegistryDir(t)
+		output := filepath.Join(dir, "staged.yaml")
+
+		m, _ := runRegistryModel(t, RegistryBuildCmd{Dir: dir, Output: output}.GetModel())
+		require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+		assert.FileExists(t, output)
+		assert.NoFileExists(t, filepath.Join(dir, "index.yaml"))
+	})
+
+	t.Run("output in a parent directory", func(t *testing.T) {
+		dir := newRegistryDir(t)
+		output := filepath.Join(filepath.Dir(dir), "index.yaml")
+
+		m, _ := runRegistryModel(t, RegistryBuildCmd{Dir: dir, Output: output}.GetModel())
+		require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+		index, err := os.ReadFile(output)
+		require.NoError(t, err)
+		assert.Contains(t, string(index), "url: "+filepath.Base(dir)+"/test-driver-1/1.0.0/test-driver-1_linux_amd64-1.0.0.tar.gz")
+	})
+
+	t.Run("output outside the packages' tree", func(t *testing.T) {
+		dir := newRegistryDir(t)
+		output := filepath.Join(t.TempDir(), "index.yaml")
+
+		m, _ := runRegistryModel(t, RegistryBuildCmd{Dir: dir, Output: output}.GetModel())
+		assert.Equal(t, 1, m.(HasStatus).Status())
+		assert.ErrorContains(t, m.(HasStatus).Err(), "outside")
+		assert.NoFileExists(t, output)
+	})
+
+	t.Run("json error", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), []byte("drivers: ["), 0o644))
+
+		m, out := runRegistryModel(t, RegistryBuildCmd{Dir: dir, Json: true}.GetModel())
+		assert.Equal(t, 1, m.(HasStatus).Status())
+
+		var env jsonschema.Envelope
+		require.NoError(t, json.Unmarshal([]byte(out), &env))
+		assert.Equal(t, "error", env.Kind)
+		assert.Contains(t, string(env.Payload), "registry_build_failed")
+	})
+}
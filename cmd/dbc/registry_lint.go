@@ -0,0 +1,246 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+)
+
+type RegistryLintCmd struct {
+	Registry   string   `arg:"positional" default:"." help:"Registry URL or directory, or the path of its index.yaml or index.json"`
+	Deep       bool     `arg:"--deep" help:"Also download every package and check its MANIFEST and signature"`
+	SigningKey []string `arg:"--signing-key,separate" placeholder:"KEY" help:"Trust this OpenPGP public key, or file containing one, to sign the registry's index and packages; may be repeated"`
+	Json       bool     `arg:"--json" help:"Print output as JSON instead of plaintext"`
+}
+
+func (RegistryLintCmd) Description() string {
+	return "Check a registry index and the packages it lists before publishing it.\n\n" +
+		"Every version must be valid semver and listed once, and every package URL must resolve. " +
+		"With --deep, each package is also downloaded and checked the way `dbc install` would: " +
+		"its digest, its MANIFEST version and the signature of its driver library."
+}
+
+func (c RegistryLintCmd) GetModel() tea.Model {
+	return registryLintModel{
+		location:    c.Registry,
+		deep:        c.Deep,
+		signingKeys: c.SigningKey,
+		jsonOutput:  c.Json,
+	}
+}
+
+type registryLintDoneMsg struct {
+	packages int
+	issues   []dbc.IndexIssue
+}
+
+type registryLintModel struct {
+	location    string
+	deep        bool
+	signingKeys []string
+	jsonOutput  bool
+
+	packages int
+	issues   []dbc.IndexIssue
+
+	status int
+	err    error
+}
+
+func (m registryLintModel) Status() int { return m.status }
+func (m registryLintModel) Err() error  { return m.err }
+
+// registryLocation returns the registry named by loc: a URL or directory,
+// either of which may be given as the path of its index.yaml or index.json.
+// Local paths are made absolute so they aren't mistaken for URLs.
+func registryLocation(loc string) (string, error) {
+	if strings.Contains(loc, "://") {
+		for _, name := range []string{"/index.yaml", "/index.json"} {
+			loc = strings.TrimSuffix(loc, name)
+		}
+		return strings.TrimSuffix(loc, "/"), nil
+	}
+	if base := filepath.Base(loc); base == "index.yaml" || base == "index.json" {
+		loc = filepath.Dir(loc)
+	}
+	return filepath.Abs(loc)
+}
+
+func (m registryLintModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		loc, err := registryLocation(m.location)
+		if err != nil {
+			return err
+		}
+
+		// Lint checks what the registry serves, so nothing is read from or
+		// written to the package cache, whatever --offline says.
+		replace := true
+		client, err := dbc.NewClient(
+			dbc.WithProjectRegistries([]dbc.RegistryEntry{{URL: loc, SigningKeys: m.signingKeys}}, &replace),
+			dbc.WithPackageCacheDir(""),
+		)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		issues, drivers, err := client.LintRegistry(ctx, &client.Registries()[0])
+		if err != nil {
+			return fmt.Errorf("error reading registry %s: %w", m.location, err)
+		}
+
+		packages := 0
+		for _, d := range drivers {
+			for _, v := range d.AllVersions() {
+				for _, p := range v.Packages {
+					packages++
+					if !m.deep || hasIssue(issues, d.Path, v.Version.String(), p.Platform) {
+						continue
+					}
+
+					pkg, err := d.GetPackage(v.Version, p.Platform, true)
+					if err != nil {
+						return err
+					}
+					for _, err := range lintPackage(ctx, client, pkg) {
+						issues = append(issues, dbc.IndexIssue{
+							Driver:   d.Path,
+							Version:  v.Version.String(),
+							Platform: p.Platform,
+							Message:  err.Error(),
+						})
+					}
+				}
+			}
+		}
+		return registryLintDoneMsg{packages: packages, issues: issues}
+	}
+}
+
+// hasIssue reports whether a problem was already found with the package of
+// driver at version on platform, such as it not resolving.
+func hasIssue(issues []dbc.IndexIssue, driver, version, platform string) bool {
+	for _, i := range issues {
+		if i.Driver == driver && i.Version == version && i.Platform == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// lintPackage downloads pkg and checks it the way installing it would: its
+// digest and size, its MANIFEST, and the signature of its driver library.
+// The MANIFEST must also give the version the index lists the package under.
+func lintPackage(ctx context.Context, client *dbc.Client, pkg dbc.PkgInfo) []error {
+	f, err := client.DownloadPackage(ctx, pkg, nil)
+	if err != nil {
+		return []error{err}
+	}
+	defer os.RemoveAll(filepath.Dir(f.Name()))
+
+	dir, err := os.MkdirTemp("", "dbc-lint-*")
+	if err != nil {
+		f.Close()
+		return []error{err}
+	}
+	defer os.RemoveAll(dir)
+
+	manifest, err := config.InflateTarball(f, dir)
+	if err != nil {
+		return []error{fmt.Errorf("failed to extract tarball: %w", err)}
+	}
+
+	var errs []error
+	if !manifest.Version.Equal(pkg.Version) {
+		errs = append(errs, fmt.Errorf("MANIFEST version %s does not match the index version %s", manifest.Version, pkg.Version))
+	}
+	// verifySignature looks for the library where installing it for this
+	// platform would have put it.
+	manifest.Driver.Shared.Set(config.PlatformTuple(), filepath.Join(dir, manifest.Files.Driver))
+	if err := verifySignature(manifest, pkg.Driver.Registry, false); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func (m registryLintModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case registryLintDoneMsg:
+		m.packages, m.issues = msg.packages, msg.issues
+		if len(m.issues) > 0 {
+			m.status = 1
+		}
+		return m, tea.Quit
+	case error:
+		m.status, m.err = 1, msg
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m registryLintModel) IsJSONMode() bool { return m.jsonOutput }
+
+func (m registryLintModel) FinalOutput() string {
+	if m.err != nil {
+		if m.jsonOutput {
+			return marshalEnvelope("error", jsonschema.ErrorResponse{
+				Code:    "registry_lint_failed",
+				Message: m.err.Error(),
+			})
+		}
+		return ""
+	}
+
+	if m.jsonOutput {
+		resp := jsonschema.RegistryLintResponse{
+			Registry: m.location,
+			Packages: m.packages,
+			Deep:     m.deep,
+			Issues:   make([]jsonschema.RegistryIssue, 0, len(m.issues)),
+		}
+		for _, i := range m.issues {
+			resp.Issues = append(resp.Issues, jsonschema.RegistryIssue{
+				Driver:   i.Driver,
+				Version:  i.Version,
+				Platform: i.Platform,
+				Message:  i.Message,
+			})
+		}
+		return marshalEnvelope("registry.lint.response", resp)
+	}
+
+	var b strings.Builder
+	for _, i := range m.issues {
+		fmt.Fprintf(&b, "%s %s\n", errStyle.Render("✗"), i)
+	}
+	if len(m.issues) == 0 {
+		fmt.Fprintf(&b, "Checked %d package(s) in %s: no problems found\n", m.packages, m.location)
+	} else {
+		fmt.Fprintf(&b, "Checked %d package(s) in %s: %d problem(s) found\n", m.packages, m.location, len(m.issues))
+	}
+	return b.String()
+}
+
+func (m registryLintModel) View() tea.View { return tea.NewView("") }
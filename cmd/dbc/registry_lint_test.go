@@ -0,0 +1,104 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryLint(t *testing.T) {
+	dir := newRegistryDir(t)
+	m, _ := runRegistryModel(t, RegistryBuildCmd{Dir: dir}.GetModel())
+	require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+
+	for _, deep := range []bool{false, true} {
+		m, out := runRegistryModel(t, RegistryLintCmd{Registry: filepath.Join(dir, "index.yaml"), Deep: deep}.GetModel())
+		require.Zero(t, m.(HasStatus).Status(), "%s", out)
+		assert.Equal(t, "Checked 1 package(s) in "+filepath.Join(dir, "index.yaml")+": no problems found\n", out)
+	}
+}
+
+func TestRegistryLintProblems(t *testing.T) {
+	dir := newRegistryDir(t)
+	for name, src := range map[string]string{
+		"no-sig.tar.gz":  "test-driver-no-sig.tar.gz",
+		"version.tar.gz": "test-driver-1.1.tar.gz",
+	} {
+		data, err := os.ReadFile(filepath.Join("testdata", src))
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkgs"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pkgs", name), data, 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), []byte(`drivers:
+  - name: Test Driver 1
+    path: test-driver-1
+    pkginfo:
+      - version: v1.0.0
+        packages:
+          - platform: linux_amd64
+            url: test-driver-1/1.0.0/test-driver-1_linux_amd64-1.0.0.tar.gz
+      - version: v1.1.0
+        packages:
+          - platform: linux_amd64
+            url: pkgs/no-sig.tar.gz
+      - version: v1.2.0
+        packages:
+          - platform: linux_amd64
+            url: pkgs/version.tar.gz
+          - platform: macos_arm64
+            url: pkgs/missing.tar.gz
+`), 0o644))
+
+	m, out := runRegistryModel(t, RegistryLintCmd{Registry: dir}.GetModel())
+	assert.Equal(t, 1, m.(HasStatus).Status())
+	assert.Equal(t, "✗ test-driver-1 1.2.0 macos_arm64: failed to download file://"+filepath.ToSlash(dir)+"/pkgs/missing.tar.gz: package not found\n"+
+		"Checked 4 package(s) in "+dir+": 1 problem(s) found\n", out)
+
+	m, out = runRegistryModel(t, RegistryLintCmd{Registry: dir, Deep: true, Json: true}.GetModel())
+	assert.Equal(t, 1, m.(HasStatus).Status())
+
+	var env jsonschema.Envelope
+	require.NoError(t, json.Unmarshal([]byte(out), &env))
+	assert.Equal(t, "registry.lint.response", env.Kind)
+	var resp jsonschema.RegistryLintResponse
+	require.NoError(t, json.Unmarshal(env.Payload, &resp))
+	assert.Equal(t, 4, resp.Packages)
+	assert.True(t, resp.Deep)
+	require.Len(t, resp.Issues, 3)
+	assert.Equal(t, "macos_arm64", resp.Issues[0].Platform)
+	assert.Equal(t, jsonschema.RegistryIssue{
+		Driver: "test-driver-1", Version: "1.1.0", Platform: "linux_amd64",
+		Message: "signature file 'test-driver-1-not-valid.so.sig' for driver is missing",
+	}, resp.Issues[1])
+	assert.Equal(t, jsonschema.RegistryIssue{
+		Driver: "test-driver-1", Version: "1.2.0", Platform: "linux_amd64",
+		Message: "MANIFEST version 1.1.0 does not match the index version 1.2.0",
+	}, resp.Issues[2])
+
+	t.Run("missing index", func(t *testing.T) {
+		m, out := runRegistryModel(t, RegistryLintCmd{Registry: t.TempDir(), Json: true}.GetModel())
+		assert.Equal(t, 1, m.(HasStatus).Status())
+		require.NoError(t, json.Unmarshal([]byte(out), &env))
+		assert.Equal(t, "error", env.Kind)
+		assert.Contains(t, string(env.Payload), "registry_lint_failed")
+	})
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+type RegistryServeCmd struct {
+	Dir   string `arg:"positional" default:"." help:"Registry directory containing index.yaml or index.json and driver tarballs"`
+	Addr  string `arg:"--addr" default:"127.0.0.1:8080" help:"Address to listen on"`
+	Token string `arg:"--token,env:REGISTRY_TOKEN" help:"Require this bearer token on every request (can also be set via DBC_REGISTRY_TOKEN)"`
+}
+
+func (c RegistryServeCmd) GetModel() tea.Model {
+	return registryServeModel{
+		dir:    c.Dir,
+		addr:   c.Addr,
+		token:  c.Token,
+		logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+}
+
+type registryServeModel struct {
+	dir    string
+	addr   string
+	token  string
+	logger *log.Logger
+
+	status int
+	err    error
+}
+
+func (m registryServeModel) Status() int { return m.status }
+func (m registryServeModel) Err() error  { return m.err }
+
+func (m registryServeModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		handler, err := newRegistryHandler(m.dir, m.token, m.logger)
+		if err != nil {
+			return err
+		}
+
+		l, err := net.Listen("tcp", m.addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", m.addr, err)
+		}
+
+		m.logger.Printf("Serving registry %s at http://%s", m.dir, l.Addr())
+		if m.token != "" {
+			m.logger.Printf("Requests require a bearer token; log in with: dbc auth login http://%s --api-key <token>", l.Addr())
+		}
+
+		srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("registry server failed: %w", err)
+		}
+		return tea.QuitMsg{}
+	}
+}
+
+func (m registryServeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+	case error:
+		m.status, m.err = 1, msg
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m registryServeModel) View() tea.View { return tea.NewView("") }
+
+// newRegistryHandler returns a handler that serves the files under dir the
+// way dbc expects to find a registry: index.yaml or index.json at the root
+// and packages at the URLs it lists. Directory listings are not served. If token is set,
+// every request must carry it as a bearer token, and GET /login exchanges it
+// for an access token as the API key login flow expects. Each request is
+// logged to logger.
+func newRegistryHandler(dir, token string, logger *log.Logger) (http.Handler, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry directory: %w", err)
+	}
+	fsys := root.FS()
+	if _, err := fs.Stat(fsys, "index.yaml"); err != nil {
+		if _, jsonErr := fs.Stat(fsys, "index.json"); jsonErr != nil {
+			return nil, fmt.Errorf("%s is not a registry: %w", dir, err)
+		}
+	}
+
+	files := http.FileServerFS(fsys)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"access_token": token})
+	})
+	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" {
+			name = "."
+		}
+		if fi, err := fs.Stat(fsys, name); err != nil || fi.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		if path.Ext(name) == ".yaml" {
+			w.Header().Set("Content-Type", "application/yaml")
+		}
+		files.ServeHTTP(w, r)
+	})
+
+	var h http.Handler = mux
+	if token != "" {
+		h = requireBearerToken(token, h)
+	}
+	return logRequests(logger, h), nil
+}
+
+// requireBearerToken rejects requests whose Authorization header does not
+// carry token.
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="dbc"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loggingResponseWriter records the status code and body size of a response.
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *loggingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *loggingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *loggingResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := io.Copy(w.ResponseWriter, r)
+	w.size += n
+	return n, err
+}
+
+// logRequests logs one line per request. The query string is left out since
+// dbc uses it to send client identifiers.
+func logRequests(logger *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		lw := &loggingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(lw, r)
+		if lw.status == 0 {
+			lw.status = http.StatusOK
+		}
+		logger.Printf("%s %s %s %d %dB %s", r.RemoteAddr, r.Method, r.URL.Path,
+			lw.status, lw.size, time.Since(start).Round(time.Millisecond))
+	})
+}
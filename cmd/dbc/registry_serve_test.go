@@ -0,0 +1,165 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use, since the
+// server logs a request after its response has been sent.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// assertLogged waits for the server to log a line containing s.
+func assertLogged(t *testing.T, logs *syncBuffer, s string) {
+	t.Helper()
+	assert.Eventually(t, func() bool { return strings.Contains(logs.String(), s) },
+		5*time.Second, 10*time.Millisecond, "%q was not logged", s)
+}
+
+// newServedRegistry builds the index for a registry directory and serves it
+// with the given token. Request logs are written to the returned buffer.
+func newServedRegistry(t *testing.T, token string) (*httptest.Server, *syncBuffer) {
+	t.Helper()
+	dir := newRegistryDir(t)
+	index, _, err := dbc.BuildIndex(dir, dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), index, 0o644))
+
+	logs := &syncBuffer{}
+	h, err := newRegistryHandler(dir, token, log.New(logs, "", 0))
+	require.NoError(t, err)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv, logs
+}
+
+func TestRegistryServe(t *testing.T) {
+	srv, logs := newServedRegistry(t, "")
+
+	c, err := dbc.NewClient(dbc.WithBaseURL(srv.URL), dbc.WithIndexCacheDir(""), dbc.WithPackageCacheDir(""))
+	require.NoError(t, err)
+
+	drivers, err := c.Search(t.Context(), "test-driver-1")
+	require.NoError(t, err)
+	require.Len(t, drivers, 1)
+	pkg, err := drivers[0].GetPackage(nil, "linux_amd64", false)
+	require.NoError(t, err)
+
+	body, err := c.Download(t.Context(), pkg)
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	body.Close()
+	require.NoError(t, err)
+	want, err := os.ReadFile(filepath.Join("testdata", "test-driver-1.tar.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, want, data)
+
+	assertLogged(t, logs, "GET /index.yaml 200")
+	assertLogged(t, logs, "GET /test-driver-1/1.0.0/test-driver-1_linux_amd64-1.0.0.tar.gz 200")
+	assert.NotContains(t, logs.String(), "mid=", "query strings are not logged")
+
+	for _, p := range []string{"/", "/test-driver-1/", "/missing.tar.gz"} {
+		rsp, err := http.Get(srv.URL + p)
+		require.NoError(t, err)
+		rsp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, rsp.StatusCode, p)
+	}
+
+	rsp, err := http.Post(srv.URL+"/index.yaml", "text/plain", nil)
+	require.NoError(t, err)
+	rsp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, rsp.StatusCode)
+}
+
+func TestRegistryServeToken(t *testing.T) {
+	const token = "s3cret"
+	srv, logs := newServedRegistry(t, token)
+
+	rsp, err := http.Get(srv.URL + "/index.yaml")
+	require.NoError(t, err)
+	rsp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+	assert.Equal(t, `Bearer realm="dbc"`, rsp.Header.Get("WWW-Authenticate"))
+	assertLogged(t, logs, "GET /index.yaml 401")
+
+	credPath := filepath.Join(t.TempDir(), "credentials.toml")
+	restore := auth.SetCredPathForTesting(credPath)
+	defer restore()
+	auth.ResetCredentialsForTesting()
+	defer auth.ResetCredentialsForTesting()
+
+	t.Run("wrong api key", func(t *testing.T) {
+		m, _ := runRegistryModel(t, LoginCmd{RegistryURL: srv.URL, ApiKey: "wrong"}.GetModel())
+		assert.Equal(t, 1, m.(HasStatus).Status())
+		assert.ErrorContains(t, m.(HasStatus).Err(), "401")
+	})
+
+	m, _ := runRegistryModel(t, LoginCmd{RegistryURL: srv.URL, ApiKey: token}.GetModel())
+	require.Zero(t, m.(HasStatus).Status(), "%v", m.(HasStatus).Err())
+	assert.FileExists(t, credPath)
+
+	c, err := dbc.NewClient(dbc.WithBaseURL(srv.URL), dbc.WithAuthFromFilesystem(), dbc.WithIndexCacheDir(""))
+	require.NoError(t, err)
+	drivers, err := c.Search(t.Context(), "test-driver-1")
+	require.NoError(t, err)
+	assert.Len(t, drivers, 1)
+}
+
+func TestRegistryServeErrors(t *testing.T) {
+	t.Run("missing directory", func(t *testing.T) {
+		cmd := RegistryServeCmd{Dir: filepath.Join(t.TempDir(), "missing"), Addr: "127.0.0.1:0"}
+		m, _ := runRegistryModel(t, cmd.GetModel())
+		assert.Equal(t, 1, m.(HasStatus).Status())
+		assert.ErrorContains(t, m.(HasStatus).Err(), "failed to open registry directory")
+	})
+
+	t.Run("no index", func(t *testing.T) {
+		cmd := RegistryServeCmd{Dir: t.TempDir(), Addr: "127.0.0.1:0"}
+		m, _ := runRegistryModel(t, cmd.GetModel())
+		assert.Equal(t, 1, m.(HasStatus).Status())
+		assert.ErrorContains(t, m.(HasStatus).Err(), "is not a registry")
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -31,6 +32,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/auth"
 	"github.com/columnar-tech/dbc/config"
 	"github.com/columnar-tech/dbc/internal/fslock"
 	"github.com/stretchr/testify/assert"
@@ -389,10 +391,48 @@ func TestAuthHTTPClientDoesNotRequireRegistryConfig(t *testing.T) {
 	globalRegistryConfig = &dbc.GlobalConfig{ReplaceDefaults: true}
 
 	// authHTTPClient must still return a usable client.
-	c := authHTTPClient()
+	c := authHTTPClient(must(url.Parse("https://registry.example.com")))
 	require.NotNil(t, c)
 }
 
+// TestAuthHTTPClientAppliesNetworkConfig checks that login and license
+// requests go through the proxy configured for the registry being logged
+// into, overriding the global one.
+func TestAuthHTTPClientAppliesNetworkConfig(t *testing.T) {
+	var proxied []string
+	newProxy := func(name string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = append(proxied, name+" "+r.URL.String())
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	global, private := newProxy("global"), newProxy("private")
+
+	savedGlobal := globalRegistryConfig
+	t.Cleanup(func() { globalRegistryConfig = savedGlobal })
+	globalRegistryConfig = &dbc.GlobalConfig{
+		Registries: []dbc.RegistryEntry{{
+			URL:     "http://private.example.com",
+			Network: dbc.NetworkConfig{Proxy: private.URL},
+		}},
+		Network: dbc.NetworkConfig{Proxy: global.URL},
+	}
+
+	for _, u := range []string{"http://private.example.com", "http://public.example.com"} {
+		req, err := http.NewRequestWithContext(authContext(must(url.Parse(u))), http.MethodGet, u+"/login", nil)
+		require.NoError(t, err)
+		resp, err := auth.HTTPClient(req.Context()).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, []string{
+		"private http://private.example.com/login",
+		"global http://public.example.com/login",
+	}, proxied)
+}
+
 // TestGetDriverListHonorsProjectRegistries proves GetDriverList — a helper
 // used by library consumers that parse dbc.toml directly — honors the
 // project's [[registries]] section when resolving driver packages, AND
@@ -473,6 +513,36 @@ func TestGetDriverListHonorsProjectRegistries(t *testing.T) {
 		"GetDriverList must not leak registry state from a previous call")
 }
 
+// TestGetDriverListRelativeRegistryPath proves a relative registry path in
+// dbc.toml is resolved against the dbc.toml's directory, not the working
+// directory, so a registry vendored next to the project works from anywhere.
+func TestGetDriverListRelativeRegistryPath(t *testing.T) {
+	t.Setenv("DBC_BASE_URL", "")
+	savedGlobal := globalRegistryConfig
+	t.Cleanup(func() { globalRegistryConfig = savedGlobal })
+	globalRegistryConfig = nil
+
+	project := t.TempDir()
+	registry := filepath.Join(project, "vendor", "registry")
+	require.NoError(t, os.MkdirAll(registry, 0o755))
+	index := "drivers:\n  - name: vendored\n    path: vendored\n    pkginfo:\n" +
+		"      - version: v1.0.0\n        packages:\n" +
+		"          - platform: " + config.PlatformTuple() + "\n            url: pkgs/vendored.tar.gz\n"
+	require.NoError(t, os.WriteFile(filepath.Join(registry, "index.yaml"), []byte(index), 0o644))
+
+	listPath := filepath.Join(project, "dbc.toml")
+	require.NoError(t, os.WriteFile(listPath, []byte("replace_defaults = true\n\n"+
+		"[[registries]]\nurl = './vendor/registry'\n\n[drivers]\n[drivers.vendored]\nversion = '>=1.0.0'\n"), 0o644))
+	t.Chdir(t.TempDir())
+
+	pkgs, err := GetDriverList(listPath)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	assert.Equal(t, "file", pkgs[0].Path.Scheme)
+	assert.True(t, strings.HasSuffix(pkgs[0].Path.Path,
+		filepath.ToSlash(filepath.Join(registry, "pkgs", "vendored.tar.gz"))), pkgs[0].Path.String())
+}
+
 // TestStartupEndToEndGlobalReplaceDefaultsWithProjectEntries runs the full
 // CLI startup sequence (loadStartupRegistryConfig + project-command dispatch
 // via applyProjectRegistries) against a temp global config.toml declaring
@@ -1568,9 +1638,9 @@ func TestInfoCmdHonorsProjectRegistries(t *testing.T) {
 	})
 
 	msg := m.Init()()
-	drv, ok := msg.(dbc.Driver)
+	info, ok := msg.(driverInfoMsg)
 	require.True(t, ok, "expected info to resolve the project driver, got %T: %v", msg, msg)
-	assert.Equal(t, "project-only-driver", drv.Path)
+	assert.Equal(t, "project-only-driver", info.drv.Path)
 	assert.GreaterOrEqual(t, atomic.LoadInt32(hits), int32(1),
 		"dbc info should have queried the project-declared registry")
 }
@@ -0,0 +1,318 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
+)
+
+// resolver picks the version of each driver in a driver list to install and
+// lock, and the packages of that version for every platform the list
+// targets. It doesn't install anything.
+type resolver struct {
+	// index is the driver registry index, and registryErrors the errors from
+	// any registries that couldn't be read.
+	index          []dbc.Driver
+	registryErrors error
+	// locked is the current lockfile, whose versions are kept as long as the
+	// driver list allows them.
+	locked LockFile
+	// upgrading reports whether the locked version of the driver name is
+	// ignored in favour of the newest version the driver list allows. A nil
+	// upgrading keeps every locked version.
+	upgrading func(name string) bool
+}
+
+// resolve returns the package to install for each driver in list, sorted by
+// driver.
+func (r resolver) resolve(list DriversList) ([]installItem, error) {
+	platforms := list.targetPlatforms()
+	// Without explicit platforms, packages locked for other platforms by
+	// whoever else syncs the list are kept rather than dropped.
+	keepLocked := len(list.Platforms) == 0
+	items := make([]installItem, 0, len(list.Drivers))
+	for name, spec := range list.Drivers {
+		item, err := r.resolveDriver(name, spec, platforms, keepLocked)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	slices.SortFunc(items, func(a, b installItem) int { return strings.Compare(a.Driver.Path, b.Driver.Path) })
+	return items, nil
+}
+
+func (r resolver) resolveDriver(name string, spec driverSpec, platforms []string, keepLocked bool) (installItem, error) {
+	var info lockInfo
+	if r.locked.lockinfo != nil {
+		info = r.locked.lockinfo[name]
+	}
+
+	// locate the driver info in the CDN driver registry index, preferring
+	// the registry it was locked from unless the driver list pins one
+	registry := spec.Registry
+	if registry == "" && info.Registry != "" {
+		if _, err := findRegistryDriver(info.Registry, name, r.index); err == nil {
+			registry = info.Registry
+		}
+	}
+	drv, err := findRegistryDriver(registry, name, r.index)
+	if err != nil {
+		return installItem{}, wrapWithRegistryContext(err, r.registryErrors)
+	}
+	if drv, err = loadDriver(drv); err != nil {
+		return installItem{}, err
+	}
+	// a version locked from another registry says nothing about this one
+	if info.Registry != "" && info.Registry != registryOrigin(drv.Registry) {
+		info = lockInfo{}
+	}
+
+	upgrading := r.upgrading != nil && r.upgrading(name)
+
+	// if the lockfile specified a version and either the driver list doesn't
+	// specify a version constraint or the version in the locked file is valid
+	// for that constraint, then we want to install the version in the lockfile
+	version, allowPre := info.Version, spec.Prerelease == "allow"
+	if info.Version == nil || upgrading || (spec.Version != nil && !spec.Version.Check(info.Version)) {
+		// no locked version or driver list version doesn't match locked file
+		if version, err = newestVersion(drv, spec, platforms); err != nil {
+			return installItem{}, err
+		}
+		allowPre = true
+	}
+
+	// The package for this platform is the one sync installs. A driver list
+	// that doesn't target this platform can still be locked here, so its
+	// first platform stands in.
+	pkg, err := drv.GetPackage(version, config.PlatformTuple(), allowPre)
+	if err != nil && !slices.Contains(platforms, config.PlatformTuple()) {
+		pkg, err = drv.GetPackage(version, platforms[0], allowPre)
+	}
+	if err != nil {
+		return installItem{}, err
+	}
+
+	packages, err := lockPackages(drv, pkg, info, platforms, keepLocked)
+	if err != nil {
+		return installItem{}, err
+	}
+	item := installItem{
+		Driver:   drv,
+		Package:  pkg,
+		Packages: packages,
+	}
+	item.Checksum = item.locked().Checksum
+	return item, nil
+}
+
+// newestVersion returns the newest version of drv that spec allows, that
+// isn't yanked, and that has a package for every one of platforms.
+func newestVersion(drv dbc.Driver, spec driverSpec, platforms []string) (*semver.Version, error) {
+	allowPre := spec.Prerelease == "allow"
+	var constraint *semver.Constraints
+	if spec.Version != nil {
+		c := *spec.Version
+		c.IncludePrerelease = c.IncludePrerelease || allowPre
+		constraint = &c
+	}
+
+	var candidates []dbc.VersionInfo
+	for _, v := range drv.AllVersions() {
+		switch {
+		case v.Yanked:
+		case constraint != nil && !constraint.Check(v.Version):
+		case constraint == nil && !allowPre && v.Version.Prerelease() != "":
+		default:
+			candidates = append(candidates, v)
+		}
+	}
+	if len(candidates) == 0 {
+		// Let the registry explain why nothing matched, e.g. because every
+		// matching version has been yanked.
+		var err error
+		if constraint != nil {
+			_, err = drv.GetWithConstraint(constraint, platforms[0])
+		} else {
+			_, err = drv.GetPackage(nil, platforms[0], allowPre)
+		}
+		return nil, cmp.Or(err, fmt.Errorf("no version of driver %s matches the driver list", drv.Path))
+	}
+
+	hasPackage := func(v dbc.VersionInfo, platform string) bool {
+		return slices.ContainsFunc(v.Packages, func(p dbc.PackageInfo) bool { return p.Platform == platform })
+	}
+	var newest *semver.Version
+	for _, v := range candidates {
+		all := !slices.ContainsFunc(platforms, func(p string) bool { return !hasPackage(v, p) })
+		if all && (newest == nil || v.Version.GreaterThan(newest)) {
+			newest = v.Version
+		}
+	}
+	if newest != nil {
+		return newest, nil
+	}
+
+	for _, platform := range platforms {
+		if !slices.ContainsFunc(candidates, func(v dbc.VersionInfo) bool { return hasPackage(v, platform) }) {
+			return nil, fmt.Errorf("no version of driver %s that the driver list allows has a package for platform %s", drv.Path, platform)
+		}
+	}
+	return nil, fmt.Errorf("no version of driver %s that the driver list allows has a package for every platform in %s",
+		drv.Path, strings.Join(platforms, ", "))
+}
+
+// lockedItems returns the package to install for exactly the drivers and
+// versions in the lockfile, from the registries they were locked from. The
+// driver list isn't consulted.
+func (r resolver) lockedItems() ([]installItem, error) {
+	if len(r.locked.Drivers) == 0 {
+		return nil, errors.New("no drivers found in the lockfile")
+	}
+
+	items := make([]installItem, 0, len(r.locked.Drivers))
+	for _, info := range r.locked.Drivers {
+		if info.Version == nil {
+			return nil, fmt.Errorf("driver %s has no version in the lockfile", info.Name)
+		}
+		drv, err := findRegistryDriver(info.Registry, info.Name, r.index)
+		if err != nil {
+			return nil, wrapWithRegistryContext(err, r.registryErrors)
+		}
+		if drv, err = loadDriver(drv); err != nil {
+			return nil, err
+		}
+		pkg, err := drv.GetPackage(info.Version, config.PlatformTuple(), true)
+		if err != nil {
+			return nil, err
+		}
+
+		item := installItem{
+			Driver:   drv,
+			Package:  pkg,
+			Packages: info.Packages,
+		}
+		item.Checksum = item.locked().Checksum
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// checkLocked returns an error if the lockfile lf is out of date for list:
+// if it's missing a driver in the list or has one the list doesn't, if it
+// locks a version the list's constraint doesn't allow or no package for one
+// of the list's platforms, or if items, resolved from list, don't match what
+// it locks.
+func checkLocked(list DriversList, lf LockFile, items []installItem) error {
+	for _, name := range slices.Sorted(maps.Keys(list.Drivers)) {
+		info, ok := lf.lockinfo[name]
+		if !ok || info.Version == nil {
+			return fmt.Errorf("driver %s is in the driver list but not in the lockfile", name)
+		}
+		spec := list.Drivers[name]
+		if spec.Version == nil {
+			continue
+		}
+		constraint := *spec.Version
+		constraint.IncludePrerelease = spec.Prerelease == "allow"
+		if !constraint.Check(info.Version) {
+			return fmt.Errorf("locked version %s of driver %s does not satisfy the constraint %s in the driver list",
+				info.Version, name, spec.Version)
+		}
+	}
+
+	for _, info := range lf.Drivers {
+		if _, ok := list.Drivers[info.Name]; !ok {
+			return fmt.Errorf("driver %s is in the lockfile but not in the driver list", info.Name)
+		}
+	}
+
+	platforms := list.targetPlatforms()
+	for _, item := range items {
+		info := lf.lockinfo[item.Driver.Path]
+		for _, platform := range platforms {
+			if _, ok := info.pkg(platform); !ok {
+				return fmt.Errorf("driver %s has no package for platform %s in the lockfile", item.Driver.Path, platform)
+			}
+		}
+		if !item.Package.Version.Equal(info.Version) {
+			return fmt.Errorf("driver %s resolves to version %s, but the lockfile has %s",
+				item.Driver.Path, item.Package.Version, info.Version)
+		}
+		if origin := registryOrigin(item.Driver.Registry); info.Registry != "" && origin != info.Registry {
+			return fmt.Errorf("driver %s resolves to registry %s, but the lockfile has %s",
+				item.Driver.Path, origin, info.Registry)
+		}
+	}
+	return nil
+}
+
+// lockPackages resolves the packages of pkg's version for platforms. Digests
+// and checksums locked in info for that version are kept, and a digest that
+// no longer matches the one the registry lists is an error. If keepLocked,
+// the packages info locks for that version on other platforms are kept too.
+func lockPackages(drv dbc.Driver, pkg dbc.PkgInfo, info lockInfo, platforms []string, keepLocked bool) ([]lockPackage, error) {
+	sameVersion := info.Version != nil && pkg.Version.Equal(info.Version)
+	packages := make([]lockPackage, 0, len(platforms))
+	for _, platform := range platforms {
+		p := pkg
+		if platform != pkg.PlatformTuple {
+			var err error
+			if p, err = drv.GetPackage(pkg.Version, platform, true); err != nil {
+				return nil, fmt.Errorf("cannot lock %s %s for platform %s: %w", drv.Path, pkg.Version, platform, err)
+			}
+		}
+
+		entry := lockPackage{
+			Platform: platform,
+			URL:      stripUserinfo(p.Path.String()),
+			SHA256:   strings.ToLower(p.SHA256),
+		}
+		if prev, ok := info.pkg(platform); ok && sameVersion {
+			if prev.SHA256 != "" && entry.SHA256 != "" && prev.SHA256 != entry.SHA256 {
+				return nil, fmt.Errorf("package of %s %s for platform %s does not match the lockfile: locked sha256 %s, registry lists %s",
+					drv.Path, pkg.Version, platform, prev.SHA256, entry.SHA256)
+			}
+			entry.SHA256 = cmp.Or(entry.SHA256, prev.SHA256)
+			entry.Checksum = prev.Checksum
+		}
+		packages = append(packages, entry)
+	}
+
+	if keepLocked && sameVersion {
+		for _, prev := range info.Packages {
+			if !slices.Contains(platforms, prev.Platform) {
+				packages = append(packages, prev)
+			}
+		}
+		slices.SortFunc(packages, func(a, b lockPackage) int { return strings.Compare(a.Platform, b.Platform) })
+	}
+	return packages, nil
+}
+
+// upgrading reports whether the locked version of the driver name is ignored
+// in favour of the newest version the driver list allows.
+func (s syncModel) upgrading(name string) bool {
+	return s.upgradeAll || slices.Contains(s.upgrade, name)
+}
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
@@ -35,6 +36,7 @@ var (
 	descStyle     = lipgloss.NewStyle().Italic(true)
 	bold          = lipgloss.NewStyle().Bold(true)
 	registryStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63"))
+	warningStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
 )
 
 type SearchCmd struct {
@@ -66,12 +68,14 @@ type searchModel struct {
 	pre            bool
 	pattern        *regexp.Regexp
 	finalDrivers   []dbc.Driver
+	conflicts      []dbc.DriverConflict
 	registryErrors error // Store registry errors to display as warnings
 }
 
 type driversWithErrorMsg struct {
-	drivers []dbc.Driver
-	err     error
+	drivers   []dbc.Driver
+	conflicts []dbc.DriverConflict
+	err       error
 }
 
 func (m searchModel) Init() tea.Cmd {
@@ -80,11 +84,13 @@ func (m searchModel) Init() tea.Cmd {
 			return err
 		}
 		drivers, err := m.getDriverRegistry()
+		filtered := m.filterDrivers(drivers)
 		// Don't fail completely if we have some drivers - return them with the error
 		// This allows graceful degradation when some registries fail
 		return driversWithErrorMsg{
-			drivers: m.filterDrivers(drivers),
-			err:     err,
+			drivers:   filtered,
+			conflicts: matchedConflicts(dbc.FindConflicts(drivers), filtered),
+			err:       err,
 		}
 	}
 }
@@ -107,6 +113,7 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case driversWithErrorMsg:
 		m.finalDrivers = msg.drivers
+		m.conflicts = msg.conflicts
 		m.registryErrors = msg.err
 		// If we have no drivers and there's an error, fail the command
 		if len(msg.drivers) == 0 && msg.err != nil {
@@ -132,7 +139,95 @@ func emptyEnumerator(_ list.Items, _ int) string {
 	return ""
 }
 
-func viewDrivers(d []dbc.Driver, verbose bool, allowPre bool) string {
+// matchedConflicts returns the conflicts, found across all drivers, for
+// paths among the matched drivers. They are detected before filtering so a
+// source whose description doesn't match the pattern is still reported.
+func matchedConflicts(conflicts []dbc.DriverConflict, matched []dbc.Driver) []dbc.DriverConflict {
+	var out []dbc.DriverConflict
+	for _, c := range conflicts {
+		if slices.ContainsFunc(matched, func(d dbc.Driver) bool { return d.Path == c.Path }) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func driverSources(c dbc.DriverConflict, allowPre bool) []jsonschema.DriverSource {
+	sources := make([]jsonschema.DriverSource, 0, len(c.Sources))
+	for i, d := range c.Sources {
+		versions := []string{}
+		for _, v := range d.Versions(config.PlatformTuple()) {
+			if _, yanked := d.Yanked(v); yanked || (v.Prerelease() != "" && !allowPre) {
+				continue
+			}
+			versions = append(versions, v.String())
+		}
+		sources = append(sources, jsonschema.DriverSource{
+			Registry: d.Registry.Name,
+			URL:      registryOrigin(d.Registry),
+			Versions: versions,
+			Selected: i == 0,
+		})
+	}
+	return sources
+}
+
+func driverConflictsJSON(conflicts []dbc.DriverConflict, allowPre bool) []jsonschema.DriverConflict {
+	var out []jsonschema.DriverConflict
+	for _, c := range conflicts {
+		out = append(out, jsonschema.DriverConflict{Driver: c.Path, Sources: driverSources(c, allowPre)})
+	}
+	return out
+}
+
+// formatConflict describes every registry publishing the driver of c, with
+// its available versions, and which one an unqualified name resolves to.
+func formatConflict(c dbc.DriverConflict, allowPre bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s is published by %d registries; `dbc install %s` uses the first:",
+		nameStyle.Render(c.Path), len(c.Sources), c.Path)
+	for i, src := range driverSources(c, allowPre) {
+		label := src.URL
+		if src.Registry != "" {
+			label = src.Registry + " (" + src.URL + ")"
+		}
+		versions := strings.Join(src.Versions, ", ")
+		if versions == "" {
+			versions = "no versions for this platform"
+		}
+		fmt.Fprintf(&b, "\n  %d. %s: %s", i+1, registryStyle.Render(label), versions)
+	}
+	return b.String()
+}
+
+// searchWarnings returns the warnings for the listed drivers, skipping those
+// hidden because they only have pre-releases.
+func searchWarnings(drivers []dbc.Driver, allowPre bool) []jsonschema.DriverWarning {
+	current := config.Get()
+	var out []jsonschema.DriverWarning
+	for _, d := range drivers {
+		if installed, _ := getInstalled(d, current); !allowPre && !d.HasNonPrerelease() && len(installed) == 0 {
+			continue
+		}
+		for _, w := range installedWarnings(d, current) {
+			if !slices.Contains(out, w) {
+				out = append(out, w)
+			}
+		}
+	}
+	return out
+}
+
+func viewConflicts(conflicts []dbc.DriverConflict, allowPre bool) string {
+	var b strings.Builder
+	for _, c := range conflicts {
+		b.WriteString("\n" + formatConflict(c, allowPre) + "\n")
+	}
+	b.WriteString(msgStyle.Render("To choose a registry, use `dbc install <registry>/<driver>` or set `registry` in dbc.toml."))
+	return b.String()
+}
+
+func viewDrivers(d []dbc.Driver, conflicts []dbc.DriverConflict, verbose bool, allowPre bool) string {
 	if len(d) == 0 {
 		return ""
 	}
@@ -140,13 +235,16 @@ func viewDrivers(d []dbc.Driver, verbose bool, allowPre bool) string {
 	current := config.Get()
 	installedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 
-	hasRegistryTags := false
+	hasRegistryTags := len(conflicts) > 0
 	for _, driver := range d {
 		if driver.Registry.Name != "" {
 			hasRegistryTags = true
 			break
 		}
 	}
+	isConflict := func(driver dbc.Driver) bool {
+		return slices.ContainsFunc(conflicts, func(c dbc.DriverConflict) bool { return c.Path == driver.Path })
+	}
 
 	l := list.New()
 	t := table.New().Border(lipgloss.HiddenBorder()).
@@ -168,6 +266,8 @@ func viewDrivers(d []dbc.Driver, verbose bool, allowPre bool) string {
 				name = name[:7] + "..."
 			}
 			regTag = registryStyle.Render("[" + name + "]")
+		} else if isConflict(driver) && driver.Registry.BaseURL != nil {
+			regTag = registryStyle.Render("[" + driver.Registry.BaseURL.Host + "]")
 		}
 
 		if !verbose {
@@ -198,7 +298,7 @@ func viewDrivers(d []dbc.Driver, verbose bool, allowPre bool) string {
 		versionTree := tree.Root(bold.Render("Available Versions:")).
 			Enumerator(tree.RoundedEnumerator)
 		for _, v := range driver.Versions(config.PlatformTuple()) {
-			if v.Prerelease() != "" && !allowPre {
+			if _, yanked := driver.Yanked(v); yanked || (v.Prerelease() != "" && !allowPre) {
 				continue
 			}
 
@@ -223,7 +323,7 @@ func viewDrivers(d []dbc.Driver, verbose bool, allowPre bool) string {
 	return l.String()
 }
 
-func viewDriversJSON(d []dbc.Driver, verbose bool, allowPre bool, registryErrors error) string {
+func viewDriversJSON(d []dbc.Driver, conflicts []dbc.DriverConflict, verbose bool, allowPre bool, registryErrors error) string {
 	current := config.Get()
 
 	if !verbose {
@@ -243,11 +343,17 @@ func viewDriversJSON(d []dbc.Driver, verbose bool, allowPre bool, registryErrors
 		}
 
 		type basicResult struct {
-			Drivers []jsonschema.SearchDriverBasic `json:"drivers"`
-			Warning string                          `json:"warning,omitempty"`
+			Drivers   []jsonschema.SearchDriverBasic `json:"drivers"`
+			Warning   string                         `json:"warning,omitempty"`
+			Conflicts []jsonschema.DriverConflict    `json:"conflicts,omitempty"`
+			Warnings  []jsonschema.DriverWarning     `json:"warnings,omitempty"`
 		}
 
-		res := basicResult{Drivers: driverList}
+		res := basicResult{
+			Drivers:   driverList,
+			Conflicts: driverConflictsJSON(conflicts, allowPre),
+			Warnings:  searchWarnings(d, allowPre),
+		}
 		if registryErrors != nil && len(d) > 0 {
 			res.Warning = registryErrors.Error()
 		}
@@ -274,7 +380,7 @@ func viewDriversJSON(d []dbc.Driver, verbose bool, allowPre bool, registryErrors
 
 		var availableVersions []string
 		for _, v := range driver.Versions(config.PlatformTuple()) {
-			if v.Prerelease() != "" && !allowPre {
+			if _, yanked := driver.Yanked(v); yanked || (v.Prerelease() != "" && !allowPre) {
 				continue
 			}
 
@@ -292,11 +398,17 @@ func viewDriversJSON(d []dbc.Driver, verbose bool, allowPre bool, registryErrors
 	}
 
 	type verboseResult struct {
-		Drivers []jsonschema.SearchDriverVerbose `json:"drivers"`
-		Warning string                            `json:"warning,omitempty"`
+		Drivers   []jsonschema.SearchDriverVerbose `json:"drivers"`
+		Warning   string                           `json:"warning,omitempty"`
+		Conflicts []jsonschema.DriverConflict      `json:"conflicts,omitempty"`
+		Warnings  []jsonschema.DriverWarning       `json:"warnings,omitempty"`
 	}
 
-	res := verboseResult{Drivers: driverList}
+	res := verboseResult{
+		Drivers:   driverList,
+		Conflicts: driverConflictsJSON(conflicts, allowPre),
+		Warnings:  searchWarnings(d, allowPre),
+	}
 	if registryErrors != nil && len(d) > 0 {
 		res.Warning = registryErrors.Error()
 	}
@@ -348,9 +460,9 @@ func (m searchModel) FinalOutput() string {
 
 	// Display driver list first
 	if m.outputJson {
-		output = viewDriversJSON(m.finalDrivers, m.verbose, m.pre, m.registryErrors)
+		output = viewDriversJSON(m.finalDrivers, m.conflicts, m.verbose, m.pre, m.registryErrors)
 	} else {
-		output = viewDrivers(m.finalDrivers, m.verbose, m.pre)
+		output = viewDrivers(m.finalDrivers, m.conflicts, m.verbose, m.pre)
 		if output == "" {
 			// Some matched drivers exist but all are pre-release and --pre wasn't passed.
 			if !m.pre && len(m.finalDrivers) > 0 {
@@ -369,10 +481,21 @@ func (m searchModel) FinalOutput() string {
 		}
 	}
 
+	if !m.outputJson && len(m.conflicts) > 0 {
+		output += "\n" + viewConflicts(m.conflicts, m.pre)
+	}
+	if !m.outputJson {
+		if warnings := searchWarnings(m.finalDrivers, m.pre); len(warnings) > 0 {
+			output += "\n"
+			for _, w := range warnings {
+				output += "\n" + formatWarning(w)
+			}
+		}
+	}
+
 	// Display warning about registry errors after the driver list (only if we have some drivers to show)
 	// If we have no drivers, the error is returned via the error mechanism
 	if !m.outputJson && m.registryErrors != nil && len(m.finalDrivers) > 0 {
-		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
 		output += "\n" + warningStyle.Render("Warning: ") + "Some driver registries were unavailable:\n"
 		output += m.registryErrors.Error()
 	}
@@ -462,3 +462,76 @@ func (suite *SubcommandTestSuite) TestSearchOnlyPreReleaseDriversFound() {
 	out := suite.runCmd(m)
 	suite.Equal("Only one or more pre-release drivers matched your pattern `only-pre`. To include them, use: dbc search --pre only-pre", out)
 }
+
+func (suite *SubcommandTestSuite) TestSearchCmdConflicts() {
+	multiRegistry := baseModel{getDriverRegistry: getMultiTestDriverRegistry, downloadPkg: downloadTestPkg}
+
+	m := SearchCmd{Pattern: regexp.MustCompile("test-driver-[12]")}.GetModelCustom(multiRegistry)
+	suite.validateOutput("\r ",
+		"test-driver-1 [registry.columnar.tech] This is a test driver       \n"+
+			"test-driver-2                          This is another test driver \n"+
+			"test-driver-1 [acme]                   This is a test driver       \n"+
+			"\n"+
+			"test-driver-1 is published by 2 registries; `dbc install test-driver-1` uses the first:\n"+
+			"  1. https://registry.columnar.tech: 1.0.0, 1.1.0\n"+
+			"  2. acme (https://acme.example.com/drivers): 1.0.0\n"+
+			"To choose a registry, use `dbc install <registry>/<driver>` or set `registry` in dbc.toml.", suite.runCmd(m))
+
+	m = SearchCmd{Pattern: regexp.MustCompile("test-driver-2")}.GetModelCustom(multiRegistry)
+	suite.NotContains(suite.runCmd(m), "published by")
+}
+
+func (suite *SubcommandTestSuite) TestSearchCmdConflictsJSON() {
+	multiRegistry := baseModel{getDriverRegistry: getMultiTestDriverRegistry, downloadPkg: downloadTestPkg}
+	want := []jsonschema.DriverConflict{{
+		Driver: "test-driver-1",
+		Sources: []jsonschema.DriverSource{
+			{URL: "https://registry.columnar.tech", Versions: []string{"1.0.0", "1.1.0"}, Selected: true},
+			{Registry: "acme", URL: "https://acme.example.com/drivers", Versions: []string{"1.0.0"}},
+		},
+	}}
+
+	for _, verbose := range []bool{false, true} {
+		m := SearchCmd{Json: true, Verbose: verbose}.GetModelCustom(multiRegistry)
+		out := suite.runCmd(m)
+
+		var env jsonschema.Envelope
+		suite.Require().NoError(json.Unmarshal([]byte(out), &env), "output must be valid JSON: %s", out)
+		var resp jsonschema.SearchResponse
+		suite.Require().NoError(json.Unmarshal(env.Payload, &resp))
+		suite.Equal(want, resp.Conflicts)
+	}
+
+	m := SearchCmd{Json: true}.GetModelCustom(testBaseModel())
+	suite.NotContains(suite.runCmd(m), "conflicts")
+}
+
+func (suite *SubcommandTestSuite) TestSearchCmdYanked() {
+	m := InstallCmd{Driver: "test-driver-1", Level: suite.configLevel}.GetModelCustom(testBaseModel())
+	suite.runCmd(m)
+
+	yankedRegistry := baseModel{getDriverRegistry: getYankedTestDriverRegistry, downloadPkg: downloadTestPkg}
+	m = SearchCmd{Pattern: regexp.MustCompile("test-driver-1"), Verbose: true}.GetModelCustom(yankedRegistry)
+	out := suite.runCmd(m)
+	suite.Contains(out, "   Available Versions:\n    ╰── 1.0.0\n\n"+
+		"Warning: test-driver-1 is deprecated: unmaintained; use test-driver-2 instead\n"+
+		"Warning: test-driver-1 1.1.0 has been yanked: broken build")
+
+	want := []jsonschema.DriverWarning{
+		{Driver: "test-driver-1", Kind: "deprecated", Reason: "unmaintained", ReplacedBy: "test-driver-2"},
+		{Driver: "test-driver-1", Kind: "yanked", Version: "1.1.0", Reason: "broken build"},
+	}
+	for _, verbose := range []bool{false, true} {
+		m := SearchCmd{Json: true, Verbose: verbose}.GetModelCustom(yankedRegistry)
+		out := suite.runCmd(m)
+
+		var env jsonschema.Envelope
+		suite.Require().NoError(json.Unmarshal([]byte(out), &env), "output must be valid JSON: %s", out)
+		var resp jsonschema.SearchResponse
+		suite.Require().NoError(json.Unmarshal(env.Payload, &resp))
+		suite.Equal(want, resp.Warnings)
+	}
+
+	m = SearchCmd{Json: true}.GetModelCustom(testBaseModel())
+	suite.NotContains(suite.runCmd(m), "warnings")
+}
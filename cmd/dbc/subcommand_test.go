@@ -62,6 +62,49 @@ func getTestDriverRegistry() ([]dbc.Driver, error) {
 	return drivers.Drivers, nil
 }
 
+var acmeRegistry = dbc.Registry{
+	Name:    "acme",
+	BaseURL: must(url.Parse("https://acme.example.com/drivers")),
+}
+
+// getMultiTestDriverRegistry returns the test index followed by a second
+// registry, acme, that also publishes test-driver-1 but only version 1.0.0.
+func getMultiTestDriverRegistry() ([]dbc.Driver, error) {
+	drivers, err := getTestDriverRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	acme := drivers[0]
+	acme.Registry = &acmeRegistry
+	acme.PkgInfo = acme.PkgInfo[:1]
+	return append(drivers, acme), nil
+}
+
+// getYankedTestDriverRegistry returns the test index with test-driver-1
+// deprecated in favor of test-driver-2 and its v1.1.0 yanked.
+func getYankedTestDriverRegistry() ([]dbc.Driver, error) {
+	data, err := os.ReadFile("testdata/test_index.yaml")
+	if err != nil {
+		return nil, err
+	}
+	index := strings.Replace(string(data), "      - version: v1.1.0\n",
+		"      - version: v1.1.0\n        yanked: true\n        yanked_reason: broken build\n", 1)
+	index = strings.Replace(index, "    path: test-driver-1\n",
+		"    path: test-driver-1\n    deprecated: true\n    deprecated_reason: unmaintained\n    replaced_by: test-driver-2\n", 1)
+
+	drivers := struct {
+		Drivers []dbc.Driver `yaml:"drivers"`
+	}{}
+	if err := yaml.Unmarshal([]byte(index), &drivers); err != nil {
+		return nil, err
+	}
+	for i := range drivers.Drivers {
+		drivers.Drivers[i].Registry = &testRegistry
+	}
+	return drivers.Drivers, nil
+}
+
 func testBaseModel() baseModel {
 	return baseModel{getDriverRegistry: getTestDriverRegistry, downloadPkg: downloadTestPkg}
 }
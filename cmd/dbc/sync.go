@@ -15,6 +15,7 @@
 package main
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"io"
@@ -22,6 +23,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -29,6 +31,7 @@ import (
 	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/Masterminds/semver/v3"
 	"github.com/columnar-tech/dbc"
 	"github.com/columnar-tech/dbc/config"
 	"github.com/columnar-tech/dbc/internal/jsonschema"
@@ -41,6 +44,8 @@ type SyncCmd struct {
 	NoVerify           bool               `arg:"--no-verify" help:"Allow installation of drivers without a signature file"`
 	Json               bool               `arg:"--json" help:"Print output as JSON instead of plaintext"`
 	JsonStreamProgress bool               `arg:"--json-stream-progress" help:"Stream progress events as JSON lines (implies --json)"`
+	Locked             bool               `arg:"--locked" help:"Fail if the lockfile is missing or out of date with the driver list, and never update it"`
+	Frozen             bool               `arg:"--frozen" help:"Install exactly the versions in the lockfile without checking the driver list or updating the lockfile"`
 }
 
 func (c SyncCmd) GetModelCustom(baseModel baseModel) tea.Model {
@@ -51,6 +56,8 @@ func (c SyncCmd) GetModelCustom(baseModel baseModel) tea.Model {
 		NoVerify:           c.NoVerify,
 		jsonOutput:         c.Json || c.JsonStreamProgress,
 		jsonStreamProgress: c.JsonStreamProgress,
+		requireLocked:      c.Locked,
+		frozen:             c.Frozen,
 	}
 }
 
@@ -61,6 +68,8 @@ func (c SyncCmd) GetModel() tea.Model {
 		NoVerify:           c.NoVerify,
 		jsonOutput:         c.Json || c.JsonStreamProgress,
 		jsonStreamProgress: c.JsonStreamProgress,
+		requireLocked:      c.Locked,
+		frozen:             c.Frozen,
 		baseModel:          defaultBaseModel(),
 	}
 }
@@ -98,6 +107,7 @@ func (s syncModel) FinalOutput() string {
 		Installed: installed,
 		Skipped:   skipped,
 		Errors:    []jsonschema.SyncError{},
+		Warnings:  s.warnings,
 	})
 }
 
@@ -117,6 +127,15 @@ type syncModel struct {
 
 	// the list of drivers in the driver list
 	list DriversList
+	// drivers to re-resolve against the driver list, ignoring the versions
+	// in the lockfile; upgradeAll re-resolves every driver
+	upgrade    []string
+	upgradeAll bool
+	// requireLocked fails the sync if the lockfile is missing or out of date
+	// with the driver list; frozen installs the lockfile as is. With either,
+	// the lockfile is never written.
+	requireLocked bool
+	frozen        bool
 	// cdn driver registry index
 	driverIndex []dbc.Driver
 	// the list of package+version to install
@@ -135,6 +154,8 @@ type syncModel struct {
 	skippedDrivers []jsonschema.SyncedDriver
 	// newlyInstalled tracks freshly installed drivers for JSON output
 	newlyInstalled []jsonschema.SyncedDriver
+	// warnings flags deprecated drivers and yanked locked versions
+	warnings []jsonschema.DriverWarning
 
 	jsonOut io.Writer
 }
@@ -145,6 +166,10 @@ type driversListMsg struct {
 }
 
 func (s syncModel) Init() tea.Cmd {
+	if s.requireLocked && s.frozen {
+		return errCmd("--locked and --frozen cannot be used together")
+	}
+
 	return func() tea.Msg {
 		p, err := filepath.Abs(s.Path)
 		if err != nil {
@@ -185,67 +210,101 @@ func loadDriverList(path string) (DriversList, error) {
 }
 
 type installItem struct {
-	Driver   dbc.Driver
-	Package  dbc.PkgInfo
+	Driver  dbc.Driver
+	Package dbc.PkgInfo
+	// Packages are the packages of the resolved version to lock for every
+	// platform the driver list targets.
+	Packages []lockPackage
+	// Checksum is the driver library checksum locked for this platform.
 	Checksum string
 }
 
+// locked returns the package locked for the platform dbc is running on.
+func (item installItem) locked() lockPackage {
+	idx := slices.IndexFunc(item.Packages, func(p lockPackage) bool { return p.Platform == config.PlatformTuple() })
+	if idx == -1 {
+		return lockPackage{}
+	}
+	return item.Packages[idx]
+}
+
+// lockEntry returns the lockfile entry for item once version is installed,
+// recording the checksum of its driver library and, if it was downloaded,
+// the digest of its package for this platform.
+func (item installItem) lockEntry(version *semver.Version, libChecksum, digest string) lockInfo {
+	packages := slices.Clone(item.Packages)
+	for i := range packages {
+		if packages[i].Platform == config.PlatformTuple() {
+			packages[i].Checksum = libChecksum
+			packages[i].SHA256 = cmp.Or(packages[i].SHA256, digest)
+		}
+	}
+	return lockInfo{
+		Name:     item.Driver.Path,
+		Version:  version,
+		Registry: registryOrigin(item.Driver.Registry),
+		Packages: packages,
+	}
+}
+
 func (s syncModel) createInstallList(list DriversList) ([]installItem, error) {
 	// Load the lock file if it exists
 	lf, err := loadLockFile(s.LockFilePath)
-	if err != nil && !errors.Is(err, fs.ErrNotExist) {
-		return nil, err
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if s.requireLocked || s.frozen {
+			return nil, fmt.Errorf("lockfile %s not found; run `dbc lock` to create it", s.LockFilePath)
+		}
 	}
 
-	// construct our list of driver+version to install
-	var items []installItem
-	for name, spec := range list.Drivers {
-		var info lockInfo
-		if lf.lockinfo != nil {
-			info = lf.lockinfo[name]
-		}
+	r := resolver{
+		index:          s.driverIndex,
+		registryErrors: s.registryErrors,
+		locked:         lf,
+		upgrading:      s.upgrading,
+	}
+	if s.frozen {
+		return r.lockedItems()
+	}
 
-		// locate the driver info in the CDN driver registry index
-		drv, err := findDriver(name, s.driverIndex)
-		if err != nil {
-			return nil, wrapWithRegistryContext(err, s.registryErrors)
+	items, err := r.resolve(list)
+	if err != nil {
+		return nil, err
+	}
+	if s.requireLocked {
+		if err := checkLocked(list, lf, items); err != nil {
+			return nil, fmt.Errorf("lockfile %s is out of date: %w; run `dbc lock` to update it", s.LockFilePath, err)
 		}
+	}
+	return items, nil
+}
 
-		var pkg dbc.PkgInfo
-		// if the lockfile specified a version and either the driver list doesn't
-		// specify a version constraint or the version in the locked file is valid
-		// for that constraint, then we want to install the version in the lockfile
-		if info.Version != nil && (spec.Version == nil || spec.Version.Check(info.Version)) {
-			// install the locked version and verify checksum
-			pkg, err = drv.GetPackage(info.Version, config.PlatformTuple(), spec.Prerelease == "allow")
-		} else {
-			// no locked version or driver list version doesn't match locked file
-			if spec.Version != nil {
-				if spec.Prerelease == "allow" {
-					spec.Version.IncludePrerelease = true
-				}
-				pkg, err = drv.GetWithConstraint(spec.Version, config.PlatformTuple())
-			} else {
-				pkg, err = drv.GetPackage(nil, config.PlatformTuple(), spec.Prerelease == "allow")
-			}
+// syncWarnings returns the warnings for the drivers about to be synced: those
+// that are deprecated, and locked versions that have since been yanked.
+func syncWarnings(items []installItem) []jsonschema.DriverWarning {
+	var warnings []jsonschema.DriverWarning
+	for _, item := range items {
+		if w, ok := deprecationWarning(item.Driver); ok {
+			warnings = append(warnings, w)
 		}
-
-		if err != nil {
-			return nil, err
+		if item.Package.Yanked {
+			warnings = append(warnings, jsonschema.DriverWarning{
+				Driver:  item.Driver.Path,
+				Kind:    "yanked",
+				Version: item.Package.Version.String(),
+				Reason:  item.Package.YankedReason,
+			})
 		}
-
-		items = append(items, installItem{
-			Driver:   drv,
-			Package:  pkg,
-			Checksum: info.Checksum,
-		})
 	}
-	return items, nil
+	return warnings
 }
 
 type installedDrvMsg struct {
 	removed     *config.DriverInfo
 	info        config.DriverInfo
+	digest      string
 	postInstall []string
 }
 
@@ -295,6 +354,17 @@ func (s syncModel) installDriver(cfg config.Config, item installItem) tea.Cmd {
 				return
 			}
 
+			digest, err := checksum(output.Name())
+			if err != nil {
+				prog.Send(err)
+				return
+			}
+			if want := item.locked().SHA256; want != "" && digest != want {
+				prog.Send(fmt.Errorf("package for driver %s does not match the lockfile: %s != %s",
+					item.Driver.Path, digest, want))
+				return
+			}
+
 			var loc string
 			if loc, err = config.EnsureLocation(cfg); err != nil {
 				prog.Send(fmt.Errorf("failed to ensure config location: %w", err))
@@ -316,12 +386,25 @@ func (s syncModel) installDriver(cfg config.Config, item installItem) tea.Cmd {
 			}
 
 			driverPath := filepath.Join(finalDir, manifest.Files.Driver)
+			// The driver library must match the checksum locked for it too,
+			// which is all a lockfile without package digests can check.
+			if item.Checksum != "" {
+				chksum, err := checksum(driverPath)
+				if err == nil && chksum != item.Checksum {
+					err = fmt.Errorf("checksum mismatch for driver %s: %s != %s", item.Driver.Path, chksum, item.Checksum)
+				}
+				if err != nil {
+					_ = os.RemoveAll(finalDir)
+					prog.Send(err)
+					return
+				}
+			}
 
 			manifest.DriverInfo.ID = item.Driver.Path
 			manifest.DriverInfo.Source = "dbc"
 			manifest.DriverInfo.Driver.Shared.Set(config.PlatformTuple(), driverPath)
 
-			if err := verifySignature(manifest, s.NoVerify); err != nil {
+			if err := verifySignature(manifest, item.Package.Driver.Registry, s.NoVerify); err != nil {
 				_ = os.RemoveAll(finalDir)
 				prog.Send(fmt.Errorf("failed to verify signature: %w", err))
 				return
@@ -335,6 +418,7 @@ func (s syncModel) installDriver(cfg config.Config, item installItem) tea.Cmd {
 			prog.Send(installedDrvMsg{
 				removed:     removedDriver,
 				info:        manifest.DriverInfo,
+				digest:      digest,
 				postInstall: manifest.PostInstall.Messages,
 			})
 		}()
@@ -343,6 +427,10 @@ func (s syncModel) installDriver(cfg config.Config, item installItem) tea.Cmd {
 }
 
 func (s syncModel) writeLockFile() error {
+	if s.requireLocked || s.frozen {
+		return nil
+	}
+
 	f, err := os.Create(s.LockFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to create lock file %s: %w", s.LockFilePath, err)
@@ -365,6 +453,15 @@ func (s syncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		s.progress, cmd = s.progress.Update(msg)
 		return s, cmd
+	case downloadRetryMsg:
+		if s.jsonStreamProgress && s.index < len(s.installItems) {
+			s.emitJSON("sync.progress", jsonschema.SyncProgressEvent{
+				Phase:  "retrying",
+				Driver: s.installItems[s.index].Driver.Path,
+				Retry:  msg.info(),
+			})
+		}
+		return s, nil
 	case driversListMsg:
 		s.Path = msg.path
 		s.LockFilePath = strings.TrimSuffix(s.Path, filepath.Ext(s.Path)) + ".lock"
@@ -406,6 +503,14 @@ func (s syncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return items
 		}
 	case []installItem:
+		// Versions are resolved for the platforms the driver list targets,
+		// which needn't include this one.
+		for _, item := range msg {
+			if item.Package.PlatformTuple != config.PlatformTuple() {
+				return s, errCmd("driver %s %s has no package for this platform (%s)",
+					item.Driver.Path, item.Package.Version, config.PlatformTuple())
+			}
+		}
 		s.spinner = spinner.New()
 		s.progress = progress.New(
 			progress.WithDefaultBlend(),
@@ -413,6 +518,14 @@ func (s syncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			progress.WithoutPercentage(),
 		)
 		s.installItems = msg
+		s.warnings = syncWarnings(msg)
+
+		var warnCmd tea.Cmd
+		if !s.jsonOutput {
+			for _, w := range s.warnings {
+				warnCmd = tea.Sequence(warnCmd, tea.Println(formatWarning(w)))
+			}
+		}
 
 		if s.jsonStreamProgress {
 			for _, item := range msg {
@@ -423,14 +536,9 @@ func (s syncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		return s, tea.Batch(s.installDriver(s.cfg, s.installItems[s.index]), s.spinner.Tick)
+		return s, tea.Sequence(warnCmd, tea.Batch(s.installDriver(s.cfg, s.installItems[s.index]), s.spinner.Tick))
 	case alreadyInstalledDrvMsg:
-		s.locked.Drivers = append(s.locked.Drivers, lockInfo{
-			Name:     msg.info.ID,
-			Version:  msg.info.Version,
-			Platform: config.PlatformTuple(),
-			Checksum: msg.item.Checksum,
-		})
+		s.locked.Drivers = append(s.locked.Drivers, msg.item.lockEntry(msg.info.Version, msg.item.Checksum, ""))
 		s.skippedDrivers = append(s.skippedDrivers, jsonschema.SyncedDriver{
 			Name:    msg.info.ID,
 			Version: msg.info.Version.String(),
@@ -482,12 +590,7 @@ func (s syncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return s, tea.Sequence(tea.Println("Error: ", err), tea.Quit)
 		}
-		s.locked.Drivers = append(s.locked.Drivers, lockInfo{
-			Name:     msg.info.ID,
-			Version:  msg.info.Version,
-			Platform: config.PlatformTuple(),
-			Checksum: chksum,
-		})
+		s.locked.Drivers = append(s.locked.Drivers, s.installItems[s.index].lockEntry(msg.info.Version, chksum, msg.digest))
 		s.newlyInstalled = append(s.newlyInstalled, jsonschema.SyncedDriver{
 			Name:    msg.info.ID,
 			Version: msg.info.Version.String(),
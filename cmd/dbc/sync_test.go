@@ -19,10 +19,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
 	"github.com/columnar-tech/dbc/internal/jsonschema"
+	"github.com/go-faster/yaml"
 )
 
 func (suite *SubcommandTestSuite) TestSync() {
@@ -81,6 +85,368 @@ func (suite *SubcommandTestSuite) TestSyncWithVersion() {
 	}
 }
 
+func (suite *SubcommandTestSuite) TestSyncFromRegistry() {
+	listPath := filepath.Join(suite.tempdir, "dbc.toml")
+	lockPath := filepath.Join(suite.tempdir, "dbc.lock")
+	multiRegistry := baseModel{getDriverRegistry: getMultiTestDriverRegistry, downloadPkg: downloadTestPkg}
+
+	suite.Require().NoError(os.WriteFile(listPath, []byte(`[drivers.test-driver-1]
+registry = "acme"
+`), 0o644))
+
+	m := SyncCmd{Path: listPath}.GetModelCustom(multiRegistry)
+	suite.validateOutput("✓ test-driver-1-1.0.0\r\n\rDone!\r\n", "", suite.runCmd(m))
+
+	lf, err := loadLockFile(lockPath)
+	suite.Require().NoError(err)
+	suite.Require().Len(lf.Drivers, 1)
+	suite.Equal("https://acme.example.com/drivers", lf.Drivers[0].Registry)
+
+	suite.Run("locked registry is kept", func() {
+		suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\n"), 0o644))
+		m := SyncCmd{Path: listPath}.GetModelCustom(multiRegistry)
+		suite.validateOutput("✓ test-driver-1-1.0.0 already installed\r\n\rDone!\r\n", "", suite.runCmd(m))
+	})
+
+	suite.Run("pinning another registry ignores the lock", func() {
+		suite.Require().NoError(os.WriteFile(listPath, []byte(`[drivers.test-driver-1]
+registry = "https://registry.columnar.tech"
+`), 0o644))
+		m := SyncCmd{Path: listPath}.GetModelCustom(multiRegistry)
+		suite.validateOutput("✓ test-driver-1-1.1.0\r\n✓   removed test-driver-1-1.0.0\r\n\rDone!\r\n", "", suite.runCmd(m))
+
+		lf, err := loadLockFile(lockPath)
+		suite.Require().NoError(err)
+		suite.Equal("https://registry.columnar.tech", lf.Drivers[0].Registry)
+	})
+
+	suite.Run("unknown registry", func() {
+		suite.Require().NoError(os.WriteFile(listPath, []byte(`[drivers.test-driver-1]
+registry = "nowhere"
+`), 0o644))
+		m := SyncCmd{Path: listPath}.GetModelCustom(multiRegistry)
+		suite.Contains(suite.runCmdErr(m), "registry `nowhere` not found")
+	})
+}
+
+func (suite *SubcommandTestSuite) TestSyncYanked() {
+	listPath := filepath.Join(suite.tempdir, "dbc.toml")
+	lockPath := filepath.Join(suite.tempdir, "dbc.lock")
+	yankedRegistry := baseModel{getDriverRegistry: getYankedTestDriverRegistry, downloadPkg: downloadTestPkg}
+	suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\n"), 0o644))
+
+	m := SyncCmd{Path: listPath}.GetModelCustom(testBaseModel())
+	suite.validateOutput("✓ test-driver-1-1.1.0\r\n\rDone!\r\n", "", suite.runCmd(m))
+
+	syncWarnings := func() []jsonschema.DriverWarning {
+		m := SyncCmd{Path: listPath, Json: true}.GetModelCustom(yankedRegistry)
+		lines := strings.Split(strings.TrimSpace(suite.runCmd(m)), "\n")
+		var env jsonschema.Envelope
+		suite.Require().NoError(json.Unmarshal([]byte(lines[len(lines)-1]), &env))
+		var status jsonschema.SyncStatus
+		suite.Require().NoError(json.Unmarshal(env.Payload, &status))
+		return status.Warnings
+	}
+	deprecated := jsonschema.DriverWarning{
+		Driver: "test-driver-1", Kind: "deprecated", Reason: "unmaintained", ReplacedBy: "test-driver-2",
+	}
+
+	suite.Run("locked version is kept with a warning", func() {
+		suite.Equal([]jsonschema.DriverWarning{deprecated, {
+			Driver: "test-driver-1", Kind: "yanked", Version: "1.1.0", Reason: "broken build",
+		}}, syncWarnings())
+
+		lf, err := loadLockFile(lockPath)
+		suite.Require().NoError(err)
+		suite.Equal("1.1.0", lf.Drivers[0].Version.String())
+	})
+
+	suite.Run("unlocked sync skips the yanked version", func() {
+		suite.Require().NoError(os.Remove(lockPath))
+		suite.Equal([]jsonschema.DriverWarning{deprecated}, syncWarnings())
+
+		lf, err := loadLockFile(lockPath)
+		suite.Require().NoError(err)
+		suite.Equal("1.0.0", lf.Drivers[0].Version.String())
+	})
+}
+
+func (suite *SubcommandTestSuite) TestSyncMultiPlatformLock() {
+	listPath := filepath.Join(suite.tempdir, "dbc.toml")
+	lockPath := filepath.Join(suite.tempdir, "dbc.lock")
+	suite.Require().NoError(os.WriteFile(listPath, []byte(fmt.Sprintf(`platforms = ['%s', 'windows_amd64']
+
+[drivers.test-driver-1]
+`, config.PlatformTuple())), 0o644))
+
+	suite.runCmd(SyncCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+
+	digest, err := checksum(filepath.Join("testdata", "test-driver-1.1.tar.gz"))
+	suite.Require().NoError(err)
+	lf, err := loadLockFile(lockPath)
+	suite.Require().NoError(err)
+	suite.Equal(lockFileVersion, lf.Version)
+	suite.Require().Len(lf.Drivers, 1)
+	info := lf.Drivers[0]
+	suite.Equal("1.1.0", info.Version.String())
+
+	var platforms []string
+	for _, p := range info.Packages {
+		platforms = append(platforms, p.Platform)
+	}
+	suite.Equal(DriversList{Platforms: []string{config.PlatformTuple(), "windows_amd64"}}.targetPlatforms(), platforms)
+
+	local, ok := info.pkg(config.PlatformTuple())
+	suite.Require().True(ok)
+	suite.Equal(digest, local.SHA256)
+	suite.NotEmpty(local.Checksum)
+	other, ok := info.pkg("windows_amd64")
+	suite.Require().True(ok)
+	suite.True(strings.HasSuffix(other.URL, "test-driver-1/1.1.0/test_driver_win_amd64-1.1.0.tar.gz"), other.URL)
+	suite.Empty(other.Checksum)
+
+	suite.Run("package does not match the lockfile", func() {
+		data, err := os.ReadFile(lockPath)
+		suite.Require().NoError(err)
+		suite.Require().NoError(os.WriteFile(lockPath, []byte(strings.ReplaceAll(string(data), digest, strings.Repeat("0", 64))), 0o644))
+		suite.Require().NoError(os.Remove(filepath.Join(suite.tempdir, "test-driver-1.toml")))
+
+		out := suite.runCmdErr(SyncCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		suite.Contains(out, "package for driver test-driver-1 does not match the lockfile")
+	})
+
+	suite.Run("version 1 lockfile from another platform", func() {
+		suite.Require().NoError(os.Remove(lockPath))
+		suite.runCmd(SyncCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		suite.FileExists(filepath.Join(suite.tempdir, "test-driver-1.toml"))
+
+		suite.Require().NoError(os.WriteFile(lockPath, []byte(`version = 1
+
+[[drivers]]
+name = 'test-driver-1'
+version = '1.1.0'
+platform = 'other_platform'
+checksum = 'not the checksum of this platform'
+`), 0o644))
+		suite.runCmd(SyncCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+
+		lf, err := loadLockFile(lockPath)
+		suite.Require().NoError(err)
+		local, ok := lf.Drivers[0].pkg(config.PlatformTuple())
+		suite.Require().True(ok)
+		suite.NotEmpty(local.Checksum)
+		_, ok = lf.Drivers[0].pkg("other_platform")
+		suite.False(ok)
+	})
+
+	suite.Run("exactly the listed platforms are locked", func() {
+		suite.Require().NoError(os.WriteFile(listPath, []byte("platforms = ['windows_amd64']\n\n[drivers.test-driver-1]\n"), 0o644))
+		suite.runCmd(LockCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+
+		lf, err := loadLockFile(lockPath)
+		suite.Require().NoError(err)
+		suite.Require().Len(lf.Drivers[0].Packages, 1)
+		suite.Equal("windows_amd64", lf.Drivers[0].Packages[0].Platform)
+	})
+
+	suite.Run("packages locked elsewhere are kept", func() {
+		suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\n"), 0o644))
+		suite.runCmd(SyncCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+
+		lf, err := loadLockFile(lockPath)
+		suite.Require().NoError(err)
+		_, ok := lf.Drivers[0].pkg(config.PlatformTuple())
+		suite.True(ok)
+		_, ok = lf.Drivers[0].pkg("windows_amd64")
+		suite.True(ok, "the package locked by a sync on another platform is kept")
+	})
+
+	suite.Run("versions are picked for the listed platforms", func() {
+		suite.Require().NoError(os.Remove(lockPath))
+		suite.Require().NoError(os.WriteFile(listPath, []byte(fmt.Sprintf("platforms = ['%s', 'windows_amd64']\n\n[drivers.test-driver-1]\n",
+			config.PlatformTuple())), 0o644))
+		m := LockCmd{Path: listPath}.GetModelCustom(baseModel{
+			getDriverRegistry: getPartialTestDriverRegistry("windows_amd64"),
+			downloadPkg:       downloadTestPkg,
+		})
+		suite.runCmd(m)
+		suite.Equal(map[string]string{"test-driver-1": "1.0.0"}, suite.lockedVersions(lockPath),
+			"1.1.0 has no package for windows_amd64")
+	})
+
+	suite.Run("lists that don't target this platform", func() {
+		suite.Require().NoError(os.Remove(lockPath))
+		suite.Require().NoError(os.WriteFile(listPath, []byte("platforms = ['windows_amd64']\n\n[drivers.test-driver-1]\n"), 0o644))
+		partial := baseModel{
+			getDriverRegistry: getPartialTestDriverRegistry(config.PlatformTuple()),
+			downloadPkg:       downloadTestPkg,
+		}
+		suite.runCmd(LockCmd{Path: listPath}.GetModelCustom(partial))
+		suite.Equal(map[string]string{"test-driver-1": "1.1.0"}, suite.lockedVersions(lockPath),
+			"only the listed platforms need a package")
+
+		out := suite.runCmdErr(SyncCmd{Path: listPath}.GetModelCustom(partial))
+		suite.Contains(out, "driver test-driver-1 1.1.0 has no package for this platform")
+	})
+
+	suite.Run("platform without a package", func() {
+		suite.Require().NoError(os.Remove(lockPath))
+		suite.Require().NoError(os.WriteFile(listPath, []byte("platforms = ['linux_riscv64']\n\n[drivers.test-driver-1]\n"), 0o644))
+		out := suite.runCmdErr(SyncCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		suite.Contains(out, "no version of driver test-driver-1 that the driver list allows has a package for platform linux_riscv64")
+	})
+}
+
+// getPartialTestDriverRegistry returns the test index without the package of
+// test-driver-1 1.1.0 for platform.
+func getPartialTestDriverRegistry(platform string) func() ([]dbc.Driver, error) {
+	return func() ([]dbc.Driver, error) {
+		data, err := os.ReadFile("testdata/test_index.yaml")
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.SplitAfter(string(data), "\n")
+		for i := 0; i+1 < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "- platform: "+platform &&
+				strings.Contains(lines[i+1], "url: test-driver-1/1.1.0/") {
+				lines = slices.Delete(lines, i, i+2)
+				break
+			}
+		}
+
+		drivers := struct {
+			Drivers []dbc.Driver `yaml:"drivers"`
+		}{}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines, "")), &drivers); err != nil {
+			return nil, err
+		}
+		for i := range drivers.Drivers {
+			drivers.Drivers[i].Registry = &testRegistry
+		}
+		return drivers.Drivers, nil
+	}
+}
+
+func (suite *SubcommandTestSuite) TestSyncLocked() {
+	listPath := filepath.Join(suite.tempdir, "dbc.toml")
+	lockPath := filepath.Join(suite.tempdir, "dbc.lock")
+	manifest := filepath.Join(suite.tempdir, "test-driver-1.toml")
+
+	suite.runCmd(InitCmd{Path: listPath}.GetModel())
+	suite.runCmd(AddCmd{Path: listPath, Driver: []string{"test-driver-1=1.0.0"}}.GetModel())
+
+	out := suite.runCmdErr(SyncCmd{Path: listPath, Locked: true}.GetModelCustom(testBaseModel()))
+	suite.Contains(out, "lockfile "+lockPath+" not found")
+	suite.NoFileExists(manifest)
+
+	out = suite.runCmdErr(SyncCmd{Path: listPath, Locked: true, Frozen: true}.GetModelCustom(testBaseModel()))
+	suite.Contains(out, "--locked and --frozen cannot be used together")
+
+	// dbc lock records the digests the test registry doesn't list, so its
+	// lockfile can be synced from as is.
+	suite.runCmd(LockCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+	lock, err := os.ReadFile(lockPath)
+	suite.Require().NoError(err)
+
+	suite.runCmd(SyncCmd{Path: listPath, Locked: true}.GetModelCustom(testBaseModel()))
+	suite.FileExists(manifest)
+	after, err := os.ReadFile(lockPath)
+	suite.Require().NoError(err)
+	suite.Equal(string(lock), string(after))
+
+	other := "windows_amd64"
+	if config.PlatformTuple() == other {
+		other = "linux_amd64"
+	}
+	tests := []struct {
+		name, list, err string
+	}{
+		{"platform not locked", fmt.Sprintf("platforms = ['%s', '%s']\n[drivers.test-driver-1]\nversion = '=1.0.0'\n", config.PlatformTuple(), other),
+			"driver test-driver-1 has no package for platform " + other + " in the lockfile"},
+		{"constraint not satisfied", "[drivers.test-driver-1]\nversion = '>=1.1.0'\n",
+			"locked version 1.0.0 of driver test-driver-1 does not satisfy the constraint >=1.1.0"},
+		{"driver not locked", "[drivers.test-driver-1]\n[drivers.test-driver-2]\n",
+			"driver test-driver-2 is in the driver list but not in the lockfile"},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.Require().NoError(os.WriteFile(listPath, []byte(tt.list), 0o644))
+			out := suite.runCmdErr(SyncCmd{Path: listPath, Locked: true}.GetModelCustom(testBaseModel()))
+			suite.Contains(out, "lockfile "+lockPath+" is out of date")
+			suite.Contains(out, tt.err)
+			after, err := os.ReadFile(lockPath)
+			suite.Require().NoError(err)
+			suite.Equal(string(lock), string(after))
+		})
+	}
+
+	suite.Run("driver not listed", func() {
+		suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\n[drivers.test-driver-no-sig]\n"), 0o644))
+		suite.runCmd(LockCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\n"), 0o644))
+		out := suite.runCmdErr(SyncCmd{Path: listPath, Locked: true}.GetModelCustom(testBaseModel()))
+		suite.Contains(out, "driver test-driver-no-sig is in the lockfile but not in the driver list")
+	})
+}
+
+func (suite *SubcommandTestSuite) TestSyncFrozen() {
+	listPath := filepath.Join(suite.tempdir, "dbc.toml")
+	lockPath := filepath.Join(suite.tempdir, "dbc.lock")
+	manifest := filepath.Join(suite.tempdir, "test-driver-1.toml")
+
+	suite.runCmd(InitCmd{Path: listPath}.GetModel())
+	suite.runCmd(AddCmd{Path: listPath, Driver: []string{"test-driver-1=1.0.0"}}.GetModel())
+
+	out := suite.runCmdErr(SyncCmd{Path: listPath, Frozen: true}.GetModelCustom(testBaseModel()))
+	suite.Contains(out, "lockfile "+lockPath+" not found")
+
+	suite.runCmd(LockCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+	lock, err := os.ReadFile(lockPath)
+	suite.Require().NoError(err)
+
+	// the locked version is installed even though the driver list no longer
+	// allows it
+	suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\nversion = '>=1.1.0'\n"), 0o644))
+	out = suite.runCmd(SyncCmd{Path: listPath, Frozen: true, Json: true}.GetModelCustom(testBaseModel()))
+	var env jsonschema.Envelope
+	suite.Require().NoError(json.Unmarshal([]byte(strings.TrimSpace(out)), &env))
+	var status jsonschema.SyncStatus
+	suite.Require().NoError(json.Unmarshal(env.Payload, &status))
+	suite.Equal([]jsonschema.SyncedDriver{{Name: "test-driver-1", Version: "1.0.0"}}, status.Installed)
+	suite.FileExists(manifest)
+
+	after, err := os.ReadFile(lockPath)
+	suite.Require().NoError(err)
+	suite.Equal(string(lock), string(after))
+
+	suite.Run("lockfile without digests", func() {
+		suite.Require().NoError(os.Remove(manifest))
+		suite.runCmd(SyncCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		lf, err := loadLockFile(lockPath)
+		suite.Require().NoError(err)
+		pkg, ok := lf.Drivers[0].pkg(config.PlatformTuple())
+		suite.Require().True(ok)
+		suite.Require().NotEmpty(pkg.Checksum)
+
+		data, err := os.ReadFile(lockPath)
+		suite.Require().NoError(err)
+		noDigests := regexp.MustCompile(`(?m)^sha256 = .*\n`).ReplaceAllString(string(data), "")
+		suite.Require().NotContains(noDigests, "sha256")
+		suite.Require().NoError(os.WriteFile(lockPath, []byte(noDigests), 0o644))
+
+		suite.Require().NoError(os.Remove(manifest))
+		suite.runCmd(SyncCmd{Path: listPath, Frozen: true}.GetModelCustom(testBaseModel()))
+		suite.FileExists(manifest)
+
+		tampered := strings.ReplaceAll(noDigests, pkg.Checksum, strings.Repeat("0", 64))
+		suite.Require().NoError(os.WriteFile(lockPath, []byte(tampered), 0o644))
+		suite.Require().NoError(os.Remove(manifest))
+		out := suite.runCmdErr(SyncCmd{Path: listPath, Frozen: true}.GetModelCustom(testBaseModel()))
+		suite.Contains(out, "checksum mismatch for driver test-driver-1")
+		suite.NoFileExists(manifest)
+	})
+}
+
 func (suite *SubcommandTestSuite) TestSyncVirtualEnv() {
 	suite.T().Setenv("ADBC_DRIVER_PATH", "")
 
@@ -348,3 +714,33 @@ func (suite *SubcommandTestSuite) TestSync_JSONProgressStream() {
 	suite.Contains(kinds, "sync.progress")
 	suite.Equal("sync.status", kinds[len(kinds)-1])
 }
+
+func (suite *SubcommandTestSuite) TestSync_JSONProgressStreamRetry() {
+	tmpDir := suite.T().TempDir()
+	driverListPath := filepath.Join(tmpDir, "dbc.toml")
+	suite.Require().NoError(os.WriteFile(driverListPath, []byte("[drivers]\n[drivers.test-driver-1]\n"), 0644))
+
+	m := SyncCmd{Path: driverListPath, JsonStreamProgress: true}.
+		GetModelCustom(baseModel{getDriverRegistry: getTestDriverRegistry, downloadPkg: downloadTestPkgWithRetry})
+	out := suite.runCmd(m)
+
+	var phases []string
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		var env jsonschema.Envelope
+		suite.Require().NoError(json.Unmarshal([]byte(line), &env), "line must be valid JSON: %s", line)
+		var evt jsonschema.SyncProgressEvent
+		if env.Kind != "sync.progress" || json.Unmarshal(env.Payload, &evt) != nil {
+			continue
+		}
+		phases = append(phases, evt.Phase)
+		if evt.Phase == "retrying" {
+			suite.Equal("test-driver-1", evt.Driver)
+			suite.Equal(1, evt.Retry.Attempt)
+			suite.EqualValues(1024, evt.Retry.Offset)
+		}
+	}
+	suite.Equal([]string{"resolving", "retrying", "installed"}, phases)
+}
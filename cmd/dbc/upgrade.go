@@ -0,0 +1,220 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+)
+
+type UpgradeCmd struct {
+	Driver   []string           `arg:"positional" help:"Drivers to upgrade [default: every driver in the driver list]"`
+	Path     string             `arg:"-p" placeholder:"FILE" default:"./dbc.toml" help:"Driver list to upgrade"`
+	Level    config.ConfigLevel `arg:"-l" help:"Config level to install to (user, system)"`
+	NoVerify bool               `arg:"--no-verify" help:"Allow installation of drivers without a signature file"`
+	DryRun   bool               `arg:"--dry-run" help:"Show the upgrades without installing them or changing the lockfile"`
+	Json     bool               `arg:"--json" help:"Print output as JSON instead of plaintext"`
+}
+
+func (UpgradeCmd) Description() string {
+	return "Upgrade drivers to the newest versions the driver list allows.\n\n" +
+		"`dbc sync` keeps the versions in the lockfile; `dbc upgrade` resolves the given drivers, or every driver in the driver list, " +
+		"again against their version constraints, installs the new versions and updates the lockfile."
+}
+
+func (c UpgradeCmd) GetModelCustom(baseModel baseModel) tea.Model {
+	return upgradeModel{
+		syncModel: syncModel{
+			baseModel:  baseModel,
+			Path:       c.Path,
+			cfg:        getConfig(c.Level),
+			NoVerify:   c.NoVerify,
+			jsonOutput: c.Json,
+			upgrade:    c.Driver,
+			upgradeAll: len(c.Driver) == 0,
+		},
+		dryRun: c.DryRun,
+	}
+}
+
+func (c UpgradeCmd) GetModel() tea.Model {
+	return c.GetModelCustom(defaultBaseModel())
+}
+
+// upgradeModel is a sync that ignores the lockfile for the drivers being
+// upgraded, and reports how their locked versions changed.
+type upgradeModel struct {
+	syncModel
+
+	dryRun bool
+
+	// the drivers being upgraded, sorted by name
+	upgraded  []jsonschema.UpgradedDriver
+	unchanged []jsonschema.SyncedDriver
+}
+
+func (m upgradeModel) WithJSONWriter(w io.Writer) tea.Model {
+	m.jsonOut = w
+	return m
+}
+
+// changes compares the versions resolved for the drivers being upgraded
+// with those locked in lf.
+func (m upgradeModel) changes(items []installItem, lf LockFile) ([]jsonschema.UpgradedDriver, []jsonschema.SyncedDriver) {
+	var (
+		upgraded  []jsonschema.UpgradedDriver
+		unchanged []jsonschema.SyncedDriver
+	)
+	for _, item := range items {
+		name := item.Driver.Path
+		if !m.upgrading(name) {
+			continue
+		}
+
+		version := item.Package.Version
+		old := lf.lockinfo[name].Version
+		if old != nil && old.Equal(version) {
+			unchanged = append(unchanged, jsonschema.SyncedDriver{Name: name, Version: version.String()})
+			continue
+		}
+
+		u := jsonschema.UpgradedDriver{Name: name, To: version.String()}
+		if old != nil {
+			u.From = old.String()
+		}
+		upgraded = append(upgraded, u)
+	}
+
+	slices.SortFunc(upgraded, func(a, b jsonschema.UpgradedDriver) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(unchanged, func(a, b jsonschema.SyncedDriver) int { return strings.Compare(a.Name, b.Name) })
+	return upgraded, unchanged
+}
+
+func (m upgradeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case driversListMsg:
+		for _, name := range m.upgrade {
+			if _, ok := msg.list.Drivers[name]; !ok {
+				return m, errCmd("driver '%s' not found in %s", name, msg.path)
+			}
+		}
+	case []installItem:
+		lf, err := loadLockFile(m.LockFilePath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return m, errCmd("%w", err)
+		}
+		m.upgraded, m.unchanged = m.changes(msg, lf)
+
+		if m.dryRun {
+			m.installItems = msg
+			m.warnings = syncWarnings(msg)
+			m.done = true
+			return m, tea.Quit
+		}
+	case error:
+		if m.jsonOutput {
+			m.status, m.err = 1, msg
+			return m, tea.Sequence(tea.Println(marshalEnvelope("error", jsonschema.ErrorResponse{
+				Code:    "upgrade_failed",
+				Message: msg.Error(),
+			})), tea.Quit)
+		}
+	}
+
+	sm, cmd := m.syncModel.Update(msg)
+	m.syncModel = sm.(syncModel)
+	return m, cmd
+}
+
+func (m upgradeModel) View() tea.View {
+	if m.dryRun && m.done {
+		return tea.NewView("")
+	}
+	return m.syncModel.View()
+}
+
+func (m upgradeModel) FinalOutput() string {
+	if m.status != 0 {
+		return ""
+	}
+
+	if m.jsonOutput {
+		upgraded := m.upgraded
+		if upgraded == nil {
+			upgraded = []jsonschema.UpgradedDriver{}
+		}
+		unchanged := m.unchanged
+		if unchanged == nil {
+			unchanged = []jsonschema.SyncedDriver{}
+		}
+		return marshalEnvelope("upgrade.status", jsonschema.UpgradeStatus{
+			DryRun:    m.dryRun,
+			Upgraded:  upgraded,
+			Unchanged: unchanged,
+			Warnings:  m.warnings,
+		})
+	}
+	return formatUpgrades(m.upgraded, m.dryRun)
+}
+
+// formatUpgrades renders the old and new locked version of each upgraded
+// driver as a table.
+func formatUpgrades(upgraded []jsonschema.UpgradedDriver, dryRun bool) string {
+	if len(upgraded) == 0 {
+		return "All drivers are up to date."
+	}
+
+	t := table.New().Border(lipgloss.HiddenBorder()).
+		BorderTop(false).BorderBottom(false).BorderLeft(false).BorderRight(false).
+		Headers("DRIVER", "OLD", "NEW")
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	versionStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	t.StyleFunc(func(row, col int) lipgloss.Style {
+		if row == table.HeaderRow {
+			return headerStyle
+		}
+		switch col {
+		case 0:
+			return nameStyle
+		case 1:
+			return msgStyle
+		case 2:
+			return versionStyle
+		}
+		return lipgloss.NewStyle()
+	})
+	for _, u := range upgraded {
+		from := u.From
+		if from == "" {
+			from = "-"
+		}
+		t.Row(u.Name, from, u.To)
+	}
+
+	out := strings.TrimRight(t.String(), "\n")
+	if dryRun {
+		out += "\n" + msgStyle.Render("Dry run: no drivers were installed and the lockfile was not changed.")
+	}
+	return out
+}
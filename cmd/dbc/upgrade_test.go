@@ -0,0 +1,130 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/columnar-tech/dbc/internal/jsonschema"
+)
+
+// lockedVersions returns the version of each driver in the lockfile at p.
+func (suite *SubcommandTestSuite) lockedVersions(p string) map[string]string {
+	lf, err := loadLockFile(p)
+	suite.Require().NoError(err)
+	out := make(map[string]string, len(lf.Drivers))
+	for _, d := range lf.Drivers {
+		out[d.Name] = d.Version.String()
+	}
+	return out
+}
+
+func (suite *SubcommandTestSuite) TestUpgrade() {
+	listPath := filepath.Join(suite.tempdir, "dbc.toml")
+	lockPath := filepath.Join(suite.tempdir, "dbc.lock")
+
+	suite.runCmd(InitCmd{Path: listPath}.GetModel())
+	suite.runCmd(AddCmd{Path: listPath, Driver: []string{"test-driver-1=1.0.0"}}.GetModel())
+	suite.runCmd(SyncCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+	suite.Equal(map[string]string{"test-driver-1": "1.0.0"}, suite.lockedVersions(lockPath))
+
+	// Dropping the constraint doesn't move the locked version on sync.
+	suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\n"), 0o644))
+	suite.runCmd(SyncCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+	suite.Equal(map[string]string{"test-driver-1": "1.0.0"}, suite.lockedVersions(lockPath))
+
+	suite.Run("dry run", func() {
+		out := suite.runCmd(UpgradeCmd{Path: listPath, DryRun: true}.GetModelCustom(testBaseModel()))
+		suite.Contains(out, "DRIVER")
+		suite.Regexp(`test-driver-1\s+1\.0\.0\s+1\.1\.0`, out)
+		suite.Contains(out, "Dry run")
+		suite.Equal(map[string]string{"test-driver-1": "1.0.0"}, suite.lockedVersions(lockPath))
+	})
+
+	suite.Run("json dry run", func() {
+		out := suite.runCmd(UpgradeCmd{Path: listPath, DryRun: true, Json: true}.GetModelCustom(testBaseModel()))
+		var env jsonschema.Envelope
+		suite.Require().NoError(json.Unmarshal([]byte(strings.TrimSpace(out)), &env))
+		suite.Equal("upgrade.status", env.Kind)
+		var status jsonschema.UpgradeStatus
+		suite.Require().NoError(json.Unmarshal(env.Payload, &status))
+		suite.True(status.DryRun)
+		suite.Equal([]jsonschema.UpgradedDriver{{Name: "test-driver-1", From: "1.0.0", To: "1.1.0"}}, status.Upgraded)
+		suite.Empty(status.Unchanged)
+	})
+
+	out := suite.runCmd(UpgradeCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+	suite.Regexp(`test-driver-1\s+1\.0\.0\s+1\.1\.0`, out)
+	suite.NotContains(out, "Dry run")
+	suite.Equal(map[string]string{"test-driver-1": "1.1.0"}, suite.lockedVersions(lockPath))
+
+	suite.Run("up to date", func() {
+		out := suite.runCmd(UpgradeCmd{Path: listPath, Json: true}.GetModelCustom(testBaseModel()))
+		var env jsonschema.Envelope
+		suite.Require().NoError(json.Unmarshal([]byte(strings.TrimSpace(out)), &env))
+		var status jsonschema.UpgradeStatus
+		suite.Require().NoError(json.Unmarshal(env.Payload, &status))
+		suite.False(status.DryRun)
+		suite.Empty(status.Upgraded)
+		suite.Equal([]jsonschema.SyncedDriver{{Name: "test-driver-1", Version: "1.1.0"}}, status.Unchanged)
+
+		out = suite.runCmd(UpgradeCmd{Path: listPath}.GetModelCustom(testBaseModel()))
+		suite.Contains(out, "All drivers are up to date.")
+	})
+}
+
+func (suite *SubcommandTestSuite) TestUpgradeNamedDrivers() {
+	listPath := filepath.Join(suite.tempdir, "dbc.toml")
+	lockPath := filepath.Join(suite.tempdir, "dbc.lock")
+	suite.Require().NoError(os.WriteFile(listPath, []byte("[drivers.test-driver-1]\n[drivers.test-driver-2]\n"), 0o644))
+	suite.Require().NoError(os.WriteFile(lockPath, []byte(`version = 1
+
+[[drivers]]
+name = 'test-driver-1'
+version = '1.0.0'
+
+[[drivers]]
+name = 'test-driver-2'
+version = '2.0.0'
+`), 0o644))
+
+	list, err := loadDriverList(listPath)
+	suite.Require().NoError(err)
+	index, err := getTestDriverRegistry()
+	suite.Require().NoError(err)
+
+	resolve := func(upgrade []string, all bool) map[string]string {
+		s := syncModel{LockFilePath: lockPath, driverIndex: index, upgrade: upgrade, upgradeAll: all}
+		items, err := s.createInstallList(list)
+		suite.Require().NoError(err)
+		out := make(map[string]string, len(items))
+		for _, item := range items {
+			out[item.Driver.Path] = item.Package.Version.String()
+		}
+		return out
+	}
+
+	suite.Equal(map[string]string{"test-driver-1": "1.0.0", "test-driver-2": "2.0.0"}, resolve(nil, false))
+	suite.Equal(map[string]string{"test-driver-1": "1.1.0", "test-driver-2": "2.0.0"}, resolve([]string{"test-driver-1"}, false))
+	suite.Equal(map[string]string{"test-driver-1": "1.1.0", "test-driver-2": "2.1.0"}, resolve(nil, true))
+
+	suite.Run("not in driver list", func() {
+		m := UpgradeCmd{Path: listPath, Driver: []string{"test-driver-3"}}.GetModelCustom(testBaseModel())
+		suite.Contains(suite.runCmdErr(m), "driver 'test-driver-3' not found in")
+	})
+}
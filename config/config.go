@@ -307,6 +307,35 @@ func InflateTarball(f *os.File, outDir string) (Manifest, error) {
 	return m, nil
 }
 
+// ReadTarballManifest returns the MANIFEST of a driver tarball without
+// extracting any of its other files.
+func ReadTarballManifest(r io.Reader) (Manifest, error) {
+	rdr, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("could not create gzip reader: %w", err)
+	}
+	defer rdr.Close()
+
+	t := tar.NewReader(rdr)
+	for {
+		hdr, err := t.Next()
+		if errors.Is(err, io.EOF) {
+			return Manifest{}, fmt.Errorf("%w: no MANIFEST found in tarball", ErrInvalidManifest)
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("error reading tarball: %w", err)
+		}
+
+		if hdr.Name == "MANIFEST" {
+			m, err := decodeManifest(t, "", false)
+			if err != nil {
+				return Manifest{}, fmt.Errorf("could not decode manifest: %w", err)
+			}
+			return m, nil
+		}
+	}
+}
+
 func decodeManifest(r io.Reader, driverName string, requireShared bool) (Manifest, error) {
 	var di tomlDriverInfo
 	if err := toml.NewDecoder(r).Decode(&di); err != nil {
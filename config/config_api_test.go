@@ -18,6 +18,7 @@ package config_test
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"io"
 	"os"
@@ -141,6 +142,32 @@ func TestInflateTarball(t *testing.T) {
 	})
 }
 
+func TestReadTarballManifest(t *testing.T) {
+	t.Run("valid_tarball", func(t *testing.T) {
+		f, err := os.Open(filepath.Join("..", "cmd", "dbc", "testdata", "test-driver-1.tar.gz"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		manifest, err := config.ReadTarballManifest(f)
+		require.NoError(t, err)
+		assert.Equal(t, "Test Driver 1", manifest.Name)
+		assert.Equal(t, "MIT", manifest.License)
+		assert.Equal(t, "1.0.0", manifest.Version.String())
+	})
+
+	t.Run("missing_manifest", func(t *testing.T) {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "libdriver.so", Mode: 0644}))
+		require.NoError(t, tw.Close())
+		require.NoError(t, gw.Close())
+
+		_, err := config.ReadTarballManifest(&buf)
+		assert.ErrorIs(t, err, config.ErrInvalidManifest)
+	})
+}
+
 func TestInstallDriver(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		tmpDir := t.TempDir()
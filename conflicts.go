@@ -0,0 +1,80 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"context"
+	"slices"
+)
+
+// DriverConflict is a driver path published by more than one registry.
+type DriverConflict struct {
+	Path string
+	// Sources holds the driver as published by each registry, in registry
+	// priority order. The first is the one an unqualified name resolves to.
+	Sources []Driver
+}
+
+// Selected returns the source an unqualified driver name resolves to.
+func (c DriverConflict) Selected() Driver {
+	return c.Sources[0]
+}
+
+// SearchResult is the result of Client.SearchWithConflicts.
+type SearchResult struct {
+	// Drivers holds the drivers Search returns, once per registry
+	// publishing them.
+	Drivers []Driver
+	// Conflicts holds the driver paths in Drivers published by more than
+	// one registry, as FindConflicts returns them.
+	Conflicts []DriverConflict
+}
+
+// SearchWithConflicts searches like Search, and also returns the drivers
+// found in more than one registry as conflicts. As with Search, the results
+// from registries that could be fetched are returned alongside the joined
+// errors of those that could not.
+func (c *Client) SearchWithConflicts(ctx context.Context, pattern string) (SearchResult, error) {
+	drivers, err := c.Search(ctx, pattern)
+	return SearchResult{Drivers: drivers, Conflicts: FindConflicts(drivers)}, err
+}
+
+// FindConflicts returns the driver paths in drivers, as returned by
+// Client.Search, that are published by more than one registry, in the order
+// they first appear. A registry listing the same path more than once is not
+// a conflict; only its first entry is kept as a source.
+func FindConflicts(drivers []Driver) []DriverConflict {
+	var (
+		order   []string
+		sources = make(map[string][]Driver)
+	)
+	for _, d := range drivers {
+		existing, ok := sources[d.Path]
+		if !ok {
+			order = append(order, d.Path)
+		}
+		if !slices.ContainsFunc(existing, func(e Driver) bool { return e.Registry == d.Registry }) {
+			sources[d.Path] = append(existing, d)
+		}
+	}
+
+	var conflicts []DriverConflict
+	for _, path := range order {
+		if len(sources[path]) > 1 {
+			conflicts = append(conflicts, DriverConflict{Path: path, Sources: sources[path]})
+		}
+	}
+	return conflicts
+}
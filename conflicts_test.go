@@ -0,0 +1,85 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindConflicts(t *testing.T) {
+	serve := func(index string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/index.yaml" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(index))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	first := serve(`drivers:
+  - name: Shared
+    path: shared
+  - name: Only First
+    path: only-first
+  - name: Shared Again
+    path: shared
+`)
+	second := serve(`drivers:
+  - name: Shared
+    path: shared
+  - name: Only Second
+    path: only-second
+`)
+
+	c, err := dbc.NewClient(
+		dbc.WithHTTPClient(&http.Client{}),
+		dbc.WithIndexCacheDir(""),
+		dbc.WithRegistries([]dbc.Registry{
+			{BaseURL: mustParseURL(first.URL)},
+			{Name: "second", BaseURL: mustParseURL(second.URL)},
+		}),
+	)
+	require.NoError(t, err)
+
+	drivers, err := c.Search(t.Context(), "")
+	require.NoError(t, err)
+	assert.Len(t, drivers, 5, "search keeps every registry's entry")
+
+	conflicts := dbc.FindConflicts(drivers)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "shared", conflicts[0].Path)
+	require.Len(t, conflicts[0].Sources, 2, "duplicates within one registry are not a conflict")
+	assert.Equal(t, "Shared", conflicts[0].Selected().Title)
+	assert.Equal(t, first.URL, conflicts[0].Sources[0].Registry.BaseURL.String())
+	assert.Equal(t, "second", conflicts[0].Sources[1].Registry.Name)
+
+	assert.Empty(t, dbc.FindConflicts(drivers[:3]))
+
+	t.Run("search with conflicts", func(t *testing.T) {
+		result, err := c.SearchWithConflicts(t.Context(), "")
+		require.NoError(t, err)
+		assert.Equal(t, drivers, result.Drivers)
+		assert.Equal(t, conflicts, result.Conflicts)
+	})
+}
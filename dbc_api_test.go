@@ -29,6 +29,7 @@ import (
 	"testing"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
 	"github.com/columnar-tech/dbc"
 	"github.com/go-faster/yaml"
 	"github.com/stretchr/testify/assert"
@@ -51,6 +52,17 @@ func (t *testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 func TestMain(m *testing.M) {
+	// testTransport answers for the production registry hosts, so keep the
+	// index and package caches NewClient writes by default out of the real
+	// user config dir.
+	configHome, err := os.MkdirTemp("", "dbc-test-config-*")
+	if err != nil {
+		panic("cannot create temp config dir: " + err.Error())
+	}
+	os.Setenv("XDG_CONFIG_HOME", configHome)
+	os.Setenv("AppData", configHome)
+	os.Setenv("HOME", configHome)
+
 	indexData, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
 	if err != nil {
 		panic("cannot read test_index.yaml: " + err.Error())
@@ -82,6 +94,7 @@ func TestMain(m *testing.M) {
 
 	testServer.Close()
 	dbc.DefaultClient = origClient
+	os.RemoveAll(configHome)
 	os.Exit(code)
 }
 
@@ -358,6 +371,81 @@ func TestDriverGetWithConstraint(t *testing.T) {
 	})
 }
 
+func TestDriverYanked(t *testing.T) {
+	var index struct {
+		Drivers []dbc.Driver `yaml:"drivers"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(`drivers:
+  - name: Yanked Driver
+    path: yanked-driver
+    deprecated: true
+    deprecated_reason: no longer maintained
+    replaced_by: other-driver
+    pkginfo:
+      - version: v1.0.0
+        packages:
+          - platform: linux_amd64
+            url: yanked-driver/1.0.0/a.tar.gz
+      - version: v1.1.0
+        yanked: true
+        yanked_reason: corrupt build
+        packages:
+          - platform: linux_amd64
+            url: yanked-driver/1.1.0/a.tar.gz
+`), &index))
+	require.Len(t, index.Drivers, 1)
+	d := index.Drivers[0]
+	d.Registry = &dbc.Registry{BaseURL: mustParseURL("https://registry.example.com")}
+
+	assert.True(t, d.Deprecated)
+	assert.Equal(t, "no longer maintained", d.DeprecatedReason)
+	assert.Equal(t, "other-driver", d.ReplacedBy)
+
+	reason, yanked := d.Yanked(semver.MustParse("1.1.0"))
+	assert.True(t, yanked)
+	assert.Equal(t, "corrupt build", reason)
+	_, yanked = d.Yanked(semver.MustParse("1.0.0"))
+	assert.False(t, yanked)
+
+	t.Run("latest_skips_yanked", func(t *testing.T) {
+		pkg, err := d.GetPackage(nil, "linux_amd64", false)
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", pkg.Version.String())
+		assert.False(t, pkg.Yanked)
+	})
+
+	t.Run("exact_version_allows_yanked", func(t *testing.T) {
+		pkg, err := d.GetPackage(semver.MustParse("1.1.0"), "linux_amd64", false)
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.0", pkg.Version.String())
+		assert.True(t, pkg.Yanked)
+		assert.Equal(t, "corrupt build", pkg.YankedReason)
+	})
+
+	t.Run("constraint_skips_yanked", func(t *testing.T) {
+		c, err := semver.NewConstraint(">=1.0.0")
+		require.NoError(t, err)
+		pkg, err := d.GetWithConstraint(c, "linux_amd64")
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", pkg.Version.String())
+	})
+
+	t.Run("constraint_only_yanked", func(t *testing.T) {
+		c, err := semver.NewConstraint(">=1.1.0")
+		require.NoError(t, err)
+		_, err = d.GetWithConstraint(c, "linux_amd64")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "version 1.1.0 of driver `yanked-driver` has been yanked: corrupt build")
+	})
+
+	t.Run("max_version_skips_yanked", func(t *testing.T) {
+		max, ok := d.MaxVersion()
+		require.True(t, ok)
+		assert.Equal(t, "1.0.0", max.Version.String())
+		assert.False(t, max.Yanked)
+	})
+}
+
 func TestPkgInfoDownloadPackage(t *testing.T) {
 	t.Run("no_url", func(t *testing.T) {
 		pkg := dbc.PkgInfo{
@@ -425,6 +513,24 @@ func TestPkgInfoDownloadPackage(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to download driver")
 	})
+
+	t.Run("digest_mismatch", func(t *testing.T) {
+		for field, pkg := range map[string]dbc.PkgInfo{
+			"sha256": {SHA256: strings.Repeat("0", 64)},
+			"size":   {Size: 1},
+		} {
+			pkg.Driver = dbc.Driver{Title: "test-driver-1"}
+			pkg.Version = semver.MustParse("1.0.0")
+			pkg.PlatformTuple = "linux_amd64"
+			pkg.Path = mustParseURL(testServer.URL + "/test-driver-1.tar.gz")
+
+			f, err := pkg.DownloadPackage(nil)
+			assert.Nil(t, f)
+			var mismatch *dbc.DigestMismatchError
+			require.ErrorAs(t, err, &mismatch)
+			assert.Equal(t, field, mismatch.Field)
+		}
+	})
 }
 
 func TestSignedByColumnar(t *testing.T) {
@@ -442,6 +548,20 @@ func TestSignedByColumnar(t *testing.T) {
 
 		err := dbc.SignedByColumnar(lib, sig)
 		assert.NoError(t, err)
+
+		t.Run("registry with its own keys", func(t *testing.T) {
+			key, err := crypto.PGP().KeyGeneration().AddUserId("registry", "registry@example.com").New().GenerateKey()
+			require.NoError(t, err)
+			pub, err := key.ToPublic()
+			require.NoError(t, err)
+			armored, err := pub.Armor()
+			require.NoError(t, err)
+
+			reg := &dbc.Registry{SigningKeys: []string{armored}}
+			err = reg.VerifySignature(bytes.NewReader(files["test-driver-1-not-valid.so"]),
+				bytes.NewReader(files["test-driver-1-not-valid.so.sig"]))
+			assert.NoError(t, err, "the Columnar key stays trusted")
+		})
 	})
 
 	t.Run("invalid_signature", func(t *testing.T) {
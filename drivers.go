@@ -32,6 +32,7 @@ import (
 	"sort"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/ProtonMail/gopenpgp/v3/crypto"
@@ -43,12 +44,42 @@ import (
 var (
 	ErrUnauthorized         = errors.New("not authorized")
 	ErrUnauthorizedColumnar = errors.New("not authorized to access")
+	// ErrNotCached is returned in offline mode when an index or package
+	// has not been cached by an earlier online run.
+	ErrNotCached = errors.New("not available offline")
+	// ErrInvalidIndexSignature is returned when a registry index does not
+	// match the detached signature published next to it.
+	ErrInvalidIndexSignature = errors.New("registry index signature is invalid")
+	// ErrUnsignedIndex is returned when a registry that requires a signed
+	// index does not publish a signature for it.
+	ErrUnsignedIndex = errors.New("registry index is not signed")
 )
 
 type Registry struct {
 	Name    string
 	Drivers []Driver
 	BaseURL *url.URL
+	// Timeout bounds how long fetching this registry's index may take. Zero
+	// uses the client's default registry timeout.
+	Timeout time.Duration
+	// RequireSignature rejects the registry's index unless it has a valid
+	// detached signature. A signature is verified whenever the registry
+	// publishes one, whether or not it is required.
+	RequireSignature bool
+	// SigningKeys are armored OpenPGP public keys, or paths of files
+	// containing them, trusted to sign this registry's index and packages
+	// in addition to the Columnar key embedded in dbc.
+	SigningKeys []string
+	// Network overrides the client's network settings for requests to this
+	// registry.
+	Network NetworkConfig
+	// ClientCert and ClientKey are the paths of the PEM certificate and
+	// private key presented to the registry's host for mutual TLS.
+	ClientCert string
+	ClientKey  string
+
+	// httpClient sends this registry's requests when Network is set.
+	httpClient *http.Client
 }
 
 func mustParseURL(u string) *url.URL {
@@ -263,8 +294,22 @@ type PkgInfo struct {
 	PlatformTuple string
 
 	Path *url.URL
+	// SHA256 is the hex-encoded sha256 digest of the package listed in the
+	// registry index, or "" if the index doesn't list one.
+	SHA256 string
+	// Size is the package size in bytes listed in the registry index, or 0
+	// if the index doesn't list one.
+	Size int64
+	// Yanked is true if the registry has withdrawn this version. Yanked
+	// versions are only resolved when requested exactly.
+	Yanked       bool
+	YankedReason string
 }
 
+// DownloadPackage fetches the tarball for p into a new temporary directory,
+// like Client.DownloadPackage. A package that doesn't match the digest or
+// size listed in the index fails with a *DigestMismatchError.
+//
 // Deprecated: Use Client.Download instead.
 func (p PkgInfo) DownloadPackage(prog ProgressFunc) (*os.File, error) {
 	if p.Path == nil {
@@ -283,6 +328,11 @@ func (p PkgInfo) DownloadPackage(prog ProgressFunc) (*os.File, error) {
 	}
 	defer rsp.Body.Close()
 
+	body, err := verifyPackage(p, rsp.Body, rsp.ContentLength)
+	if err != nil {
+		return nil, err
+	}
+
 	fname := path.Base(location)
 	tmpdir, err := os.MkdirTemp(os.TempDir(), "adbc-drivers-*")
 	if err != nil {
@@ -301,7 +351,7 @@ func (p PkgInfo) DownloadPackage(prog ProgressFunc) (*os.File, error) {
 		fn:    prog,
 	}
 
-	_, err = io.Copy(pw, rsp.Body)
+	_, err = io.Copy(pw, body)
 	if err != nil {
 		output.Close()
 		output = nil
@@ -311,14 +361,23 @@ func (p PkgInfo) DownloadPackage(prog ProgressFunc) (*os.File, error) {
 }
 
 type pkginfo struct {
-	Version  *semver.Version `yaml:"version"`
-	Packages []struct {
-		PlatformTuple string `yaml:"platform"`
-		URL           string `yaml:"url"`
-	} `yaml:"packages"`
+	Version      *semver.Version `yaml:"version" json:"version"`
+	Yanked       bool            `yaml:"yanked" json:"yanked"`
+	YankedReason string          `yaml:"yanked_reason" json:"yanked_reason"`
+	Packages     []pkgentry      `yaml:"packages" json:"packages"`
+}
+
+type pkgentry struct {
+	PlatformTuple string `yaml:"platform" json:"platform"`
+	URL           string `yaml:"url" json:"url"`
+	SHA256        string `yaml:"sha256" json:"sha256"`
+	Size          int64  `yaml:"size" json:"size"`
 }
 
 func (p pkginfo) GetPackage(d Driver, platformTuple string) (PkgInfo, error) {
+	if d.IsSummary() {
+		return PkgInfo{}, fmt.Errorf("cannot resolve package for %s: only its summary has been loaded from a sharded index", d.Path)
+	}
 	if len(p.Packages) == 0 {
 		return PkgInfo{}, fmt.Errorf("no packages available for version %s", p.Version)
 	}
@@ -331,14 +390,24 @@ func (p pkginfo) GetPackage(d Driver, platformTuple string) (PkgInfo, error) {
 		if pkg.PlatformTuple == platformTuple {
 			var uri *url.URL
 
-			if pkg.URL != "" {
+			if isOCIURL(base) {
+				var err error
+				uri, err = ociPackageURL(base, pkg.URL, d.Path, p.Version.String(), platformTuple)
+				if err != nil {
+					return PkgInfo{}, err
+				}
+			} else if pkg.URL != "" {
 				var err error
 				uri, err = url.Parse(pkg.URL)
 				if err != nil {
 					return PkgInfo{}, fmt.Errorf("invalid package URL %q: %w", pkg.URL, err)
 				}
 				if !uri.IsAbs() {
-					uri = base.JoinPath(pkg.URL)
+					// Relative URLs are relative to the registry root,
+					// whether that is served over HTTP or a local directory.
+					ref := uri
+					uri = base.JoinPath(ref.EscapedPath())
+					uri.RawQuery = ref.RawQuery
 				}
 			} else {
 				uri = base.JoinPath(d.Path, p.Version.String(),
@@ -350,6 +419,10 @@ func (p pkginfo) GetPackage(d Driver, platformTuple string) (PkgInfo, error) {
 				Version:       p.Version,
 				PlatformTuple: platformTuple,
 				Path:          uri,
+				SHA256:        pkg.SHA256,
+				Size:          pkg.Size,
+				Yanked:        p.Yanked,
+				YankedReason:  p.YankedReason,
 			}, nil
 		}
 	}
@@ -368,15 +441,57 @@ func filter[T any](items iter.Seq[T], predicate func(T) bool) iter.Seq[T] {
 }
 
 type Driver struct {
-	Registry *Registry `yaml:"-"`
-
-	Title   string    `yaml:"name"`
-	Desc    string    `yaml:"description"`
-	License string    `yaml:"license"`
-	Path    string    `yaml:"path"`
-	URLs    []string  `yaml:"urls"`
-	DocsURL string    `yaml:"docs_url"`
-	PkgInfo []pkginfo `yaml:"pkginfo"`
+	Registry *Registry `yaml:"-" json:"-"`
+
+	Title   string    `yaml:"name" json:"name"`
+	Desc    string    `yaml:"description" json:"description"`
+	License string    `yaml:"license" json:"license"`
+	Path    string    `yaml:"path" json:"path"`
+	URLs    []string  `yaml:"urls" json:"urls"`
+	DocsURL string    `yaml:"docs_url" json:"docs_url"`
+	PkgInfo []pkginfo `yaml:"pkginfo" json:"pkginfo"`
+
+	// Deprecated is true if the registry no longer recommends the driver.
+	// DeprecatedReason explains why, and ReplacedBy names the driver to use
+	// instead, if any.
+	Deprecated       bool   `yaml:"deprecated" json:"deprecated"`
+	DeprecatedReason string `yaml:"deprecated_reason" json:"deprecated_reason"`
+	ReplacedBy       string `yaml:"replaced_by" json:"replaced_by"`
+
+	// shardExt is set for a driver listed by a sharded index, whose PkgInfo
+	// names each version's platforms but not its packages, to the extension
+	// of the file listing it in full: .yaml, or .json for a JSON index.
+	shardExt string
+}
+
+// IsSummary reports whether d was listed by a sharded index and so only
+// knows which versions and platforms exist. Client.LoadDriver fetches the
+// rest, which resolving a package requires.
+func (d Driver) IsSummary() bool { return d.shardExt != "" }
+
+// Yanked reports whether version v of the driver has been yanked and, if so,
+// the reason the registry gave.
+func (d Driver) Yanked(v *semver.Version) (reason string, yanked bool) {
+	for _, p := range d.PkgInfo {
+		if p.Yanked && p.Version.Equal(v) {
+			return p.YankedReason, true
+		}
+	}
+	return "", false
+}
+
+// yankedError explains that the versions of d matching a request were all
+// yanked, or returns nil if none of the matching versions were.
+func (d Driver) yankedError(match func(pkginfo) bool) error {
+	for _, p := range slices.Backward(d.PkgInfo) {
+		if p.Yanked && match(p) {
+			if p.YankedReason != "" {
+				return fmt.Errorf("version %s of driver `%s` has been yanked: %s", p.Version, d.Path, p.YankedReason)
+			}
+			return fmt.Errorf("version %s of driver `%s` has been yanked", p.Version, d.Path)
+		}
+	}
+	return nil
 }
 
 func (d Driver) HasNonPrerelease() bool {
@@ -390,17 +505,17 @@ func (d Driver) GetWithConstraint(c *semver.Constraints, platformTuple string) (
 		return PkgInfo{}, fmt.Errorf("no package info available for driver %s", d.Path)
 	}
 
-	itr := filter(slices.Values(d.PkgInfo), func(p pkginfo) bool {
+	matches := func(p pkginfo) bool {
 		if !c.Check(p.Version) {
 			return false
 		}
 
-		return slices.ContainsFunc(p.Packages, func(p struct {
-			PlatformTuple string `yaml:"platform"`
-			URL           string `yaml:"url"`
-		}) bool {
+		return slices.ContainsFunc(p.Packages, func(p pkgentry) bool {
 			return p.PlatformTuple == platformTuple
 		})
+	}
+	itr := filter(slices.Values(d.PkgInfo), func(p pkginfo) bool {
+		return !p.Yanked && matches(p)
 	})
 
 	var result *pkginfo
@@ -412,6 +527,9 @@ func (d Driver) GetWithConstraint(c *semver.Constraints, platformTuple string) (
 	}
 
 	if result == nil {
+		if err := d.yankedError(matches); err != nil {
+			return PkgInfo{}, fmt.Errorf("no package found for driver %s that satisfies constraints %s: %w", d.Path, c, err)
+		}
 		return PkgInfo{}, fmt.Errorf("no package found for driver %s that satisfies constraints %s", d.Path, c)
 	}
 
@@ -454,7 +572,14 @@ func (d Driver) GetPackage(version *semver.Version, platformTuple string, allowP
 
 	var pkg pkginfo
 	if version == nil {
-		pkg = slices.MaxFunc(pkglist, func(a, b pkginfo) int {
+		// Yanked versions are only installed when pinned exactly.
+		available := slices.Collect(filter(slices.Values(pkglist), func(p pkginfo) bool {
+			return !p.Yanked
+		}))
+		if len(available) == 0 {
+			return PkgInfo{}, d.yankedError(func(pkginfo) bool { return true })
+		}
+		pkg = slices.MaxFunc(available, func(a, b pkginfo) int {
 			return a.Version.Compare(b.Version)
 		})
 		version = pkg.Version
@@ -474,11 +599,19 @@ func (d Driver) GetPackage(version *semver.Version, platformTuple string, allowP
 	return pkg.GetPackage(d, platformTuple)
 }
 
+// MaxVersion returns the newest version of the driver that hasn't been
+// yanked, or the newest yanked one if they all have.
 func (d Driver) MaxVersion() (VersionInfo, bool) {
 	if len(d.PkgInfo) == 0 {
 		return VersionInfo{}, false
 	}
 	p := slices.MaxFunc(d.PkgInfo, func(a, b pkginfo) int {
+		if a.Yanked != b.Yanked {
+			if a.Yanked {
+				return -1
+			}
+			return 1
+		}
 		return a.Version.Compare(b.Version)
 	})
 	pkgs := make([]PackageInfo, 0, len(p.Packages))
@@ -486,22 +619,31 @@ func (d Driver) MaxVersion() (VersionInfo, bool) {
 		pkgs = append(pkgs, PackageInfo{
 			Platform: pkg.PlatformTuple,
 			URL:      pkg.URL,
+			SHA256:   pkg.SHA256,
+			Size:     pkg.Size,
 		})
 	}
-	return VersionInfo{Version: p.Version, Packages: pkgs}, true
+	return VersionInfo{Version: p.Version, Yanked: p.Yanked, YankedReason: p.YankedReason, Packages: pkgs}, true
 }
 
 // PackageInfo holds the platform and raw URL string for a single package entry.
 // The URL may be relative (joined against the registry base URL) or absolute.
+// SHA256 and Size are empty if the index doesn't list them.
 type PackageInfo struct {
 	Platform string
 	URL      string
+	SHA256   string
+	Size     int64
 }
 
 // VersionInfo holds the version and its associated packages for a driver.
 type VersionInfo struct {
-	Version  *semver.Version
-	Packages []PackageInfo
+	Version *semver.Version
+	// Yanked is true if the registry has withdrawn this version, for the
+	// reason in YankedReason.
+	Yanked       bool
+	YankedReason string
+	Packages     []PackageInfo
 }
 
 // AllVersions returns all version/package entries for the driver as exported
@@ -516,11 +658,15 @@ func (d Driver) AllVersions() []VersionInfo {
 			pkgs = append(pkgs, PackageInfo{
 				Platform: p.PlatformTuple,
 				URL:      p.URL,
+				SHA256:   p.SHA256,
+				Size:     p.Size,
 			})
 		}
 		result = append(result, VersionInfo{
-			Version:  pi.Version,
-			Packages: pkgs,
+			Version:      pi.Version,
+			Yanked:       pi.Yanked,
+			YankedReason: pi.YankedReason,
+			Packages:     pkgs,
 		})
 	}
 	return result
@@ -551,16 +697,5 @@ func SignedByColumnar(lib, sig io.Reader) error {
 	if err != nil {
 		return err
 	}
-
-	reader, err := verifier.VerifyingReader(lib, sig, crypto.Auto)
-	if err != nil {
-		return err
-	}
-
-	result, err := reader.DiscardAllAndVerifySignature()
-	if err != nil {
-		return err
-	}
-
-	return result.SignatureError()
+	return verifyDetached(verifier, lib, sig)
 }
@@ -0,0 +1,434 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/go-faster/yaml"
+)
+
+// indexDocument is the schema of an index.yaml as written by BuildIndex. It
+// mirrors what Driver and pkginfo decode, but omits empty fields so
+// regenerated files stay close to hand-written ones.
+type indexDocument struct {
+	Name    string        `yaml:"name,omitempty" json:"name,omitempty"`
+	Layout  string        `yaml:"layout,omitempty" json:"layout,omitempty"`
+	Drivers []indexDriver `yaml:"drivers" json:"drivers"`
+}
+
+type indexDriver struct {
+	Name             string         `yaml:"name" json:"name"`
+	Description      string         `yaml:"description,omitempty" json:"description,omitempty"`
+	License          string         `yaml:"license,omitempty" json:"license,omitempty"`
+	Path             string         `yaml:"path" json:"path"`
+	URLs             []string       `yaml:"urls,omitempty" json:"urls,omitempty"`
+	DocsURL          string         `yaml:"docs_url,omitempty" json:"docs_url,omitempty"`
+	Deprecated       bool           `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	DeprecatedReason string         `yaml:"deprecated_reason,omitempty" json:"deprecated_reason,omitempty"`
+	ReplacedBy       string         `yaml:"replaced_by,omitempty" json:"replaced_by,omitempty"`
+	PkgInfo          []indexVersion `yaml:"pkginfo,omitempty" json:"pkginfo,omitempty"`
+	// Versions summarizes PkgInfo in the index.yaml of a sharded index.
+	Versions []summaryVersion `yaml:"versions,omitempty" json:"versions,omitempty"`
+}
+
+type indexVersion struct {
+	Version      string         `yaml:"version" json:"version"`
+	Yanked       bool           `yaml:"yanked,omitempty" json:"yanked,omitempty"`
+	YankedReason string         `yaml:"yanked_reason,omitempty" json:"yanked_reason,omitempty"`
+	Packages     []indexPackage `yaml:"packages" json:"packages"`
+}
+
+type indexPackage struct {
+	Platform string `yaml:"platform" json:"platform"`
+	URL      string `yaml:"url,omitempty" json:"url,omitempty"`
+	SHA256   string `yaml:"sha256,omitempty" json:"sha256,omitempty"`
+	Size     int64  `yaml:"size,omitempty" json:"size,omitempty"`
+}
+
+// BuiltPackage describes a driver tarball that BuildIndex added to, or
+// refreshed in, an index.
+type BuiltPackage struct {
+	Driver   string
+	Version  *semver.Version
+	Platform string
+	// URL is the package URL relative to the directory of the index.
+	URL string
+	// SHA256 is the hex-encoded sha256 digest of the tarball.
+	SHA256 string
+	Size   int64
+	// New is true if the index did not list this package before.
+	New bool
+
+	manifest config.Manifest
+}
+
+var platformPattern = regexp.MustCompile(`(?:^|[_-])((?:linux|macos|windows|freebsd|openbsd)_[a-z0-9]+)(?:[-_.]|$)`)
+
+// platformFromFilename extracts the platform tuple (e.g. linux_amd64) from a
+// package file name. It also returns the offset where the tuple's separator
+// starts, so the caller can use the preceding text as the driver name.
+func platformFromFilename(name string) (string, int, bool) {
+	m := platformPattern.FindStringSubmatchIndex(name)
+	if m == nil {
+		return "", 0, false
+	}
+	return name[m[2]:m[3]], m[0], true
+}
+
+// inspectPackage reads the MANIFEST of the tarball at p and works out where
+// it belongs in the index. The driver path comes from the first directory
+// under root, as in the default <driver>/<version>/<file> layout, or from
+// the file name when the tarball sits directly in root. The package URL is
+// relative to indexDir, which must contain p.
+func inspectPackage(root, indexDir, p string) (BuiltPackage, error) {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return BuiltPackage{}, err
+	}
+	rel = filepath.ToSlash(rel)
+	name := path.Base(rel)
+
+	pkgURL, err := filepath.Rel(indexDir, p)
+	if err != nil {
+		return BuiltPackage{}, err
+	}
+	pkgURL = filepath.ToSlash(pkgURL)
+	if pkgURL == ".." || strings.HasPrefix(pkgURL, "../") {
+		return BuiltPackage{}, fmt.Errorf("%s: package is outside %s, the directory of the index", rel, indexDir)
+	}
+
+	platform, start, ok := platformFromFilename(name)
+	if !ok {
+		return BuiltPackage{}, fmt.Errorf("%s: cannot determine platform from file name", rel)
+	}
+
+	driver := name[:start]
+	if dir := path.Dir(rel); dir != "." {
+		driver, _, _ = strings.Cut(dir, "/")
+	}
+	if driver == "" {
+		return BuiltPackage{}, fmt.Errorf("%s: cannot determine driver name from path", rel)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return BuiltPackage{}, err
+	}
+	defer f.Close()
+
+	manifest, err := config.ReadTarballManifest(f)
+	if err != nil {
+		return BuiltPackage{}, fmt.Errorf("%s: %w", rel, err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return BuiltPackage{}, err
+	}
+	digest, size, err := fileDigest(f)
+	if err != nil {
+		return BuiltPackage{}, fmt.Errorf("%s: %w", rel, err)
+	}
+
+	return BuiltPackage{
+		Driver:   driver,
+		Version:  manifest.Version,
+		Platform: platform,
+		URL:      (&url.URL{Path: pkgURL}).EscapedPath(),
+		SHA256:   digest,
+		Size:     size,
+		manifest: manifest,
+	}, nil
+}
+
+// BuildIndex scans root for driver tarballs (*.tar.gz) and returns an
+// index.yaml listing them with URLs relative to indexDir, the directory the
+// index will be written to. All tarballs must be under indexDir, which is
+// usually root itself. Each tarball's MANIFEST
+// supplies the driver name, version and license; the platform is taken from
+// the file name. Each package's sha256 digest and size are recorded so
+// clients can verify their downloads.
+//
+// existing is the current index.yaml, or nil to start from scratch. Its
+// entries are kept, so hand-maintained fields like description and docs_url
+// survive regeneration, and packages that are no longer on disk are not
+// removed. Hidden directories are skipped.
+func BuildIndex(root, indexDir string, existing []byte) ([]byte, []BuiltPackage, error) {
+	doc, err := parseIndexDocument(existing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found []BuiltPackage
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".tar.gz") {
+			return nil
+		}
+
+		pkg, err := inspectPackage(root, indexDir, p)
+		if err != nil {
+			return err
+		}
+		found = append(found, pkg)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	slices.SortFunc(found, func(a, b BuiltPackage) int {
+		if c := strings.Compare(a.Driver, b.Driver); c != 0 {
+			return c
+		}
+		if c := a.Version.Compare(b.Version); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Platform, b.Platform)
+	})
+	for i := 1; i < len(found); i++ {
+		prev, cur := found[i-1], found[i]
+		if prev.Driver == cur.Driver && prev.Version.Equal(cur.Version) && prev.Platform == cur.Platform {
+			return nil, nil, fmt.Errorf("duplicate package for %s %s on %s: %s and %s",
+				cur.Driver, cur.Version, cur.Platform, prev.URL, cur.URL)
+		}
+	}
+
+	for i := range found {
+		isNew, err := doc.add(found[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		found[i].New = isNew
+	}
+
+	out, err := doc.encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, found, nil
+}
+
+// IndexEntry is a package for UpdateIndex to list in an index.
+type IndexEntry struct {
+	// Driver supplies the driver's metadata; its PkgInfo is ignored.
+	Driver   Driver
+	Version  *semver.Version
+	Platform string
+	// URL is the package URL, usually relative to the registry root.
+	URL string
+	// SHA256 and Size describe the package, if known, so clients can
+	// verify their downloads.
+	SHA256 string
+	Size   int64
+	// Yanked and YankedReason mark the package's version as withdrawn.
+	Yanked       bool
+	YankedReason string
+}
+
+// UpdateIndex merges entries into existing, an index.yaml or nil, and
+// returns the updated index. The name, description, license, urls, docs_url
+// and deprecation of each driver in entries are replaced with those of its
+// Driver, and the yanked status of each version with that of its entries;
+// everything else in existing is kept.
+func UpdateIndex(existing []byte, entries []IndexEntry) ([]byte, error) {
+	doc, err := parseIndexDocument(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		drv, _, err := doc.addPackage(e.Driver.Path, e.Version, indexPackage{
+			Platform: e.Platform,
+			URL:      e.URL,
+			SHA256:   e.SHA256,
+			Size:     e.Size,
+		})
+		if err != nil {
+			return nil, err
+		}
+		drv.Name = e.Driver.Title
+		drv.Description = e.Driver.Desc
+		drv.License = e.Driver.License
+		drv.URLs = e.Driver.URLs
+		drv.DocsURL = e.Driver.DocsURL
+		drv.Deprecated = e.Driver.Deprecated
+		drv.DeprecatedReason = e.Driver.DeprecatedReason
+		drv.ReplacedBy = e.Driver.ReplacedBy
+		for i := range drv.PkgInfo {
+			if v, err := semver.NewVersion(drv.PkgInfo[i].Version); err == nil && v.Equal(e.Version) {
+				drv.PkgInfo[i].Yanked = e.Yanked
+				drv.PkgInfo[i].YankedReason = e.YankedReason
+			}
+		}
+	}
+	return doc.encode()
+}
+
+func parseIndexDocument(data []byte) (indexDocument, error) {
+	var doc indexDocument
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := unmarshalIndex(data, &doc); err != nil {
+			return indexDocument{}, fmt.Errorf("failed to parse existing index: %w", err)
+		}
+	}
+	return doc, nil
+}
+
+// encode sorts the document and renders it as YAML.
+func (doc *indexDocument) encode() ([]byte, error) {
+	if err := doc.sort(); err != nil {
+		return nil, err
+	}
+
+	return encodeIndexYAML(doc)
+}
+
+// encodeIndexYAML renders v, an index document or part of one, as YAML.
+func encodeIndexYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode index: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// add merges pkg into the document and reports whether it is a new package.
+// The driver's name and license follow the MANIFEST of its newest version.
+func (doc *indexDocument) add(pkg BuiltPackage) (bool, error) {
+	newest := true
+	if idx := slices.IndexFunc(doc.Drivers, func(d indexDriver) bool { return d.Path == pkg.Driver }); idx != -1 {
+		for _, v := range doc.Drivers[idx].PkgInfo {
+			ver, err := semver.NewVersion(v.Version)
+			if err != nil {
+				return false, fmt.Errorf("driver %s: invalid version %q in existing index: %w", pkg.Driver, v.Version, err)
+			}
+			if ver.GreaterThan(pkg.Version) {
+				newest = false
+			}
+		}
+	}
+
+	drv, isNew, err := doc.addPackage(pkg.Driver, pkg.Version, indexPackage{
+		Platform: pkg.Platform,
+		URL:      pkg.URL,
+		SHA256:   pkg.SHA256,
+		Size:     pkg.Size,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if newest {
+		if pkg.manifest.Name != "" {
+			drv.Name = pkg.manifest.Name
+		}
+		if pkg.manifest.License != "" {
+			drv.License = pkg.manifest.License
+		}
+	}
+	return isNew, nil
+}
+
+// addPackage lists pkg as the package for driverPath at version on its
+// platform, replacing any existing entry and adding the driver and version if
+// needed. It returns the driver's entry and whether the package is new to the
+// document.
+func (doc *indexDocument) addPackage(driverPath string, version *semver.Version, pkg indexPackage) (*indexDriver, bool, error) {
+	idx := slices.IndexFunc(doc.Drivers, func(d indexDriver) bool { return d.Path == driverPath })
+	if idx == -1 {
+		doc.Drivers = append(doc.Drivers, indexDriver{Path: driverPath})
+		idx = len(doc.Drivers) - 1
+	}
+	drv := &doc.Drivers[idx]
+
+	vidx := -1
+	for i, v := range drv.PkgInfo {
+		ver, err := semver.NewVersion(v.Version)
+		if err != nil {
+			return nil, false, fmt.Errorf("driver %s: invalid version %q in existing index: %w", drv.Path, v.Version, err)
+		}
+		if ver.Equal(version) {
+			vidx = i
+		}
+	}
+	if vidx == -1 {
+		drv.PkgInfo = append(drv.PkgInfo, indexVersion{Version: "v" + version.String()})
+		vidx = len(drv.PkgInfo) - 1
+	}
+	ver := &drv.PkgInfo[vidx]
+
+	for i := range ver.Packages {
+		if ver.Packages[i].Platform == pkg.Platform {
+			ver.Packages[i] = pkg
+			return drv, false, nil
+		}
+	}
+	ver.Packages = append(ver.Packages, pkg)
+	return drv, true, nil
+}
+
+// sort orders drivers by path, versions oldest first and packages by
+// platform, so regenerating an unchanged registry yields identical output.
+func (doc *indexDocument) sort() error {
+	slices.SortStableFunc(doc.Drivers, func(a, b indexDriver) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	for i := range doc.Drivers {
+		drv := &doc.Drivers[i]
+		versions := make(map[string]*semver.Version, len(drv.PkgInfo))
+		for _, v := range drv.PkgInfo {
+			ver, err := semver.NewVersion(v.Version)
+			if err != nil {
+				return fmt.Errorf("driver %s: invalid version %q in existing index: %w", drv.Path, v.Version, err)
+			}
+			versions[v.Version] = ver
+		}
+		slices.SortStableFunc(drv.PkgInfo, func(a, b indexVersion) int {
+			return versions[a.Version].Compare(versions[b.Version])
+		})
+		for j := range drv.PkgInfo {
+			slices.SortStableFunc(drv.PkgInfo[j].Packages, func(a, b indexPackage) int {
+				return strings.Compare(a.Platform, b.Platform)
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/columnar-tech/dbc"
+	"github.com/go-faster/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyTestTarball copies one of the cmd/dbc testdata tarballs to
+// root/relPath, creating directories as needed.
+func copyTestTarball(t *testing.T, name, root, relPath string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", name))
+	require.NoError(t, err)
+	dest := filepath.Join(root, filepath.FromSlash(relPath))
+	require.NoError(t, os.MkdirAll(filepath.Dir(dest), 0o755))
+	require.NoError(t, os.WriteFile(dest, data, 0o644))
+}
+
+func decodeBuiltIndex(t *testing.T, data []byte) []dbc.Driver {
+	t.Helper()
+	var doc struct {
+		Drivers []dbc.Driver `yaml:"drivers"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &doc))
+	return doc.Drivers
+}
+
+func TestBuildIndex(t *testing.T) {
+	root := t.TempDir()
+	copyTestTarball(t, "test-driver-1.tar.gz", root, "test-driver-1/1.0.0/test_driver_linux_amd64-1.0.0.tar.gz")
+	copyTestTarball(t, "test-driver-1.1.tar.gz", root, "test-driver-1/1.1.0/test_driver_macos_arm64-1.1.0.tar.gz")
+	copyTestTarball(t, "test-driver-1.tar.gz", root, "flat_windows_amd64-1.0.0.tar.gz")
+	copyTestTarball(t, "test-driver-1.tar.gz", root, ".staging/ignored_linux_amd64-9.9.9.tar.gz")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("not a package"), 0o644))
+
+	existing := []byte(`name: private
+drivers:
+  - name: Old Name
+    description: Hand-written description
+    license: Proprietary
+    path: test-driver-1
+    docs_url: https://docs.example.com/test-driver-1
+    pkginfo:
+      - version: v1.0.0
+        packages:
+          - platform: linux_amd64
+            url: old/location.tar.gz
+      - version: v0.9.0
+        packages:
+          - platform: linux_amd64
+            url: test-driver-1/0.9.0/test_driver_linux_amd64-0.9.0.tar.gz
+`)
+
+	out, pkgs, err := dbc.BuildIndex(root, root, existing)
+	require.NoError(t, err)
+
+	require.Len(t, pkgs, 3)
+	assert.Equal(t, "flat", pkgs[0].Driver)
+	assert.Equal(t, "windows_amd64", pkgs[0].Platform)
+	assert.Equal(t, "flat_windows_amd64-1.0.0.tar.gz", pkgs[0].URL)
+	assert.True(t, pkgs[0].New)
+	assert.Len(t, pkgs[0].SHA256, 64)
+	assert.NotZero(t, pkgs[0].Size)
+	assert.Equal(t, "test-driver-1", pkgs[1].Driver)
+	assert.False(t, pkgs[1].New, "linux_amd64 1.0.0 was already indexed")
+	assert.True(t, pkgs[2].New)
+
+	drivers := decodeBuiltIndex(t, out)
+	require.Len(t, drivers, 2)
+	assert.Equal(t, "flat", drivers[0].Path)
+	assert.Equal(t, "Test Driver 1", drivers[0].Title)
+
+	drv := drivers[1]
+	assert.Equal(t, "test-driver-1", drv.Path)
+	assert.Equal(t, "Hand-written description", drv.Desc)
+	assert.Equal(t, "https://docs.example.com/test-driver-1", drv.DocsURL)
+	assert.Equal(t, "Test Driver 1", drv.Title, "name follows the newest MANIFEST")
+	assert.Equal(t, "MIT", drv.License)
+
+	require.Len(t, drv.PkgInfo, 3)
+	versions := drv.Versions("linux_amd64")
+	require.Len(t, versions, 2)
+	assert.Equal(t, "0.9.0", versions[0].String(), "versions missing on disk are kept")
+
+	assert.Equal(t, "test-driver-1/1.0.0/test_driver_linux_amd64-1.0.0.tar.gz", drv.PkgInfo[1].Packages[0].URL,
+		"URLs of packages found on disk are refreshed")
+
+	t.Run("regenerating is stable", func(t *testing.T) {
+		again, pkgs, err := dbc.BuildIndex(root, root, out)
+		require.NoError(t, err)
+		assert.Equal(t, string(out), string(again))
+		for _, p := range pkgs {
+			assert.False(t, p.New)
+		}
+	})
+
+	t.Run("index is usable as a local registry", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "index.yaml"), out, 0o644))
+		c, err := dbc.NewClient(dbc.WithBaseURL(root), dbc.WithIndexCacheDir(""))
+		require.NoError(t, err)
+
+		drivers, err := c.Search(t.Context(), "test-driver-1")
+		require.NoError(t, err)
+		pkg, err := findDriver(t, drivers, "test-driver-1").GetPackage(nil, "macos_arm64", false)
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.0", pkg.Version.String())
+		assert.Equal(t, pkgs[2].SHA256, pkg.SHA256)
+		assert.Equal(t, pkgs[2].Size, pkg.Size)
+
+		body, err := c.Download(t.Context(), pkg)
+		require.NoError(t, err)
+		body.Close()
+	})
+}
+
+func TestBuildIndexErrors(t *testing.T) {
+	t.Run("platform missing from file name", func(t *testing.T) {
+		root := t.TempDir()
+		copyTestTarball(t, "test-driver-1.tar.gz", root, "test-driver-1/1.0.0/driver.tar.gz")
+		_, _, err := dbc.BuildIndex(root, root, nil)
+		assert.ErrorContains(t, err, "cannot determine platform")
+	})
+
+	t.Run("duplicate packages", func(t *testing.T) {
+		root := t.TempDir()
+		copyTestTarball(t, "test-driver-1.tar.gz", root, "test-driver-1/a/x_linux_amd64.tar.gz")
+		copyTestTarball(t, "test-driver-1.tar.gz", root, "test-driver-1/b/x_linux_amd64.tar.gz")
+		_, _, err := dbc.BuildIndex(root, root, nil)
+		assert.ErrorContains(t, err, "duplicate package")
+	})
+
+	t.Run("unreadable tarball", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "bad_linux_amd64.tar.gz"), []byte("not gzip"), 0o644))
+		_, _, err := dbc.BuildIndex(root, root, nil)
+		assert.ErrorContains(t, err, "bad_linux_amd64.tar.gz")
+	})
+
+	t.Run("package outside the index directory", func(t *testing.T) {
+		root := t.TempDir()
+		copyTestTarball(t, "test-driver-1.tar.gz", root, "flat_linux_amd64-1.0.0.tar.gz")
+		_, _, err := dbc.BuildIndex(root, filepath.Join(root, "out"), nil)
+		assert.ErrorContains(t, err, "outside")
+	})
+}
+
+func TestBuildIndexInParentDir(t *testing.T) {
+	indexDir := t.TempDir()
+	root := filepath.Join(indexDir, "packages")
+	copyTestTarball(t, "test-driver-1.tar.gz", root, "test-driver-1/1.0.0/test_driver_linux_amd64-1.0.0.tar.gz")
+
+	_, pkgs, err := dbc.BuildIndex(root, indexDir, nil)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	assert.Equal(t, "test-driver-1", pkgs[0].Driver, "the driver path is still taken from root")
+	assert.Equal(t, "packages/test-driver-1/1.0.0/test_driver_linux_amd64-1.0.0.tar.gz", pkgs[0].URL)
+}
+
+func TestUpdateIndex(t *testing.T) {
+	existing := []byte(`name: mirror
+drivers:
+  - name: Other
+    path: other
+    pkginfo:
+      - version: v1.0.0
+        packages:
+          - platform: linux_amd64
+            url: other/1.0.0/other_linux_amd64-1.0.0.tar.gz
+  - name: Stale Name
+    path: test-driver-1
+    pkginfo:
+      - version: v1.0.0
+        packages:
+          - platform: linux_amd64
+            url: test-driver-1/1.0.0/test-driver-1_linux_amd64-1.0.0.tar.gz
+`)
+
+	drv := dbc.Driver{
+		Title:   "Test Driver 1",
+		Desc:    "This is a test driver",
+		License: "MIT",
+		Path:    "test-driver-1",
+		DocsURL: "https://example.com/docs",
+	}
+	out, err := dbc.UpdateIndex(existing, []dbc.IndexEntry{
+		{Driver: drv, Version: semver.MustParse("1.1.0"), Platform: "linux_amd64", URL: "test-driver-1/1.1.0/a.tar.gz"},
+		{Driver: drv, Version: semver.MustParse("1.0.0"), Platform: "macos_arm64", URL: "test-driver-1/1.0.0/b.tar.gz"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "name: mirror\n")
+
+	drivers := decodeBuiltIndex(t, out)
+	require.Len(t, drivers, 2)
+	assert.Equal(t, "other", drivers[0].Path)
+
+	got := drivers[1]
+	assert.Equal(t, "Test Driver 1", got.Title)
+	assert.Equal(t, "This is a test driver", got.Desc)
+	assert.Equal(t, "https://example.com/docs", got.DocsURL)
+	assert.Len(t, got.Versions("linux_amd64"), 2)
+	assert.Len(t, got.Versions("macos_arm64"), 1)
+
+	again, err := dbc.UpdateIndex(out, nil)
+	require.NoError(t, err)
+	assert.Equal(t, string(out), string(again))
+}
+
+func TestUpdateIndexYanked(t *testing.T) {
+	drv := dbc.Driver{
+		Title:            "Test Driver 1",
+		Path:             "test-driver-1",
+		Deprecated:       true,
+		DeprecatedReason: "superseded",
+		ReplacedBy:       "test-driver-2",
+	}
+	out, err := dbc.UpdateIndex(nil, []dbc.IndexEntry{
+		{Driver: drv, Version: semver.MustParse("1.0.0"), Platform: "linux_amd64", URL: "a.tar.gz"},
+		{Driver: drv, Version: semver.MustParse("1.1.0"), Platform: "linux_amd64", URL: "b.tar.gz",
+			Yanked: true, YankedReason: "broken"},
+	})
+	require.NoError(t, err)
+
+	drivers := decodeBuiltIndex(t, out)
+	require.Len(t, drivers, 1)
+	got := drivers[0]
+	assert.True(t, got.Deprecated)
+	assert.Equal(t, "superseded", got.DeprecatedReason)
+	assert.Equal(t, "test-driver-2", got.ReplacedBy)
+
+	reason, yanked := got.Yanked(semver.MustParse("1.1.0"))
+	assert.True(t, yanked)
+	assert.Equal(t, "broken", reason)
+	_, yanked = got.Yanked(semver.MustParse("1.0.0"))
+	assert.False(t, yanked)
+	assert.NotContains(t, string(out), "yanked: false")
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/columnar-tech/dbc/internal"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	indexCacheDataFile = "index.yaml"
+	indexCacheMetaFile = "index.toml"
+)
+
+// indexCacheMeta is stored next to a cached index and records the validators
+// the registry returned with it, so the next fetch can be made conditional.
+type indexCacheMeta struct {
+	URL          string `toml:"url"`
+	ETag         string `toml:"etag,omitempty"`
+	LastModified string `toml:"last_modified,omitempty"`
+	SHA256       string `toml:"sha256"`
+	// Signed records that the index was verified against a detached
+	// signature before it was cached.
+	Signed bool `toml:"signed,omitempty"`
+	// File is the name the index was requested as, such as index.json, and
+	// ContentType the media type it was served with. Empty for caches
+	// written before JSON indexes, which were all index.yaml.
+	File        string    `toml:"file,omitempty"`
+	ContentType string    `toml:"content_type,omitempty"`
+	FetchedAt   time.Time `toml:"fetched_at"`
+}
+
+// indexCache is an on-disk cache of registry indexes, one directory per
+// registry. The zero value is a disabled cache: loads miss and stores are
+// no-ops, so a Client built without NewClient behaves as before.
+type indexCache struct {
+	dir string
+}
+
+// defaultIndexCacheDir returns the cache location used by NewClient, or "" if
+// no user config directory can be located (e.g. WASM hosts).
+func defaultIndexCacheDir() string {
+	dir, err := internal.GetCachePath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "registries")
+}
+
+func (c indexCache) entryDir(base *url.URL) string {
+	sum := sha256.Sum256([]byte(registryURLKey(base)))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16]))
+}
+
+// load returns the cached index for the registry at base. The body is checked
+// against the digest recorded in the metadata, so a torn or concurrent write
+// is reported as a miss rather than decoded with the wrong validators.
+func (c indexCache) load(base *url.URL) ([]byte, indexCacheMeta, bool) {
+	meta, ok := c.loadMeta(base)
+	if !ok {
+		return nil, indexCacheMeta{}, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.entryDir(base), indexCacheDataFile))
+	if err != nil {
+		return nil, indexCacheMeta{}, false
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != meta.SHA256 {
+		return nil, indexCacheMeta{}, false
+	}
+
+	return data, meta, true
+}
+
+// loadMeta returns the metadata of the cached index for the registry at
+// base, without reading or checking the index itself.
+func (c indexCache) loadMeta(base *url.URL) (indexCacheMeta, bool) {
+	if c.dir == "" || base == nil {
+		return indexCacheMeta{}, false
+	}
+
+	metaData, err := os.ReadFile(filepath.Join(c.entryDir(base), indexCacheMetaFile))
+	if err != nil {
+		return indexCacheMeta{}, false
+	}
+
+	var meta indexCacheMeta
+	if err := toml.Unmarshal(metaData, &meta); err != nil {
+		return indexCacheMeta{}, false
+	}
+	return meta, true
+}
+
+// store saves data as the cached index for the registry at base. Both files
+// are replaced atomically so concurrent dbc processes never observe a partial
+// write.
+func (c indexCache) store(base *url.URL, data []byte, meta indexCacheMeta) error {
+	if c.dir == "" || base == nil {
+		return nil
+	}
+
+	dir := c.entryDir(base)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create index cache directory: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	meta.URL = base.Redacted()
+	meta.SHA256 = hex.EncodeToString(sum[:])
+	if meta.FetchedAt.IsZero() {
+		meta.FetchedAt = time.Now().UTC()
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(meta); err != nil {
+		return fmt.Errorf("failed to encode index cache metadata: %w", err)
+	}
+
+	if err := internal.WriteFileAtomic(filepath.Join(dir, indexCacheDataFile), data, 0o600); err != nil {
+		return err
+	}
+	return internal.WriteFileAtomic(filepath.Join(dir, indexCacheMetaFile), buf.Bytes(), 0o600)
+}
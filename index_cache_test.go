@@ -0,0 +1,139 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexCacheRevalidation(t *testing.T) {
+	indexData, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
+	require.NoError(t, err)
+
+	const etag = `"index-v1"`
+	var full, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(indexData)
+	}))
+	t.Cleanup(srv.Close)
+
+	cacheDir := t.TempDir()
+	newClient := func() *dbc.Client {
+		c, err := dbc.NewClient(
+			dbc.WithHTTPClient(&http.Client{}),
+			dbc.WithBaseURL(srv.URL),
+			dbc.WithIndexCacheDir(cacheDir),
+		)
+		require.NoError(t, err)
+		return c
+	}
+
+	first, err := newClient().Search(t.Context(), "")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, full.Load())
+	assert.EqualValues(t, 0, notModified.Load())
+
+	// A fresh client (i.e. a new dbc invocation) revalidates the cached copy
+	// and decodes it instead of downloading the index again.
+	second, err := newClient().Search(t.Context(), "")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, full.Load())
+	assert.EqualValues(t, 1, notModified.Load())
+
+	require.Len(t, second, len(first))
+	for i := range first {
+		assert.Equal(t, first[i].Path, second[i].Path)
+		assert.Equal(t, srv.URL, second[i].Registry.BaseURL.String())
+	}
+}
+
+func TestIndexCacheIgnoresCorruptEntry(t *testing.T) {
+	indexData, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
+	require.NoError(t, err)
+
+	var conditional atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") != "" {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(indexData)
+	}))
+	t.Cleanup(srv.Close)
+
+	cacheDir := t.TempDir()
+	c, err := dbc.NewClient(
+		dbc.WithHTTPClient(&http.Client{}),
+		dbc.WithBaseURL(srv.URL),
+		dbc.WithIndexCacheDir(cacheDir),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Search(t.Context(), "")
+	require.NoError(t, err)
+
+	// Truncate the cached body; the digest check must turn this into a miss
+	// so the next fetch is unconditional.
+	matches, err := filepath.Glob(filepath.Join(cacheDir, "*", "index.yaml"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.NoError(t, os.WriteFile(matches[0], []byte("drivers: ["), 0o600))
+
+	drivers, err := c.Search(t.Context(), "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, drivers)
+	assert.EqualValues(t, 0, conditional.Load())
+}
+
+func TestIndexCacheDisabled(t *testing.T) {
+	srv := newInstallTestServer(t)
+	c, err := dbc.NewClient(
+		dbc.WithHTTPClient(&http.Client{}),
+		dbc.WithBaseURL(srv.URL),
+		dbc.WithIndexCacheDir(""),
+	)
+	require.NoError(t, err)
+
+	drivers, err := c.Search(t.Context(), "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, drivers)
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-faster/yaml"
+)
+
+// jsonIndexFile is the index a registry may publish instead of index.yaml,
+// with the same structure written as JSON.
+const jsonIndexFile = "index.json"
+
+// indexFiles are the names a registry's index is looked for under, in order.
+var indexFiles = []string{indexFile, jsonIndexFile}
+
+// isJSONIndex reports whether data, an index or one of the per-driver files
+// of a sharded index, is written as JSON rather than YAML.
+func isJSONIndex(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n\ufeff")
+	return len(data) > 0 && data[0] == '{'
+}
+
+// indexExt returns the file extension matching the format of data.
+func indexExt(data []byte) string {
+	if isJSONIndex(data) {
+		return ".json"
+	}
+	return ".yaml"
+}
+
+// indexFileFor returns the name of the index written in the format with the
+// file extension ext.
+func indexFileFor(ext string) string {
+	if ext == ".json" {
+		return jsonIndexFile
+	}
+	return indexFile
+}
+
+// servedFile returns the file a response to a request for name, with the
+// given Content-Type, holds. A registry asked for a .yaml file may negotiate
+// JSON instead, as though the .json file had been requested, and publishes
+// the signature of that as, for example, index.json.sig.
+func servedFile(name, contentType string) string {
+	if path.Ext(name) != ".yaml" {
+		return name
+	}
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil && mt == "application/json" {
+		return strings.TrimSuffix(name, ".yaml") + ".json"
+	}
+	return name
+}
+
+// unmarshalIndex decodes data, an index or one of the per-driver files of a
+// sharded index, into v. JSON is decoded with encoding/json, which is much
+// faster than YAML on large indexes; anything else is decoded as YAML.
+func unmarshalIndex(data []byte, v any) error {
+	if isJSONIndex(data) {
+		err := json.Unmarshal(data, v)
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			return err
+		}
+		// A YAML flow mapping starts with a brace too.
+	}
+	return yaml.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// statusError is an unexpected HTTP status returned for a registry file.
+// If err is set, it is the error the status means and is reported instead.
+type statusError struct {
+	code   int
+	status string
+	err    error
+}
+
+func (e *statusError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return e.status
+}
+
+func (e *statusError) Unwrap() error { return e.err }
+
+// isNotFound reports whether err means a registry has no such file, so
+// another name for it may be tried.
+func isNotFound(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusNotFound
+	}
+	return errors.Is(err, fs.ErrNotExist)
+}
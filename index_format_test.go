@@ -0,0 +1,235 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/go-faster/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// yamlToJSON rewrites data, a YAML index or driver file, as JSON.
+func yamlToJSON(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var v any
+	require.NoError(t, yaml.Unmarshal(data, &v))
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	return out
+}
+
+// assertSameDrivers checks that got lists the same drivers, versions and
+// packages as want.
+func assertSameDrivers(t *testing.T, want, got []Driver) {
+	t.Helper()
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.Equal(t, want[i].Path, got[i].Path)
+		assert.Equal(t, want[i].Title, got[i].Title)
+		assert.Equal(t, want[i].AllVersions(), got[i].AllVersions())
+	}
+}
+
+func TestUnmarshalIndex(t *testing.T) {
+	var doc indexDocument
+	require.NoError(t, unmarshalIndex([]byte(`{"name": "json", "drivers": [{"name": "A", "path": "a"}]}`), &doc))
+	assert.Equal(t, "json", doc.Name)
+	require.Len(t, doc.Drivers, 1)
+	assert.Equal(t, "a", doc.Drivers[0].Path)
+
+	// A YAML flow mapping also starts with a brace.
+	doc = indexDocument{}
+	require.NoError(t, unmarshalIndex([]byte("{name: flow, drivers: []}"), &doc))
+	assert.Equal(t, "flow", doc.Name)
+
+	assert.Error(t, unmarshalIndex([]byte(`{"drivers": "nope"}`), &doc))
+}
+
+func TestIndexDocumentAddKeepsName(t *testing.T) {
+	doc, err := parseIndexDocument([]byte(`drivers:
+  - name: Test Driver 1
+    license: MIT
+    path: test-driver-1
+`))
+	require.NoError(t, err)
+
+	_, err = doc.add(BuiltPackage{
+		Driver:   "test-driver-1",
+		Version:  semver.MustParse("1.0.0"),
+		Platform: "linux_amd64",
+		URL:      "a.tar.gz",
+		manifest: config.Manifest{},
+	})
+	require.NoError(t, err)
+	require.Len(t, doc.Drivers, 1)
+	assert.Equal(t, "Test Driver 1", doc.Drivers[0].Name, "an empty MANIFEST name keeps the existing one")
+	assert.Equal(t, "MIT", doc.Drivers[0].License)
+
+	_, err = doc.add(BuiltPackage{
+		Driver:   "test-driver-1",
+		Version:  semver.MustParse("1.1.0"),
+		Platform: "linux_amd64",
+		URL:      "b.tar.gz",
+		manifest: config.Manifest{DriverInfo: config.DriverInfo{Name: "Renamed"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", doc.Drivers[0].Name)
+}
+
+func TestJSONIndex(t *testing.T) {
+	index, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
+	require.NoError(t, err)
+	jsonIndex := yamlToJSON(t, index)
+	want, err := decodeIndex(index, &Registry{})
+	require.NoError(t, err)
+
+	var (
+		mu        sync.Mutex
+		requested []string
+		accept    = map[string]string{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !strings.HasSuffix(r.URL.Path, ".sig") {
+			requested = append(requested, r.URL.Path)
+			accept[r.URL.Path] = r.Header.Get("Accept")
+		}
+		if r.URL.Path == "/index.json" {
+			w.Write(jsonIndex)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	cacheDir := t.TempDir()
+	newClient := func() *Client {
+		return &Client{
+			httpClient: http.DefaultClient,
+			registries: []Registry{{BaseURL: mustParseURL(srv.URL)}},
+			indexCache: indexCache{dir: cacheDir},
+		}
+	}
+
+	drivers, err := newClient().Search(t.Context(), "")
+	require.NoError(t, err)
+	assertSameDrivers(t, want, drivers)
+	assert.Equal(t, []string{"/index.yaml", "/index.json"}, requested)
+	assert.Equal(t, "application/json, application/yaml;q=0.9", accept["/index.yaml"])
+	assert.Equal(t, "application/json", accept["/index.json"])
+
+	// Having found index.json once, dbc asks for it first from then on.
+	requested = nil
+	drivers, err = newClient().Search(t.Context(), "")
+	require.NoError(t, err)
+	assertSameDrivers(t, want, drivers)
+	assert.Equal(t, []string{"/index.json"}, requested)
+
+	t.Run("neither", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(srv.Close)
+		c := &Client{httpClient: http.DefaultClient, registries: []Registry{{BaseURL: mustParseURL(srv.URL)}}}
+		_, err := c.Search(t.Context(), "")
+		assert.ErrorContains(t, err, "failed to fetch drivers: 404 Not Found")
+	})
+}
+
+func TestIndexNegotiation(t *testing.T) {
+	key, sign := newSigningKey(t)
+	trustSigningKey(t, key)
+
+	index, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
+	require.NoError(t, err)
+	jsonIndex := yamlToJSON(t, index)
+	want, err := decodeIndex(index, &Registry{})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			if strings.Contains(r.Header.Get("Accept"), "application/json") {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.Write(jsonIndex)
+				return
+			}
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write(index)
+		case "/index.yaml.sig":
+			w.Write(sign(index))
+		case "/index.json.sig":
+			w.Write(sign(jsonIndex))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := &Client{
+		httpClient: http.DefaultClient,
+		registries: []Registry{{BaseURL: mustParseURL(srv.URL), RequireSignature: true}},
+	}
+	drivers, err := c.Search(t.Context(), "")
+	require.NoError(t, err, "the negotiated JSON is checked against index.json.sig")
+	assertSameDrivers(t, want, drivers)
+}
+
+func TestJSONShardedIndex(t *testing.T) {
+	index, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
+	require.NoError(t, err)
+	summary, files, err := ShardIndex(index)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, jsonIndexFile), yamlToJSON(t, summary), 0o644))
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(strings.TrimSuffix(name, ".yaml")+".json"))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, yamlToJSON(t, data), 0o644))
+	}
+
+	c, err := NewClient(WithBaseURL(dir), WithIndexCacheDir(""), WithPackageCacheDir(""))
+	require.NoError(t, err)
+	drivers, err := c.Search(t.Context(), "")
+	require.NoError(t, err)
+	require.Len(t, drivers, len(files))
+	idx := slices.IndexFunc(drivers, func(d Driver) bool { return d.Path == "test-driver-1" })
+	require.NotEqual(t, -1, idx)
+	assert.True(t, drivers[idx].IsSummary())
+
+	full, err := c.LoadDriver(t.Context(), drivers[idx])
+	require.NoError(t, err)
+	pkg, err := full.GetPackage(nil, "linux_amd64", false)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(pkg.Path.String(), "test_driver_linux_amd64-1.1.0.tar.gz"), pkg.Path.String())
+
+	issues, _, err := c.LintRegistry(t.Context(), &c.Registries()[0])
+	require.NoError(t, err)
+	for _, issue := range issues {
+		assert.NotContains(t, issue.Message, "lists different versions")
+	}
+}
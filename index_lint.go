@@ -0,0 +1,348 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/columnar-tech/dbc/auth"
+)
+
+// IndexIssue is a problem found in a registry index by LintIndex or
+// LintRegistry. Version and Platform are empty for problems with a whole
+// driver or version.
+type IndexIssue struct {
+	Driver   string
+	Version  string
+	Platform string
+	Message  string
+}
+
+func (i IndexIssue) String() string {
+	where := strings.Join(slices.DeleteFunc([]string{i.Driver, i.Version, i.Platform},
+		func(s string) bool { return s == "" }), " ")
+	if where == "" {
+		return i.Message
+	}
+	return where + ": " + i.Message
+}
+
+// LintIndex checks an index.yaml document for entries dbc would reject or
+// misread: drivers without a path, versions that aren't valid semver,
+// drivers, versions or platforms listed more than once, and packages with a
+// malformed url, sha256 or size. The index.yaml of a sharded index is checked
+// as far as its summaries go; LintRegistry checks each driver's own file.
+// Issues are returned in document order; an error is only returned if data
+// isn't an index at all.
+func LintIndex(data []byte) ([]IndexIssue, error) {
+	doc, err := parseIndexDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []IndexIssue
+	switch doc.Layout {
+	case "":
+	case shardedLayout:
+		for i, d := range doc.Drivers {
+			if _, err := driverIndexFile(d.Path, indexExt(data)); err != nil && d.Path != "" {
+				issues = append(issues, IndexIssue{Driver: d.Path, Message: err.Error()})
+			}
+			doc.Drivers[i].PkgInfo = summaryPkgInfo(d.Versions)
+		}
+	default:
+		return []IndexIssue{{Message: fmt.Sprintf("unsupported layout %q", doc.Layout)}}, nil
+	}
+
+	paths := make(map[string]bool, len(doc.Drivers))
+	for _, d := range doc.Drivers {
+		paths[d.Path] = true
+	}
+
+	seenDrivers := make(map[string]bool, len(doc.Drivers))
+	for _, d := range doc.Drivers {
+		if d.Path != "" && seenDrivers[d.Path] {
+			issues = append(issues, IndexIssue{Driver: d.Path, Message: "driver is listed more than once"})
+		}
+		seenDrivers[d.Path] = true
+		issues = append(issues, lintDriver(d, paths)...)
+	}
+	return issues, nil
+}
+
+// summaryPkgInfo returns the versions of a sharded index.yaml as pkginfo
+// entries without URLs, digests or sizes, so they can be checked like those
+// of any other index.
+func summaryPkgInfo(versions []summaryVersion) []indexVersion {
+	out := make([]indexVersion, 0, len(versions))
+	for _, v := range versions {
+		iv := indexVersion{Version: v.Version, Yanked: v.Yanked, YankedReason: v.YankedReason}
+		for _, p := range v.Platforms {
+			iv.Packages = append(iv.Packages, indexPackage{Platform: p})
+		}
+		out = append(out, iv)
+	}
+	return out
+}
+
+// lintDriver checks one driver entry of an index. paths holds the path of
+// every driver in the index, for checking replaced_by.
+func lintDriver(d indexDriver, paths map[string]bool) []IndexIssue {
+	var issues []IndexIssue
+	report := func(version, platform, format string, args ...any) {
+		issues = append(issues, IndexIssue{
+			Driver:   d.Path,
+			Version:  version,
+			Platform: platform,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if d.Path == "" {
+		report("", "", "driver %q has no path", d.Name)
+	}
+	if d.ReplacedBy != "" && !paths[d.ReplacedBy] {
+		report("", "", "replaced_by names driver %q, which is not in the index", d.ReplacedBy)
+	}
+	if len(d.PkgInfo) == 0 {
+		report("", "", "driver has no versions")
+	}
+
+	var versions []*semver.Version
+	for _, v := range d.PkgInfo {
+		ver, err := semver.NewVersion(v.Version)
+		if err != nil {
+			report(v.Version, "", "invalid version: %s", err)
+		} else if slices.ContainsFunc(versions, ver.Equal) {
+			report(v.Version, "", "version is listed more than once")
+		} else {
+			versions = append(versions, ver)
+		}
+		if len(v.Packages) == 0 {
+			report(v.Version, "", "version has no packages")
+		}
+
+		var platforms []string
+		for _, p := range v.Packages {
+			switch {
+			case p.Platform == "":
+				report(v.Version, "", "package %q has no platform", p.URL)
+			case slices.Contains(platforms, p.Platform):
+				report(v.Version, p.Platform, "platform is listed more than once")
+			}
+			platforms = append(platforms, p.Platform)
+
+			if p.URL != "" {
+				if _, err := url.Parse(p.URL); err != nil {
+					report(v.Version, p.Platform, "invalid package URL: %s", err)
+				}
+			}
+			if p.SHA256 != "" {
+				if b, err := hex.DecodeString(p.SHA256); err != nil || len(b) != 32 {
+					report(v.Version, p.Platform, "sha256 %q is not a hex-encoded sha256 digest", p.SHA256)
+				}
+			}
+			if p.Size < 0 {
+				report(v.Version, p.Platform, "size %d is negative", p.Size)
+			}
+		}
+	}
+	return issues
+}
+
+// LintRegistry fetches the index of r, checking its signature as Search
+// would, and checks it with LintIndex. The file of each driver in a sharded
+// index is fetched and checked too. The index is always fetched from r,
+// bypassing the index cache and offline mode, so the result reflects what
+// the registry serves. It then checks that every package the index lists
+// can be fetched, without downloading it, and returns the drivers the index
+// lists so callers can inspect the packages themselves. No drivers are
+// returned if the index is too malformed to be read.
+func (c *Client) LintRegistry(ctx context.Context, r *Registry) ([]IndexIssue, []Driver, error) {
+	ctx = withUncachedIndex(ctx)
+	data, err := c.fetchIndex(ctx, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	issues, err := LintIndex(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	drivers, err := decodeIndex(data, r)
+	if err != nil {
+		// Whatever stopped the index from decoding, such as an invalid
+		// version, has already been reported.
+		if len(issues) == 0 {
+			return nil, nil, err
+		}
+		return issues, nil, nil
+	}
+
+	paths := make(map[string]bool, len(drivers))
+	for _, d := range drivers {
+		paths[d.Path] = true
+	}
+	loaded := drivers[:0:0]
+	for _, d := range drivers {
+		if d.IsSummary() {
+			full, driverIssues := c.lintDriverFile(ctx, d, paths)
+			issues = append(issues, driverIssues...)
+			if full == nil {
+				continue
+			}
+			d = *full
+		}
+		loaded = append(loaded, d)
+	}
+	drivers = loaded
+
+	for _, d := range drivers {
+		for _, v := range d.AllVersions() {
+			for _, p := range v.Packages {
+				pkg, err := d.GetPackage(v.Version, p.Platform, true)
+				if err == nil {
+					err = c.checkPackage(ctx, pkg)
+				}
+				if err != nil {
+					issues = append(issues, IndexIssue{
+						Driver:   d.Path,
+						Version:  v.Version.String(),
+						Platform: p.Platform,
+						Message:  err.Error(),
+					})
+				}
+			}
+		}
+	}
+	return issues, drivers, nil
+}
+
+// lintDriverFile fetches and checks the file of a sharded index listing the
+// driver summarized by d, including that it lists the same versions and
+// platforms as the summary. It returns the driver the file lists, or nil if
+// it can't be read.
+func (c *Client) lintDriverFile(ctx context.Context, d Driver, paths map[string]bool) (*Driver, []IndexIssue) {
+	report := func(format string, args ...any) IndexIssue {
+		return IndexIssue{Driver: d.Path, Message: fmt.Sprintf(format, args...)}
+	}
+
+	name, err := driverIndexFile(d.Path, d.shardExt)
+	if err != nil {
+		// Already reported by LintIndex.
+		return nil, nil
+	}
+	data, err := c.fetchIndexFile(ctx, d.Registry, name)
+	if err != nil {
+		return nil, []IndexIssue{report("%s", err)}
+	}
+
+	var entry indexDriver
+	if err := unmarshalIndex(data, &entry); err != nil {
+		return nil, []IndexIssue{report("failed to parse %s: %s", name, err)}
+	}
+	issues := lintDriver(entry, paths)
+	full, err := decodeDriverIndex(data, name, d)
+	if err != nil {
+		return nil, append(issues, report("%s", err))
+	}
+
+	listed := func(drv Driver) []string {
+		var out []string
+		for _, v := range drv.AllVersions() {
+			for _, p := range v.Packages {
+				out = append(out, v.Version.String()+" "+p.Platform)
+			}
+		}
+		slices.Sort(out)
+		return out
+	}
+	if !slices.Equal(listed(d), listed(full)) {
+		issues = append(issues, report("%s lists different versions or platforms than %s", name, indexFileFor(d.shardExt)))
+	}
+	return &full, issues
+}
+
+// checkPackage returns an error if pkg can't be fetched, without
+// downloading it: local packages are only opened, packages in OCI registries
+// only have their manifest fetched, and packages served over HTTP are asked
+// for their first byte. A size that differs from the index's is caught, but
+// the digest is only checked if an OCI manifest gives it.
+func (c *Client) checkPackage(ctx context.Context, pkg PkgInfo) error {
+	if pkg.Path == nil {
+		return fmt.Errorf("cannot download package for %s: no url set", pkg.Driver.Title)
+	}
+
+	if isFileURL(pkg.Path) {
+		f, size, err := openLocalPackage(pkg.Path)
+		if err != nil {
+			return err
+		}
+		f.Close()
+		return checkPackageSize(pkg, size)
+	}
+
+	ctx = auth.WithHTTPClient(ctx, c.registryClient(pkg.Driver.Registry))
+	if isOCIURL(pkg.Path) {
+		_, _, err := c.ociPackageLayer(ctx, pkg)
+		return err
+	}
+
+	rsp, err := c.makeRequest(ctx, pkg.Path.String(), http.Header{"Range": {"bytes=0-0"}})
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", pkg.Path, err)
+	}
+	defer rsp.Body.Close()
+
+	switch rsp.StatusCode {
+	case http.StatusPartialContent:
+		// Content-Range is "bytes 0-0/<size>", or "bytes 0-0/*" if the
+		// size isn't known.
+		_, total, _ := strings.Cut(rsp.Header.Get("Content-Range"), "/")
+		size, err := strconv.ParseInt(total, 10, 64)
+		if err != nil {
+			size = -1
+		}
+		return checkPackageSize(pkg, size)
+	case http.StatusOK:
+		// The server ignored the range. The body is closed unread.
+		return checkPackageSize(pkg, rsp.ContentLength)
+	default:
+		return fmt.Errorf("failed to download %s: %s", pkg.Path, rsp.Status)
+	}
+}
+
+// checkPackageSize returns a *DigestMismatchError if size, the length of
+// pkg's tarball or -1 if unknown, differs from the size the index lists.
+func checkPackageSize(pkg PkgInfo, size int64) error {
+	if size < 0 || pkg.Size <= 0 || size == pkg.Size {
+		return nil
+	}
+	return &DigestMismatchError{
+		URL:      pkg.Path.Redacted(),
+		Field:    "size",
+		Expected: strconv.FormatInt(pkg.Size, 10),
+		Actual:   strconv.FormatInt(size, 10),
+	}
+}
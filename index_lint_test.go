@@ -0,0 +1,186 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintIndex(t *testing.T) {
+	issues, err := dbc.LintIndex([]byte(`drivers:
+  - name: Good
+    path: good
+    pkginfo:
+      - version: v1.0.0
+        packages:
+          - platform: linux_amd64
+            url: good/1.0.0/good_linux_amd64-1.0.0.tar.gz
+            sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+  - name: Bad
+    path: bad
+    replaced_by: missing
+    pkginfo:
+      - version: not-a-version
+        packages:
+          - platform: linux_amd64
+      - version: v1.0.0
+        packages:
+          - platform: linux_amd64
+            url: "%zz"
+            sha256: abc
+            size: -1
+          - platform: linux_amd64
+      - version: 1.0.0
+        packages: []
+  - name: Good Again
+    path: good
+    pkginfo:
+      - version: v2.0.0
+        packages:
+          - url: nowhere.tar.gz
+`))
+	require.NoError(t, err)
+
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.String())
+	}
+	assert.Equal(t, []string{
+		`bad: replaced_by names driver "missing", which is not in the index`,
+		"bad not-a-version: invalid version: invalid semantic version",
+		`bad v1.0.0 linux_amd64: invalid package URL: parse "%zz": invalid URL escape "%zz"`,
+		`bad v1.0.0 linux_amd64: sha256 "abc" is not a hex-encoded sha256 digest`,
+		"bad v1.0.0 linux_amd64: size -1 is negative",
+		"bad v1.0.0 linux_amd64: platform is listed more than once",
+		"bad 1.0.0: version is listed more than once",
+		"bad 1.0.0: version has no packages",
+		"good: driver is listed more than once",
+		`good v2.0.0: package "nowhere.tar.gz" has no platform`,
+	}, got)
+
+	_, err = dbc.LintIndex([]byte("drivers: 42\n"))
+	assert.Error(t, err)
+}
+
+func TestLintRegistry(t *testing.T) {
+	dir := newLocalRegistry(t)
+
+	newClient := func(t *testing.T) *dbc.Client {
+		c, err := dbc.NewClient(dbc.WithBaseURL(dir), dbc.WithIndexCacheDir(""), dbc.WithPackageCacheDir(""))
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("clean", func(t *testing.T) {
+		c := newClient(t)
+		issues, drivers, err := c.LintRegistry(t.Context(), &c.Registries()[0])
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+		assert.Len(t, drivers, 2)
+	})
+
+	t.Run("missing package", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(filepath.Join(dir, "pkgs")))
+		c := newClient(t)
+		issues, _, err := c.LintRegistry(t.Context(), &c.Registries()[0])
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "test-driver-1", issues[0].Driver)
+		assert.Equal(t, "1.0.0", issues[0].Version)
+		assert.Equal(t, config.PlatformTuple(), issues[0].Platform)
+		assert.Contains(t, issues[0].Message, "package not found")
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), []byte(`drivers:
+  - name: Test Driver 1
+    path: test-driver-1
+    pkginfo:
+      - version: latest
+        packages:
+          - platform: linux_amd64
+`), 0o644))
+		c := newClient(t)
+		issues, drivers, err := c.LintRegistry(t.Context(), &c.Registries()[0])
+		require.NoError(t, err)
+		assert.Nil(t, drivers)
+		require.Len(t, issues, 1)
+		assert.Contains(t, issues[0].String(), "test-driver-1 latest: invalid version")
+	})
+
+	t.Run("missing index", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "index.yaml")))
+		c := newClient(t)
+		_, _, err := c.LintRegistry(t.Context(), &c.Registries()[0])
+		assert.Error(t, err)
+	})
+}
+
+func TestLintRegistryHTTP(t *testing.T) {
+	dir := newLocalRegistry(t)
+	index, err := os.ReadFile(filepath.Join(dir, "index.yaml"))
+	require.NoError(t, err)
+	// The listed size of test-driver-1 is wrong.
+	index = bytes.Replace(index, []byte("url: pkgs/test-driver-1.tar.gz\n"),
+		[]byte("url: pkgs/test-driver-1.tar.gz\n            size: 1\n"), 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), index, 0o644))
+
+	var mu sync.Mutex
+	ranges := map[string]string{}
+	files := http.FileServer(http.Dir(dir))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".tar.gz") {
+			mu.Lock()
+			ranges[r.URL.Path] = r.Header.Get("Range")
+			mu.Unlock()
+		}
+		files.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	// Neither offline mode nor a cached index changes what is linted.
+	cacheDir := t.TempDir()
+	c, err := dbc.NewClient(dbc.WithBaseURL(srv.URL), dbc.WithIndexCacheDir(cacheDir),
+		dbc.WithPackageCacheDir(""), dbc.WithOffline(true))
+	require.NoError(t, err)
+
+	issues, drivers, err := c.LintRegistry(t.Context(), &c.Registries()[0])
+	require.NoError(t, err)
+	assert.Len(t, drivers, 2)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "test-driver-1", issues[0].Driver)
+	assert.Contains(t, issues[0].Message, "expected size 1")
+
+	assert.Equal(t, map[string]string{
+		"/pkgs/test-driver-1.tar.gz": "bytes=0-0",
+		"/test-driver-2/2.0.0/test-driver-2_" + config.PlatformTuple() + "-2.0.0.tar.gz": "bytes=0-0",
+	}, ranges, "packages are probed, not downloaded")
+
+	cached, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	assert.Empty(t, cached, "the index is not cached")
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// indexFile is the index every registry publishes at its root.
+	indexFile = "index.yaml"
+	// shardedLayout is the layout of an index.yaml that only summarizes its
+	// drivers. Each driver is listed in full in its own file under
+	// driverIndexDir, so installing one driver doesn't mean fetching every
+	// version of every other.
+	shardedLayout  = "sharded"
+	driverIndexDir = "drivers"
+)
+
+// summaryVersion is a version of a driver as the index.yaml of a sharded
+// index lists it: enough for search to show, but without its packages.
+type summaryVersion struct {
+	Version      string   `yaml:"version" json:"version"`
+	Platforms    []string `yaml:"platforms,omitempty" json:"platforms,omitempty"`
+	Yanked       bool     `yaml:"yanked,omitempty" json:"yanked,omitempty"`
+	YankedReason string   `yaml:"yanked_reason,omitempty" json:"yanked_reason,omitempty"`
+}
+
+// driverSummary is a driver entry of an index.yaml, which in a sharded index
+// lists Versions rather than PkgInfo.
+type driverSummary struct {
+	Driver   `yaml:",inline"`
+	Versions []summaryVersion `yaml:"versions" json:"versions"`
+}
+
+// summarize returns the driver d summarizes, with a PkgInfo entry naming the
+// platforms of each of its versions. ext is the extension of the file that
+// lists it in full, matching the format of the index.
+func (d driverSummary) summarize(ext string) (Driver, error) {
+	drv := d.Driver
+	drv.shardExt = ext
+	drv.PkgInfo = make([]pkginfo, 0, len(d.Versions))
+	for _, v := range d.Versions {
+		ver, err := semver.NewVersion(v.Version)
+		if err != nil {
+			return Driver{}, fmt.Errorf("driver %s: invalid version %q: %w", d.Path, v.Version, err)
+		}
+		info := pkginfo{Version: ver, Yanked: v.Yanked, YankedReason: v.YankedReason}
+		for _, p := range v.Platforms {
+			info.Packages = append(info.Packages, pkgentry{PlatformTuple: p})
+		}
+		drv.PkgInfo = append(drv.PkgInfo, info)
+	}
+	return drv, nil
+}
+
+// summarize returns the versions of d as a sharded index.yaml lists them.
+func (d indexDriver) summarize() []summaryVersion {
+	versions := make([]summaryVersion, 0, len(d.PkgInfo))
+	for _, v := range d.PkgInfo {
+		sv := summaryVersion{Version: v.Version, Yanked: v.Yanked, YankedReason: v.YankedReason}
+		for _, p := range v.Packages {
+			sv.Platforms = append(sv.Platforms, p.Platform)
+		}
+		versions = append(versions, sv)
+	}
+	return versions
+}
+
+// driverIndexFile returns the file of a sharded index that lists the driver
+// at path in full, relative to the registry root. ext is the extension of the
+// index, as the driver files are written in the same format.
+func driverIndexFile(path, ext string) (string, error) {
+	if path == "" || path == "." || path == ".." || strings.ContainsAny(path, `/\`) {
+		return "", fmt.Errorf("driver path %q cannot be used in a sharded index", path)
+	}
+	return driverIndexDir + "/" + path + ext, nil
+}
+
+// LoadDriver returns d with every version and package its registry lists. A
+// driver listed by a sharded index is only a summary until its own file,
+// drivers/<path>.yaml, or drivers/<path>.json for a JSON index, is fetched;
+// that file is cached and its signature checked just like the index. Any
+// other driver is returned as it is.
+func (c *Client) LoadDriver(ctx context.Context, d Driver) (Driver, error) {
+	if !d.IsSummary() {
+		return d, nil
+	}
+	if d.Registry == nil {
+		return Driver{}, fmt.Errorf("cannot load driver %s: driver has no registry", d.Path)
+	}
+	name, err := driverIndexFile(d.Path, d.shardExt)
+	if err != nil {
+		return Driver{}, err
+	}
+
+	if timeout := c.timeoutFor(d.Registry); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	data, err := c.fetchIndexFile(ctx, d.Registry, name)
+	if err != nil {
+		return Driver{}, fmt.Errorf("failed to load driver %s: %w", d.Path, err)
+	}
+	return decodeDriverIndex(data, name, d)
+}
+
+// decodeDriverIndex parses data, the file name of a sharded index, as the
+// full entry of the driver summarized by d.
+func decodeDriverIndex(data []byte, name string, d Driver) (Driver, error) {
+	var full Driver
+	if err := unmarshalIndex(data, &full); err != nil {
+		return Driver{}, fmt.Errorf("failed to parse %s: %s", name, err)
+	}
+	if full.Path != d.Path {
+		return Driver{}, fmt.Errorf("%s lists driver %q rather than %s", name, full.Path, d.Path)
+	}
+	full.Registry = d.Registry
+	return full, nil
+}
+
+// ShardIndex splits index, a single-file index.yaml, into the index.yaml of a
+// sharded index and the files listing each of its drivers in full, keyed by
+// their slash-separated paths relative to the registry root, such as
+// drivers/flightsql.yaml. The files are always written as YAML.
+func ShardIndex(index []byte) ([]byte, map[string][]byte, error) {
+	doc, err := parseIndexDocument(index)
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc.Layout != "" {
+		return nil, nil, fmt.Errorf("index already has layout %q", doc.Layout)
+	}
+	if err := doc.sort(); err != nil {
+		return nil, nil, err
+	}
+
+	summary := indexDocument{Name: doc.Name, Layout: shardedLayout}
+	files := make(map[string][]byte, len(doc.Drivers))
+	for _, d := range doc.Drivers {
+		name, err := driverIndexFile(d.Path, ".yaml")
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := files[name]; ok {
+			return nil, nil, fmt.Errorf("driver %s is listed more than once", d.Path)
+		}
+		if files[name], err = encodeIndexYAML(d); err != nil {
+			return nil, nil, err
+		}
+
+		d.Versions, d.PkgInfo = d.summarize(), nil
+		summary.Drivers = append(summary.Drivers, d)
+	}
+
+	out, err := summary.encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, files, nil
+}
+
+// JoinIndex returns the single-file index.yaml equivalent to index, the
+// index.yaml or index.json of a sharded index, reading the file listing each
+// driver in full with read. read is given paths relative to the registry
+// root, such as drivers/flightsql.yaml, or drivers/flightsql.json for a JSON
+// index. An index that isn't sharded is returned as it is.
+func JoinIndex(index []byte, read func(name string) ([]byte, error)) ([]byte, error) {
+	doc, err := parseIndexDocument(index)
+	if err != nil {
+		return nil, err
+	}
+	switch doc.Layout {
+	case "":
+		return index, nil
+	case shardedLayout:
+	default:
+		return nil, fmt.Errorf("unsupported index layout %q", doc.Layout)
+	}
+
+	joined := indexDocument{Name: doc.Name}
+	for _, d := range doc.Drivers {
+		name, err := driverIndexFile(d.Path, indexExt(index))
+		if err != nil {
+			return nil, err
+		}
+		data, err := read(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		var full indexDriver
+		if err := unmarshalIndex(data, &full); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if full.Path != d.Path {
+			return nil, fmt.Errorf("%s lists driver %q rather than %s", name, full.Path, d.Path)
+		}
+		joined.Drivers = append(joined.Drivers, full)
+	}
+	return joined.encode()
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shardRegistry rewrites the index.yaml of the registry in dir as a sharded
+// index.
+func shardRegistry(t *testing.T, dir string) {
+	t.Helper()
+	index, err := os.ReadFile(filepath.Join(dir, "index.yaml"))
+	require.NoError(t, err)
+	summary, files, err := dbc.ShardIndex(index)
+	require.NoError(t, err)
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, data, 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), summary, 0o644))
+}
+
+func TestShardIndex(t *testing.T) {
+	index, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
+	require.NoError(t, err)
+
+	summary, files, err := dbc.ShardIndex(index)
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "layout: sharded")
+	assert.NotContains(t, string(summary), "pkginfo")
+	assert.NotContains(t, string(summary), ".tar.gz")
+	assert.Contains(t, files, "drivers/test-driver-1.yaml")
+	assert.Contains(t, string(files["drivers/test-driver-1.yaml"]), "test_driver_linux_amd64-1.1.0.tar.gz")
+
+	joined, err := dbc.JoinIndex(summary, func(name string) ([]byte, error) {
+		data, ok := files[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return data, nil
+	})
+	require.NoError(t, err)
+	original, rejoined := decodeBuiltIndex(t, index), decodeBuiltIndex(t, joined)
+	require.Len(t, rejoined, len(original))
+	for _, d := range original {
+		idx := slices.IndexFunc(rejoined, func(r dbc.Driver) bool { return r.Path == d.Path })
+		require.NotEqual(t, -1, idx, d.Path)
+		assert.Equal(t, d.AllVersions(), rejoined[idx].AllVersions())
+	}
+
+	t.Run("not sharded", func(t *testing.T) {
+		out, err := dbc.JoinIndex(index, nil)
+		require.NoError(t, err)
+		assert.Equal(t, index, out)
+	})
+
+	t.Run("already sharded", func(t *testing.T) {
+		_, _, err := dbc.ShardIndex(summary)
+		assert.ErrorContains(t, err, `already has layout "sharded"`)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		_, _, err := dbc.ShardIndex([]byte("drivers:\n  - name: Bad\n    path: ../bad\n"))
+		assert.ErrorContains(t, err, `driver path "../bad" cannot be used in a sharded index`)
+	})
+}
+
+func TestShardedRegistry(t *testing.T) {
+	index, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
+	require.NoError(t, err)
+	summary, files, err := dbc.ShardIndex(index)
+	require.NoError(t, err)
+
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/index.yaml" {
+			w.Write(summary)
+			return
+		}
+		if data, ok := files[strings.TrimPrefix(r.URL.Path, "/")]; ok {
+			w.Write(data)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	fetched := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.DeleteFunc(slices.Clone(requested), func(p string) bool { return strings.HasSuffix(p, ".sig") })
+	}
+
+	cacheDir := t.TempDir()
+	newClient := func(opts ...dbc.Option) *dbc.Client {
+		c, err := dbc.NewClient(append([]dbc.Option{
+			dbc.WithHTTPClient(&http.Client{}),
+			dbc.WithBaseURL(srv.URL),
+			dbc.WithIndexCacheDir(cacheDir),
+		}, opts...)...)
+		require.NoError(t, err)
+		return c
+	}
+
+	c := newClient()
+	drivers, err := c.Search(t.Context(), "")
+	require.NoError(t, err)
+	require.Len(t, drivers, len(files))
+	assert.Equal(t, []string{"/index.yaml"}, fetched())
+
+	summarized := drivers[0]
+	assert.True(t, summarized.IsSummary())
+	assert.Equal(t, "This is a test driver", summarized.Desc)
+	assert.Len(t, summarized.Versions("linux_amd64"), 2)
+	_, err = summarized.GetPackage(nil, "linux_amd64", false)
+	assert.ErrorContains(t, err, "only its summary has been loaded")
+
+	full, err := c.LoadDriver(t.Context(), summarized)
+	require.NoError(t, err)
+	assert.False(t, full.IsSummary())
+	assert.Equal(t, []string{"/index.yaml", "/drivers/test-driver-1.yaml"}, fetched())
+	pkg, err := full.GetPackage(nil, "linux_amd64", false)
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/test-driver-1/1.1.0/test_driver_linux_amd64-1.1.0.tar.gz", pkg.Path.String())
+
+	same, err := c.LoadDriver(t.Context(), full)
+	require.NoError(t, err)
+	assert.Equal(t, full, same)
+
+	t.Run("offline", func(t *testing.T) {
+		offline := newClient(dbc.WithOffline(true))
+		drivers, err := offline.Search(t.Context(), "")
+		require.NoError(t, err)
+		_, err = offline.LoadDriver(t.Context(), drivers[0])
+		require.NoError(t, err)
+		_, err = offline.LoadDriver(t.Context(), drivers[1])
+		assert.ErrorIs(t, err, dbc.ErrNotCached)
+	})
+}
+
+func TestLintRegistrySharded(t *testing.T) {
+	dir := newLocalRegistry(t)
+	shardRegistry(t, dir)
+
+	lint := func(t *testing.T) ([]dbc.IndexIssue, []dbc.Driver) {
+		c, err := dbc.NewClient(dbc.WithBaseURL(dir), dbc.WithIndexCacheDir(""), dbc.WithPackageCacheDir(""))
+		require.NoError(t, err)
+		issues, drivers, err := c.LintRegistry(t.Context(), &c.Registries()[0])
+		require.NoError(t, err)
+		return issues, drivers
+	}
+
+	issues, drivers := lint(t)
+	assert.Empty(t, issues)
+	require.Len(t, drivers, 2)
+	assert.False(t, drivers[0].IsSummary())
+
+	t.Run("mismatch", func(t *testing.T) {
+		p := filepath.Join(dir, "drivers", "test-driver-2.yaml")
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(p, []byte(strings.ReplaceAll(string(data), config.PlatformTuple(), "other_arch")), 0o644))
+
+		issues, _ := lint(t)
+		require.NotEmpty(t, issues)
+		assert.Equal(t, "test-driver-2: drivers/test-driver-2.yaml lists different versions or platforms than index.yaml", issues[0].String())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "drivers", "test-driver-1.yaml")))
+		issues, drivers := lint(t)
+		require.NotEmpty(t, issues)
+		assert.Equal(t, "test-driver-1", issues[0].Driver)
+		assert.False(t, slices.ContainsFunc(drivers, func(d dbc.Driver) bool { return d.Path == "test-driver-1" }))
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// indexSignatureFile is the detached OpenPGP signature a registry may publish
+// next to its index.yaml.
+const indexSignatureFile = indexFile + ".sig"
+
+// checkIndexSignature verifies data, the index of r, against its detached
+// signature sig. A nil sig means the registry does not publish one, which is
+// only an error if r requires a signed index or wasSigned reports that the
+// copy of the index cached earlier was signed: a registry that signed its
+// index once must not be able to stop without anyone noticing.
+func checkIndexSignature(r *Registry, data, sig []byte, wasSigned bool) error {
+	if sig == nil {
+		if r.RequireSignature || wasSigned {
+			return ErrUnsignedIndex
+		}
+		return nil
+	}
+
+	verifier, err := r.verifier()
+	if err != nil {
+		return err
+	}
+	if err := verifyDetached(verifier, bytes.NewReader(data), bytes.NewReader(sig)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidIndexSignature, err)
+	}
+	return nil
+}
+
+// readLocalIndexSignature reads the signature of the index file name of a
+// file:// registry, returning nil if there is none.
+func readLocalIndexSignature(base *url.URL, name string) ([]byte, error) {
+	sig, err := os.ReadFile(filepath.Join(localPath(base), filepath.FromSlash(name)+".sig"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index signature: %w", err)
+	}
+	return sig, nil
+}
+
+// fetchIndexSignature downloads the signature of the index file name of r,
+// returning nil if the registry does not publish one. A 404 means
+// "unsigned", and so does a 403, which S3 and CloudFront return for missing
+// objects, unless r has signing keys configured or requires a signature.
+// Any other failure is an error, since treating it as unsigned would let an
+// attacker who can block the signature strip it.
+func (c *Client) fetchIndexSignature(ctx context.Context, r *Registry, name string) ([]byte, error) {
+	resp, err := c.makeRequest(ctx, r.BaseURL.JoinPath(name+".sig").String(), nil)
+	var se *statusError
+	if errors.As(err, &se) && se.code == http.StatusForbidden &&
+		len(r.SigningKeys) == 0 && !r.RequireSignature {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index signature: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		sig, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch index signature: %w", err)
+		}
+		return sig, nil
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to fetch index signature: %s", resp.Status)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const signedTestIndex = `name: signed
+drivers:
+  - name: Test Driver 1
+    path: test-driver-1
+`
+
+// newSigningKey generates an OpenPGP key and returns a function producing
+// detached signatures with it.
+func newSigningKey(t *testing.T) (*crypto.Key, func(data []byte) []byte) {
+	t.Helper()
+	pgp := crypto.PGP()
+	key, err := pgp.KeyGeneration().AddUserId("dbc test", "test@example.com").New().GenerateKey()
+	require.NoError(t, err)
+
+	return key, func(data []byte) []byte {
+		signer, err := pgp.Sign().SigningKey(key).Detached().New()
+		require.NoError(t, err)
+		sig, err := signer.Sign(data, crypto.Armor)
+		require.NoError(t, err)
+		return sig
+	}
+}
+
+// trustSigningKey makes key the key index signatures are verified against
+// for the duration of the test.
+func trustSigningKey(t *testing.T, key *crypto.Key) {
+	t.Helper()
+	pub, err := key.ToPublic()
+	require.NoError(t, err)
+	verifier, err := crypto.PGP().Verify().VerificationKey(pub).New()
+	require.NoError(t, err)
+
+	orig := getVerifier
+	getVerifier = func() (crypto.PGPVerify, error) { return verifier, nil }
+	t.Cleanup(func() { getVerifier = orig })
+}
+
+func TestIndexSignature(t *testing.T) {
+	key, sign := newSigningKey(t)
+	trustSigningKey(t, key)
+	_, signOther := newSigningKey(t)
+	armored, err := key.GetArmoredPublicKey()
+	require.NoError(t, err)
+
+	index := []byte(signedTestIndex)
+	tampered := []byte(signedTestIndex + "  - name: Evil Driver\n    path: evil\n")
+
+	newServer := func(t *testing.T, index, sig []byte, sigStatus int) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/index.yaml":
+				w.Write(index)
+			case "/index.yaml.sig":
+				if sig == nil {
+					w.WriteHeader(sigStatus)
+					return
+				}
+				w.Write(sig)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	tests := []struct {
+		name      string
+		index     []byte
+		sig       []byte
+		sigStatus int
+		require   bool
+		keys      []string
+		wantErr   error
+		errMsg    string
+	}{
+		{name: "valid signature", index: index, sig: sign(index), require: true},
+		{name: "tampered index", index: tampered, sig: sign(index), wantErr: ErrInvalidIndexSignature},
+		{name: "untrusted key", index: index, sig: signOther(index), wantErr: ErrInvalidIndexSignature},
+		{name: "garbage signature", index: index, sig: []byte("not a signature"), wantErr: ErrInvalidIndexSignature},
+		{name: "unsigned", index: index, sigStatus: http.StatusNotFound},
+		{name: "signature forbidden", index: index, sigStatus: http.StatusForbidden},
+		{name: "signature forbidden with keys", index: index, sigStatus: http.StatusForbidden, keys: []string{armored}, errMsg: "failed to fetch index signature"},
+		{name: "signature forbidden but required", index: index, sigStatus: http.StatusForbidden, require: true, errMsg: "failed to fetch index signature"},
+		{name: "unsigned but required", index: index, sigStatus: http.StatusNotFound, require: true, wantErr: ErrUnsignedIndex},
+		{name: "signature unavailable", index: index, sigStatus: http.StatusInternalServerError, errMsg: "failed to fetch index signature: 500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, tt.index, tt.sig, tt.sigStatus)
+			c := &Client{
+				httpClient: http.DefaultClient,
+				registries: []Registry{{BaseURL: mustParseURL(srv.URL), RequireSignature: tt.require, SigningKeys: tt.keys}},
+			}
+			drivers, err := c.Search(t.Context(), "")
+			switch {
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, drivers)
+			case tt.errMsg != "":
+				require.ErrorContains(t, err, tt.errMsg)
+			default:
+				require.NoError(t, err)
+				require.Len(t, drivers, 1)
+				assert.Equal(t, "test-driver-1", drivers[0].Path)
+			}
+		})
+	}
+}
+
+func TestIndexSignatureCached(t *testing.T) {
+	key, sign := newSigningKey(t)
+	trustSigningKey(t, key)
+
+	index := []byte(signedTestIndex)
+	var signed, downgraded atomic.Bool
+	var sigFetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			if downgraded.Load() {
+				w.Header().Set("ETag", `"v2"`)
+				w.Write(index)
+				return
+			}
+			if r.Header.Get("If-None-Match") != "" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write(index)
+		case "/index.yaml.sig":
+			sigFetches.Add(1)
+			if !signed.Load() || downgraded.Load() {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(sign(index))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	cacheDir := t.TempDir()
+	newClient := func(offline, require bool) *Client {
+		return &Client{
+			httpClient: http.DefaultClient,
+			registries: []Registry{{BaseURL: mustParseURL(srv.URL), RequireSignature: require}},
+			indexCache: indexCache{dir: cacheDir},
+			offline:    offline,
+		}
+	}
+
+	_, err := newClient(false, false).Search(t.Context(), "")
+	require.NoError(t, err)
+	_, meta, ok := indexCache{dir: cacheDir}.load(mustParseURL(srv.URL))
+	require.True(t, ok)
+	assert.False(t, meta.Signed)
+
+	fetches := sigFetches.Load()
+	_, err = newClient(false, false).Search(t.Context(), "")
+	require.NoError(t, err)
+	assert.Equal(t, fetches, sigFetches.Load(), "an unsigned cached index is not probed for a signature on 304")
+
+	_, err = newClient(true, true).Search(t.Context(), "")
+	require.ErrorIs(t, err, ErrUnsignedIndex, "an unsigned cached index is rejected offline")
+
+	// The registry starts signing without changing its index: the 304 path
+	// picks up the signature and records it.
+	signed.Store(true)
+	_, err = newClient(false, true).Search(t.Context(), "")
+	require.NoError(t, err)
+	_, meta, ok = indexCache{dir: cacheDir}.load(mustParseURL(srv.URL))
+	require.True(t, ok)
+	assert.True(t, meta.Signed)
+
+	fetches = sigFetches.Load()
+	_, err = newClient(false, true).Search(t.Context(), "")
+	require.NoError(t, err)
+	assert.Equal(t, fetches, sigFetches.Load(), "a signed cached index is not re-verified on 304")
+
+	_, err = newClient(true, true).Search(t.Context(), "")
+	require.NoError(t, err)
+
+	// The registry serves a changed index without a signature: once signed,
+	// an index stays signed even if the client doesn't require it.
+	downgraded.Store(true)
+	_, err = newClient(false, false).Search(t.Context(), "")
+	require.ErrorIs(t, err, ErrUnsignedIndex)
+	_, meta, ok = indexCache{dir: cacheDir}.load(mustParseURL(srv.URL))
+	require.True(t, ok)
+	assert.True(t, meta.Signed, "the signed cache entry is not replaced")
+}
+
+func TestIndexSignatureLocalRegistry(t *testing.T) {
+	key, sign := newSigningKey(t)
+	trustSigningKey(t, key)
+
+	dir := t.TempDir()
+	index := []byte(signedTestIndex)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), index, 0o644))
+
+	search := func(require bool) error {
+		c := &Client{registries: []Registry{{BaseURL: mustParseURL("file://" + filepath.ToSlash(dir)), RequireSignature: require}}}
+		_, err := c.Search(t.Context(), "")
+		return err
+	}
+
+	assert.NoError(t, search(false))
+	assert.ErrorIs(t, search(true), ErrUnsignedIndex)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, indexSignatureFile), sign(index), 0o644))
+	assert.NoError(t, search(true))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), append(index, '#'), 0o644))
+	assert.ErrorIs(t, search(false), ErrInvalidIndexSignature)
+}
@@ -64,7 +64,7 @@ type InstallStatus struct {
 // until install.complete is received.
 type InstallProgressEvent struct {
 	// Event identifies the progress step. Valid values:
-	// "download.start", "download.progress", "download.complete",
+	// "download.start", "download.progress", "download.retry", "download.complete",
 	// "extract.start", "extract.complete",
 	// "verify.start", "verify.complete", "verify.checksum.ok", "verify.checksum.mismatch",
 	// "manifest.create", "install.complete".
@@ -77,6 +77,23 @@ type InstallProgressEvent struct {
 	Total int64 `json:"total,omitempty"`
 	// Checksum is the computed or expected checksum value (verify events only).
 	Checksum string `json:"checksum,omitempty"`
+	// Retry describes the failed request being retried (download.retry only).
+	Retry RetryInfo `json:"retry,omitzero"`
+}
+
+// RetryInfo describes a download request that failed and is being retried.
+type RetryInfo struct {
+	// Attempt is the number of this retry, starting at 1.
+	Attempt int `json:"attempt"`
+	// MaxAttempts is the most retries that will be made.
+	MaxAttempts int `json:"max_attempts"`
+	// WaitMs is how long dbc waits before retrying, in milliseconds.
+	WaitMs int64 `json:"wait_ms"`
+	// Offset is the byte offset the download resumes from, or 0 if it
+	// starts again from the beginning.
+	Offset int64 `json:"offset,omitempty"`
+	// Error describes why the request failed.
+	Error string `json:"error"`
 }
 
 // -----------------------------------------------------------------------------
@@ -126,6 +143,41 @@ type SearchDriverVerbose struct {
 	AvailableVersions []string `json:"available_versions,omitempty"`
 }
 
+// DriverSource is one registry publishing a driver.
+type DriverSource struct {
+	// Registry is the registry's configured name, if it has one.
+	Registry string `json:"registry,omitempty"`
+	// URL is the registry's URL, without credentials.
+	URL string `json:"url"`
+	// Versions lists the versions the registry offers for the current platform.
+	Versions []string `json:"versions"`
+	// Selected is true for the source an unqualified driver name resolves to.
+	Selected bool `json:"selected,omitempty"`
+}
+
+// DriverWarning flags a driver that its registry no longer recommends: one
+// that is deprecated, or an installed or locked version that was yanked.
+type DriverWarning struct {
+	// Driver is the driver identifier path.
+	Driver string `json:"driver"`
+	// Kind is "deprecated" or "yanked".
+	Kind string `json:"kind"`
+	// Version is the yanked version; empty for deprecated drivers.
+	Version string `json:"version,omitempty"`
+	// Reason is the explanation given by the registry, if any.
+	Reason string `json:"reason,omitempty"`
+	// ReplacedBy names the driver to use instead of a deprecated one, if any.
+	ReplacedBy string `json:"replaced_by,omitempty"`
+}
+
+// DriverConflict reports a driver name published by more than one registry.
+type DriverConflict struct {
+	// Driver is the driver identifier path.
+	Driver string `json:"driver"`
+	// Sources lists every registry publishing the driver, in priority order.
+	Sources []DriverSource `json:"sources"`
+}
+
 // SearchResponse is the top-level JSON payload for the search command. The
 // drivers field is raw JSON to accommodate both SearchDriverBasic and
 // SearchDriverVerbose slices without a common interface.
@@ -134,6 +186,11 @@ type SearchResponse struct {
 	Drivers json.RawMessage `json:"drivers"`
 	// Warning is an optional message when some registries were unavailable.
 	Warning string `json:"warning,omitempty"`
+	// Conflicts lists matched drivers published by more than one registry.
+	Conflicts []DriverConflict `json:"conflicts,omitempty"`
+	// Warnings lists matched drivers that are deprecated or whose installed
+	// version was yanked.
+	Warnings []DriverWarning `json:"warnings,omitempty"`
 }
 
 // -----------------------------------------------------------------------------
@@ -155,6 +212,12 @@ type DriverInfo struct {
 	Description string `json:"description"`
 	// Packages lists the supported platform tuples.
 	Packages []string `json:"packages"`
+	// Sources lists every registry publishing the driver when there is more
+	// than one.
+	Sources []DriverSource `json:"sources,omitempty"`
+	// Warnings reports whether the driver is deprecated or an installed
+	// version was yanked.
+	Warnings []DriverWarning `json:"warnings,omitempty"`
 }
 
 // -----------------------------------------------------------------------------
@@ -197,6 +260,8 @@ type InitResponse struct {
 type AddResponseDriver struct {
 	// Name is the driver identifier.
 	Name string `json:"name"`
+	// Registry is the name or URL of the registry the driver is pinned to, if any.
+	Registry string `json:"registry,omitempty"`
 	// VersionConstraint is the optional semver constraint that was recorded.
 	VersionConstraint string `json:"version_constraint,omitempty"`
 }
@@ -229,7 +294,7 @@ type RemoveResponse struct {
 
 // SyncProgressEvent is a single NDJSON line in the sync progress stream.
 type SyncProgressEvent struct {
-	// Phase is the current sync step: "resolving", "downloading", "verifying", or "installed".
+	// Phase is the current sync step: "resolving", "downloading", "retrying", "verifying", or "installed".
 	Phase string `json:"phase"`
 	// Driver is the driver identifier being synced.
 	Driver string `json:"driver"`
@@ -239,6 +304,9 @@ type SyncProgressEvent struct {
 	Total int64 `json:"total,omitempty"`
 	// Version is the resolved version string (available after resolving phase).
 	Version string `json:"version,omitempty"`
+	// Retry describes the failed download request being retried (retrying
+	// phase only).
+	Retry RetryInfo `json:"retry,omitzero"`
 }
 
 // SyncedDriver records a driver that was successfully installed or skipped during sync.
@@ -265,6 +333,86 @@ type SyncStatus struct {
 	Skipped []SyncedDriver `json:"skipped"`
 	// Errors lists drivers that failed to install.
 	Errors []SyncError `json:"errors"`
+	// Warnings lists synced drivers that are deprecated or whose locked
+	// version was yanked.
+	Warnings []DriverWarning `json:"warnings,omitempty"`
+}
+
+// -----------------------------------------------------------------------------
+// Upgrade
+// -----------------------------------------------------------------------------
+
+// UpgradedDriver records a driver whose locked version was changed by upgrade.
+type UpgradedDriver struct {
+	// Name is the driver identifier.
+	Name string `json:"name"`
+	// From is the previously locked version, or empty if the driver was not locked.
+	From string `json:"from,omitempty"`
+	// To is the newest version the driver list allows.
+	To string `json:"to"`
+}
+
+// UpgradeStatus is the final JSON payload emitted after an upgrade completes.
+type UpgradeStatus struct {
+	// DryRun is true if nothing was installed and the lockfile was left unchanged.
+	DryRun bool `json:"dry_run"`
+	// Upgraded lists drivers whose locked version changed.
+	Upgraded []UpgradedDriver `json:"upgraded"`
+	// Unchanged lists drivers already locked at the newest allowed version.
+	Unchanged []SyncedDriver `json:"unchanged"`
+	// Warnings lists upgraded drivers that are deprecated or whose version
+	// was yanked.
+	Warnings []DriverWarning `json:"warnings,omitempty"`
+}
+
+// -----------------------------------------------------------------------------
+// Lock
+// -----------------------------------------------------------------------------
+
+// LockStatus is the final JSON payload emitted after `dbc lock` writes the
+// lockfile.
+type LockStatus struct {
+	// Lockfile is the path of the lockfile that was written.
+	Lockfile string `json:"lockfile"`
+	// Locked lists every driver in the lockfile with its locked version.
+	Locked []SyncedDriver `json:"locked"`
+	// Warnings lists locked drivers that are deprecated or whose locked
+	// version was yanked.
+	Warnings []DriverWarning `json:"warnings,omitempty"`
+}
+
+// -----------------------------------------------------------------------------
+// Outdated
+// -----------------------------------------------------------------------------
+
+// OutdatedDriver compares an installed or locked driver with the versions its
+// registry publishes.
+type OutdatedDriver struct {
+	// Driver is the driver identifier.
+	Driver string `json:"driver"`
+	// Source is where the driver was found: "lockfile", or the config level
+	// ("user", "system", "env") it is installed at.
+	Source string `json:"source"`
+	// Location is the lockfile or driver manifest the driver was found in.
+	Location string `json:"location"`
+	// Current is the installed or locked version.
+	Current string `json:"current"`
+	// Wanted is the newest version the driver list's constraints allow. It
+	// equals Latest for drivers without constraints.
+	Wanted string `json:"wanted"`
+	// Latest is the newest version the registry publishes.
+	Latest string `json:"latest"`
+	// Outdated is true if Current is older than Wanted or Latest.
+	Outdated bool `json:"outdated"`
+	// Error explains why the driver couldn't be checked, e.g. because no
+	// version that isn't yanked has a package for this platform. Wanted and
+	// Latest are empty when it is set, unless noted otherwise.
+	Error string `json:"error,omitempty"`
+}
+
+// OutdatedResponse is the JSON payload emitted by `dbc outdated`.
+type OutdatedResponse struct {
+	Drivers []OutdatedDriver `json:"drivers"`
 }
 
 // -----------------------------------------------------------------------------
@@ -331,6 +479,70 @@ type AuthStatus struct {
 	Registries []AuthRegistryStatus `json:"registries"`
 }
 
+// -----------------------------------------------------------------------------
+// Registry (publishing tools)
+// -----------------------------------------------------------------------------
+
+// RegistryPackage is a driver package found while building a registry index,
+// or copied into a mirror.
+type RegistryPackage struct {
+	// Driver is the driver path (short name) the package was filed under.
+	Driver string `json:"driver"`
+	// Version is the driver version from the package's MANIFEST.
+	Version string `json:"version"`
+	// Platform is the platform tuple, e.g. "linux_amd64".
+	Platform string `json:"platform"`
+	// URL is the package URL relative to the registry root.
+	URL string `json:"url"`
+	// SHA256 is the hex-encoded sha256 digest recorded in the index.
+	SHA256 string `json:"sha256,omitempty"`
+	// Size is the package size in bytes recorded in the index.
+	Size int64 `json:"size,omitempty"`
+	// New is true if the package was not listed in the previous index, or,
+	// for a mirror, was downloaded by this run.
+	New bool `json:"new"`
+}
+
+// RegistryBuildResponse is the JSON payload emitted by `dbc registry build`.
+type RegistryBuildResponse struct {
+	// IndexPath is the filesystem path of the written index.
+	IndexPath string `json:"index_path"`
+	// Packages lists every package found on disk.
+	Packages []RegistryPackage `json:"packages"`
+}
+
+// MirrorResponse is the JSON payload emitted by `dbc mirror`.
+type MirrorResponse struct {
+	// IndexPath is the filesystem path of the mirror's index.
+	IndexPath string `json:"index_path"`
+	// Packages lists every package selected for the mirror.
+	Packages []RegistryPackage `json:"packages"`
+}
+
+// RegistryIssue is a problem found by `dbc registry lint`.
+type RegistryIssue struct {
+	// Driver is the driver identifier path.
+	Driver string `json:"driver"`
+	// Version is the version the problem concerns, if any.
+	Version string `json:"version,omitempty"`
+	// Platform is the platform of the package the problem concerns, if any.
+	Platform string `json:"platform,omitempty"`
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// RegistryLintResponse is the JSON payload emitted by `dbc registry lint`.
+type RegistryLintResponse struct {
+	// Registry is the location of the registry that was checked.
+	Registry string `json:"registry"`
+	// Packages is the number of packages the index lists.
+	Packages int `json:"packages"`
+	// Deep is true if every package was also downloaded and inspected.
+	Deep bool `json:"deep"`
+	// Issues lists every problem found; it is empty for a clean registry.
+	Issues []RegistryIssue `json:"issues"`
+}
+
 // -----------------------------------------------------------------------------
 // Error
 // -----------------------------------------------------------------------------
@@ -16,6 +16,7 @@ package jsonschema_test
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/columnar-tech/dbc/internal/jsonschema"
@@ -143,6 +144,20 @@ func TestInstallProgressEvent(t *testing.T) {
 				Total:  1024,
 			},
 		},
+		{
+			name: "download retry",
+			in: jsonschema.InstallProgressEvent{
+				Event:  "download.retry",
+				Driver: "snowflake",
+				Retry: jsonschema.RetryInfo{
+					Attempt:     1,
+					MaxAttempts: 3,
+					WaitMs:      500,
+					Offset:      4096,
+					Error:       "unexpected EOF",
+				},
+			},
+		},
 		{
 			name: "verify checksum ok",
 			in: jsonschema.InstallProgressEvent{
@@ -392,6 +407,12 @@ func TestSyncProgressEvent(t *testing.T) {
 			name: "resolving no optional",
 			in:   jsonschema.SyncProgressEvent{Phase: "resolving", Driver: "sqlite"},
 		},
+		{
+			name: "retrying",
+			in: jsonschema.SyncProgressEvent{Phase: "retrying", Driver: "duckdb", Retry: jsonschema.RetryInfo{
+				Attempt: 2, MaxAttempts: 3, WaitMs: 1000, Error: "server responded 503 Service Unavailable",
+			}},
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -453,6 +474,63 @@ func TestSyncStatus_EmptySlices(t *testing.T) {
 	}
 }
 
+func TestUpgradeStatus(t *testing.T) {
+	v := jsonschema.UpgradeStatus{
+		DryRun:    true,
+		Upgraded:  []jsonschema.UpgradedDriver{{Name: "snowflake", From: "1.0.0", To: "1.1.0"}, {Name: "sqlite", To: "2.0.0"}},
+		Unchanged: []jsonschema.SyncedDriver{{Name: "duckdb", Version: "2.0.0"}},
+	}
+	got := roundTrip(t, v)
+	if got.DryRun != v.DryRun {
+		t.Errorf("DryRun mismatch")
+	}
+	if len(got.Upgraded) != 2 || got.Upgraded[0] != v.Upgraded[0] || got.Upgraded[1] != v.Upgraded[1] {
+		t.Errorf("Upgraded mismatch: %+v", got.Upgraded)
+	}
+	if len(got.Unchanged) != 1 || got.Unchanged[0] != v.Unchanged[0] {
+		t.Errorf("Unchanged mismatch: %+v", got.Unchanged)
+	}
+
+	b, _ := json.Marshal(jsonschema.UpgradedDriver{Name: "sqlite", To: "2.0.0"})
+	if strings.Contains(string(b), "from") {
+		t.Errorf("empty From should be omitted: %s", b)
+	}
+}
+
+func TestLockStatus(t *testing.T) {
+	v := jsonschema.LockStatus{
+		Lockfile: "/project/dbc.lock",
+		Locked:   []jsonschema.SyncedDriver{{Name: "snowflake", Version: "1.1.0"}},
+	}
+	got := roundTrip(t, v)
+	if got.Lockfile != v.Lockfile {
+		t.Errorf("Lockfile mismatch: %q", got.Lockfile)
+	}
+	if len(got.Locked) != 1 || got.Locked[0] != v.Locked[0] {
+		t.Errorf("Locked mismatch: %+v", got.Locked)
+	}
+}
+
+func TestOutdatedResponse(t *testing.T) {
+	v := jsonschema.OutdatedResponse{Drivers: []jsonschema.OutdatedDriver{{
+		Driver:   "snowflake",
+		Source:   "lockfile",
+		Location: "/project/dbc.lock",
+		Current:  "1.0.0",
+		Wanted:   "1.2.0",
+		Latest:   "2.0.0",
+		Outdated: true,
+	}, {
+		Driver: "flightsql",
+		Source: "env",
+		Error:  "no package for this platform",
+	}}}
+	got := roundTrip(t, v)
+	if len(got.Drivers) != 2 || got.Drivers[0] != v.Drivers[0] || got.Drivers[1] != v.Drivers[1] {
+		t.Errorf("Drivers mismatch: %+v", got.Drivers)
+	}
+}
+
 func TestAuthDeviceCodeEvent(t *testing.T) {
 	v := jsonschema.AuthDeviceCodeEvent{
 		VerificationURI:         "https://auth.example.com/activate",
@@ -656,3 +734,55 @@ func TestSyncError(t *testing.T) {
 		t.Errorf("round-trip mismatch:\n want %+v\n  got %+v", v, got)
 	}
 }
+
+func TestRegistryBuildResponse(t *testing.T) {
+	v := jsonschema.RegistryBuildResponse{
+		IndexPath: "/srv/registry/index.yaml",
+		Packages: []jsonschema.RegistryPackage{
+			{Driver: "sqlite", Version: "1.0.0", Platform: "linux_amd64", URL: "sqlite/1.0.0/sqlite_linux_amd64-1.0.0.tar.gz",
+				SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", Size: 1024, New: true},
+		},
+	}
+	got := roundTrip(t, v)
+	if got.IndexPath != v.IndexPath {
+		t.Errorf("IndexPath mismatch")
+	}
+	if len(got.Packages) != 1 || got.Packages[0] != v.Packages[0] {
+		t.Errorf("Packages mismatch: %+v", got.Packages)
+	}
+}
+
+func TestMirrorResponse(t *testing.T) {
+	v := jsonschema.MirrorResponse{
+		IndexPath: "/srv/mirror/index.yaml",
+		Packages: []jsonschema.RegistryPackage{
+			{Driver: "sqlite", Version: "1.0.0", Platform: "linux_amd64", URL: "sqlite/1.0.0/sqlite_linux_amd64-1.0.0.tar.gz", New: true},
+			{Driver: "sqlite", Version: "1.0.0", Platform: "macos_arm64", URL: "sqlite/1.0.0/sqlite_macos_arm64-1.0.0.tar.gz"},
+		},
+	}
+	got := roundTrip(t, v)
+	if got.IndexPath != v.IndexPath {
+		t.Errorf("IndexPath mismatch")
+	}
+	if len(got.Packages) != 2 || got.Packages[0] != v.Packages[0] || got.Packages[1] != v.Packages[1] {
+		t.Errorf("Packages mismatch: %+v", got.Packages)
+	}
+}
+
+func TestRegistryLintResponse(t *testing.T) {
+	v := jsonschema.RegistryLintResponse{
+		Registry: "https://registry.example.com",
+		Packages: 2,
+		Deep:     true,
+		Issues: []jsonschema.RegistryIssue{
+			{Driver: "sqlite", Version: "1.0.0", Platform: "linux_amd64", Message: "signature file 'libadbc_driver_sqlite.so.sig' for driver is missing"},
+		},
+	}
+	got := roundTrip(t, v)
+	if got.Registry != v.Registry || got.Packages != v.Packages || got.Deep != v.Deep {
+		t.Errorf("round-trip mismatch:\n want %+v\n  got %+v", v, got)
+	}
+	if len(got.Issues) != 1 || got.Issues[0] != v.Issues[0] {
+		t.Errorf("Issues mismatch: %+v", got.Issues)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ocitest provides an in-process fake of the OCI distribution API
+// for testing registry clients. It serves manifests and blobs pushed through
+// its Go API and can require the bearer token handshake used by real
+// registries.
+package ocitest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const (
+	// ManifestMediaType is the media type of manifests pushed with Push.
+	ManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	emptyConfigType   = "application/vnd.oci.empty.v1+json"
+)
+
+// Layer is a blob to push as part of an artifact.
+type Layer struct {
+	MediaType string
+	Data      []byte
+	// Title is recorded as the org.opencontainers.image.title annotation.
+	Title string
+}
+
+// Descriptor is an OCI content descriptor.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an OCI image manifest.
+type Manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	ArtifactType  string       `json:"artifactType,omitempty"`
+	Config        Descriptor   `json:"config"`
+	Layers        []Descriptor `json:"layers"`
+}
+
+// Server is a fake OCI distribution server.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte // "repo@digest" and "repo:tag"
+
+	username, password string
+
+	tokenRequests atomic.Int32
+	requests      atomic.Int32
+}
+
+// NewServer starts a fake registry that serves anonymous pulls. It is closed
+// when the test ends.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		blobs:     make(map[string][]byte),
+		manifests: make(map[string][]byte),
+	}
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Host returns the host:port the server listens on, for use in oci:// URLs.
+func (s *Server) Host() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// RequireAuth makes every /v2/ request require a bearer token, issued by the
+// server's /token endpoint to clients presenting username and password with
+// basic auth.
+func (s *Server) RequireAuth(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username, s.password = username, password
+}
+
+// TokenRequests returns the number of token requests served so far.
+func (s *Server) TokenRequests() int { return int(s.tokenRequests.Load()) }
+
+// Requests returns the number of /v2/ requests served so far.
+func (s *Server) Requests() int { return int(s.requests.Load()) }
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Push stores layers as an artifact of artifactType in repo, tags it, and
+// returns the manifest's descriptor.
+func (s *Server) Push(repo, tag, artifactType string, layers ...Layer) Descriptor {
+	empty := []byte("{}")
+	m := Manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestMediaType,
+		ArtifactType:  artifactType,
+		Config:        Descriptor{MediaType: emptyConfigType, Digest: digestOf(empty), Size: int64(len(empty))},
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[m.Config.Digest] = empty
+	for _, l := range layers {
+		d := Descriptor{MediaType: l.MediaType, Digest: digestOf(l.Data), Size: int64(len(l.Data))}
+		if l.Title != "" {
+			d.Annotations = map[string]string{"org.opencontainers.image.title": l.Title}
+		}
+		m.Layers = append(m.Layers, d)
+		s.blobs[d.Digest] = l.Data
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	desc := Descriptor{MediaType: ManifestMediaType, Digest: digestOf(data), Size: int64(len(data))}
+	s.manifests[repo+"@"+desc.Digest] = data
+	s.manifests[repo+":"+tag] = data
+	return desc
+}
+
+// CorruptBlob replaces the content of the blob with the given digest, so it
+// no longer matches its digest.
+func (s *Server) CorruptBlob(digest string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[digest] = data
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		s.serveToken(w, r)
+		return
+	}
+	if r.URL.Path != "/v2" && !strings.HasPrefix(r.URL.Path, "/v2/") {
+		http.NotFound(w, r)
+		return
+	}
+	s.requests.Add(1)
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	rest := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/v2"), "/")
+	var repo string
+	var kind, ref string
+	for _, k := range []string{"/manifests/", "/blobs/"} {
+		if i := strings.LastIndex(rest, k); i > 0 {
+			repo, kind, ref = rest[:i], strings.Trim(k, "/"), rest[i+len(k):]
+			break
+		}
+	}
+
+	if !s.authorized(w, r, repo) {
+		return
+	}
+	if rest == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	s.mu.Lock()
+	var data []byte
+	var ok bool
+	switch kind {
+	case "manifests":
+		key := repo + ":" + ref
+		if strings.HasPrefix(ref, "sha256:") {
+			key = repo + "@" + ref
+		}
+		data, ok = s.manifests[key]
+	case "blobs":
+		data, ok = s.blobs[ref]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, strings.ToUpper(strings.TrimSuffix(kind, "s"))+"_UNKNOWN")
+		return
+	}
+
+	if kind == "manifests" {
+		w.Header().Set("Content-Type", ManifestMediaType)
+		w.Header().Set("Docker-Content-Digest", digestOf(data))
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// authorized checks the request's bearer token, answering with a challenge
+// if it is missing or does not grant pull access to repo.
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request, repo string) bool {
+	s.mu.Lock()
+	required := s.username != ""
+	s.mu.Unlock()
+	if !required {
+		return true
+	}
+
+	scope := "repository:" + repo + ":pull"
+	if r.Header.Get("Authorization") == "Bearer "+tokenFor(scope) ||
+		(repo == "" && strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token:")) {
+		return true
+	}
+
+	challenge := fmt.Sprintf(`Bearer realm="%s/token",service="ocitest"`, s.URL)
+	if repo != "" {
+		challenge += fmt.Sprintf(`,scope="%s"`, scope)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	writeError(w, http.StatusUnauthorized, "UNAUTHORIZED")
+	return false
+}
+
+func tokenFor(scope string) string {
+	return "token:" + scope
+}
+
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	s.tokenRequests.Add(1)
+
+	s.mu.Lock()
+	username, password := s.username, s.password
+	s.mu.Unlock()
+
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != username || pass != password || r.URL.Query().Get("service") != "ocitest" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"token": tokenFor(r.URL.Query().Get("scope"))})
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]string{{"code": code, "message": strings.ToLower(code)}},
+	})
+}
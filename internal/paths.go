@@ -72,3 +72,13 @@ func GetCredentialPath() (string, error) {
 
 	return filepath.Join(dir, "dbc", "credentials", "credentials.toml"), nil
 }
+
+// Directory for data dbc caches between runs, such as registry indexes. It
+// lives under GetUserConfigPath so it sits next to the uid/machine-id files.
+func GetCachePath() (string, error) {
+	dir, err := GetUserConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache"), nil
+}
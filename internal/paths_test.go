@@ -129,3 +129,12 @@ func TestGetCredentialPath(t *testing.T) {
 		assert.Equal(t, "credentials.toml", filepath.Base(path))
 	})
 }
+
+func TestGetCachePath(t *testing.T) {
+	path, err := GetCachePath()
+	require.NoError(t, err)
+
+	configPath, err := GetUserConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configPath, "cache"), path)
+}
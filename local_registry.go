@@ -0,0 +1,171 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// isLocalPath reports whether a registry location is a filesystem path
+// rather than a URL. Relative paths must be spelled with a leading "./" or
+// "../" so that a typo'd URL is not silently treated as a directory.
+func isLocalPath(s string) bool {
+	if filepath.IsAbs(s) {
+		return true
+	}
+	slashed := filepath.ToSlash(s)
+	return slashed == "." || slashed == ".." ||
+		strings.HasPrefix(slashed, "./") || strings.HasPrefix(slashed, "../")
+}
+
+// fileURL returns the file:// URL for the local path p, which is made
+// absolute against the working directory if needed.
+func fileURL(p string) (*url.URL, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve registry path %q: %w", p, err)
+	}
+	slashed := filepath.ToSlash(abs)
+	if !strings.HasPrefix(slashed, "/") {
+		// Windows drive paths become file:///C:/...
+		slashed = "/" + slashed
+	}
+	return &url.URL{Scheme: "file", Path: slashed}, nil
+}
+
+// localPath returns the filesystem path named by a file:// URL.
+func localPath(u *url.URL) string {
+	p := u.Path
+	if runtime.GOOS == "windows" && len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+func isFileURL(u *url.URL) bool {
+	return u != nil && u.Scheme == "file"
+}
+
+// parseRegistryURL parses the location of a registry: an http(s) URL, an
+// oci:// repository, a file:// URL, or a local directory path.
+func parseRegistryURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, errors.New("registry entry has empty url")
+	}
+	if isLocalPath(raw) {
+		return fileURL(raw)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry URL %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid registry URL %q: missing host", raw)
+		}
+	case "oci":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid registry URL %q: missing host", raw)
+		}
+		if strings.Trim(u.Path, "/") == "" {
+			return nil, fmt.Errorf("invalid registry URL %q: missing repository", raw)
+		}
+	case "file":
+		if u.Host != "" && u.Host != "localhost" {
+			return nil, fmt.Errorf("invalid registry URL %q: remote file hosts are not supported", raw)
+		}
+		if u.Opaque != "" || !strings.HasPrefix(u.Path, "/") {
+			return nil, fmt.Errorf("invalid registry URL %q: file URLs must use an absolute path", raw)
+		}
+		u.Host = ""
+	default:
+		return nil, fmt.Errorf("invalid registry URL %q: scheme must be http or https, oci, or a file:// URL or local path", raw)
+	}
+	return u, nil
+}
+
+// parseBaseURL parses the argument of WithBaseURL. Unlike registry entries it
+// is not validated beyond parsing, but a local path is still turned into a
+// file:// URL.
+func parseBaseURL(raw string) (*url.URL, error) {
+	if isLocalPath(raw) {
+		return fileURL(raw)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", raw, err)
+	}
+	return u, nil
+}
+
+// ResolveRegistryPaths returns a copy of entries with relative local paths,
+// signing key files, CA bundles and client certificates made absolute
+// against dir, typically the
+// directory containing the dbc.toml or config.toml that declared them. URLs
+// and armored keys are returned unchanged.
+func ResolveRegistryPaths(entries []RegistryEntry, dir string) []RegistryEntry {
+	if entries == nil {
+		return nil
+	}
+	out := make([]RegistryEntry, len(entries))
+	for i, e := range entries {
+		if isLocalPath(e.URL) && !filepath.IsAbs(e.URL) {
+			e.URL = filepath.Join(dir, e.URL)
+		}
+		e.SigningKeys = resolveSigningKeyPaths(e.SigningKeys, dir)
+		e.Network = e.Network.resolvePaths(dir)
+		e.ClientCert = resolvePath(e.ClientCert, dir)
+		e.ClientKey = resolvePath(e.ClientKey, dir)
+		out[i] = e
+	}
+	return out
+}
+
+// readLocalIndex reads the index file name, such as index.yaml, from a
+// file:// registry.
+func readLocalIndex(base *url.URL, name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(localPath(base), filepath.FromSlash(name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch drivers: %w", err)
+	}
+	return data, nil
+}
+
+// openLocalPackage opens a tarball from a file:// registry, returning it
+// along with its size.
+func openLocalPackage(u *url.URL) (io.ReadCloser, int64, error) {
+	f, err := os.Open(localPath(u))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, 0, fmt.Errorf("failed to download %s: package not found", u)
+		}
+		return nil, 0, fmt.Errorf("failed to download %s: %w", u, err)
+	}
+	size := int64(-1)
+	if fi, err := f.Stat(); err == nil {
+		size = fi.Size()
+	}
+	return f, size, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLocalRegistry lays out a registry directory with one driver whose
+// package URL is relative and one that relies on the default path layout.
+func newLocalRegistry(t *testing.T) string {
+	t.Helper()
+
+	tarball, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test-driver-1.tar.gz"))
+	require.NoError(t, err)
+
+	platform := config.PlatformTuple()
+	dir := t.TempDir()
+	index := fmt.Sprintf(`name: vendored
+drivers:
+  - name: Test Driver 1
+    path: test-driver-1
+    pkginfo:
+      - version: v1.0.0
+        packages:
+          - platform: %[1]s
+            url: pkgs/test-driver-1.tar.gz
+  - name: Test Driver 2
+    path: test-driver-2
+    pkginfo:
+      - version: v2.0.0
+        packages:
+          - platform: %[1]s
+`, platform)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), []byte(index), 0o644))
+
+	for _, p := range []string{
+		filepath.Join(dir, "pkgs", "test-driver-1.tar.gz"),
+		filepath.Join(dir, "test-driver-2", "2.0.0", "test-driver-2_"+platform+"-2.0.0.tar.gz"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, tarball, 0o644))
+	}
+	return dir
+}
+
+func TestFileRegistry(t *testing.T) {
+	dir := newLocalRegistry(t)
+	want, err := os.ReadFile(filepath.Join(dir, "pkgs", "test-driver-1.tar.gz"))
+	require.NoError(t, err)
+
+	replace := true
+	locations := map[string]string{
+		"file URL":      "file://" + filepath.ToSlash(dir),
+		"absolute path": dir,
+	}
+	for name, loc := range locations {
+		t.Run(name, func(t *testing.T) {
+			c, err := dbc.NewClient(
+				dbc.WithProjectRegistries([]dbc.RegistryEntry{{URL: loc}}, &replace),
+				dbc.WithIndexCacheDir(""),
+				dbc.WithPackageCacheDir(""),
+			)
+			require.NoError(t, err)
+
+			drivers, err := c.Search(t.Context(), "")
+			require.NoError(t, err)
+			require.Len(t, drivers, 2)
+			assert.Equal(t, "vendored", drivers[0].Registry.Name)
+
+			for _, d := range drivers {
+				pkg, err := d.GetPackage(nil, config.PlatformTuple(), false)
+				require.NoError(t, err)
+				assert.Equal(t, "file", pkg.Path.Scheme)
+
+				body, err := c.Download(t.Context(), pkg)
+				require.NoError(t, err, d.Path)
+				data, err := io.ReadAll(body)
+				body.Close()
+				require.NoError(t, err)
+				assert.Equal(t, want, data)
+			}
+		})
+	}
+}
+
+func TestFileRegistryRelativePath(t *testing.T) {
+	dir := newLocalRegistry(t)
+	t.Chdir(filepath.Dir(dir))
+
+	c, err := dbc.NewClient(
+		dbc.WithBaseURL("./"+filepath.Base(dir)),
+		dbc.WithIndexCacheDir(""),
+		dbc.WithPackageCacheDir(""),
+		// Local registries are read from disk even when offline.
+		dbc.WithOffline(true),
+	)
+	require.NoError(t, err)
+
+	cfg := config.Config{Level: config.ConfigEnv, Location: t.TempDir()}
+	manifest, err := c.Install(t.Context(), cfg, "test-driver-1")
+	require.NoError(t, err)
+	assert.Equal(t, "test-driver-1", manifest.DriverInfo.ID)
+}
+
+func TestFileRegistryMissingPackage(t *testing.T) {
+	dir := newLocalRegistry(t)
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "pkgs")))
+
+	c, err := dbc.NewClient(dbc.WithBaseURL(dir), dbc.WithIndexCacheDir(""))
+	require.NoError(t, err)
+
+	drivers, err := c.Search(t.Context(), "test-driver-1")
+	require.NoError(t, err)
+	pkg, err := findDriver(t, drivers, "test-driver-1").GetPackage(nil, config.PlatformTuple(), false)
+	require.NoError(t, err)
+
+	_, err = c.Download(t.Context(), pkg)
+	assert.ErrorContains(t, err, "package not found")
+}
@@ -0,0 +1,274 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// NetworkConfig is the [network] section of a global config.toml, or the
+// [registries.network] section of a single registry entry. Each zero field
+// inherits: a registry's from the global section, and the global section's
+// from Go's defaults.
+type NetworkConfig struct {
+	// Proxy is the URL of the proxy to send requests through. Empty uses
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY from the environment, and
+	// "direct" connects without a proxy whatever the environment says.
+	Proxy string `toml:"proxy,omitempty"`
+	// CABundle is the path of a PEM file of certificate authorities to
+	// trust in addition to the system's, such as a corporate TLS proxy's.
+	// Relative paths are resolved like relative registry paths.
+	CABundle string `toml:"ca_bundle,omitempty"`
+	// ConnectTimeout bounds establishing a connection, including the TLS
+	// handshake.
+	ConnectTimeout Duration `toml:"connect_timeout,omitempty"`
+	// ReadTimeout bounds how long to wait for the server to send anything:
+	// the response headers, or the next part of a response body. Unlike a
+	// registry's timeout, it does not limit how long a slow but steady
+	// download may take.
+	ReadTimeout Duration `toml:"read_timeout,omitempty"`
+	// Retries is how many times a request that fails with a network error,
+	// or a 429 or 5xx response, is retried, and how many times a download
+	// that fails partway through is resumed. Nil uses the default of 3, and
+	// 0 disables retries.
+	Retries *int `toml:"retries,omitempty"`
+}
+
+// directProxy is the Proxy value that bypasses any proxy configured in the
+// environment.
+const directProxy = "direct"
+
+// IsZero reports whether n leaves every setting at its default.
+func (n NetworkConfig) IsZero() bool { return n == NetworkConfig{} }
+
+// retries returns how many times to retry a failed request.
+func (n NetworkConfig) retries() int {
+	if n.Retries == nil {
+		return defaultRetries
+	}
+	return *n.Retries
+}
+
+// merge returns n with the settings o sets overriding n's.
+func (n NetworkConfig) merge(o NetworkConfig) NetworkConfig {
+	if o.Proxy != "" {
+		n.Proxy = o.Proxy
+	}
+	if o.CABundle != "" {
+		n.CABundle = o.CABundle
+	}
+	if o.ConnectTimeout != 0 {
+		n.ConnectTimeout = o.ConnectTimeout
+	}
+	if o.ReadTimeout != 0 {
+		n.ReadTimeout = o.ReadTimeout
+	}
+	if o.Retries != nil {
+		n.Retries = o.Retries
+	}
+	return n
+}
+
+// resolvePaths returns n with a relative CABundle made relative to dir.
+func (n NetworkConfig) resolvePaths(dir string) NetworkConfig {
+	n.CABundle = resolvePath(n.CABundle, dir)
+	return n
+}
+
+// resolvePath returns p made absolute against dir if it is a relative path.
+func resolvePath(p, dir string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// transport returns a RoundTripper applying n, which retries failed requests
+// and presents the client certificate in certs for the host of each request,
+// if there is one.
+func (n NetworkConfig) transport(certs map[string]tls.Certificate) (http.RoundTripper, error) {
+	if n.Retries != nil && *n.Retries < 0 {
+		return nil, fmt.Errorf("invalid retries %d: must not be negative", *n.Retries)
+	}
+	rt, err := n.connTransport(certs)
+	if err != nil {
+		return nil, err
+	}
+	return &retryTransport{RoundTripper: rt, retries: n.retries()}, nil
+}
+
+// connTransport returns the RoundTripper that transport retries through. If
+// n only sets Retries and there are no certs, that is http.DefaultTransport.
+func (n NetworkConfig) connTransport(certs map[string]tls.Certificate) (http.RoundTripper, error) {
+	if n.Proxy == "" && n.CABundle == "" && n.ConnectTimeout == 0 && n.ReadTimeout == 0 && len(certs) == 0 {
+		return http.DefaultTransport, nil
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	switch n.Proxy {
+	case "":
+	case directProxy:
+		t.Proxy = nil
+	default:
+		proxy, err := url.Parse(n.Proxy)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy %q: must be a URL such as http://proxy.example.com:3128", n.Proxy)
+		}
+		t.Proxy = http.ProxyURL(proxy)
+	}
+
+	if n.CABundle != "" {
+		pool, err := loadCABundle(n.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	if n.ConnectTimeout != 0 {
+		d := &net.Dialer{Timeout: time.Duration(n.ConnectTimeout), KeepAlive: 30 * time.Second}
+		t.DialContext = d.DialContext
+		t.TLSHandshakeTimeout = time.Duration(n.ConnectTimeout)
+	}
+
+	t.ResponseHeaderTimeout = time.Duration(n.ReadTimeout)
+
+	var rt http.RoundTripper = t
+	if len(certs) > 0 {
+		rt = newClientCertTransport(t, certs)
+	}
+	if n.ReadTimeout == 0 {
+		return rt, nil
+	}
+	return &readTimeoutTransport{RoundTripper: rt, timeout: time.Duration(n.ReadTimeout)}, nil
+}
+
+// loadCABundle returns the system certificate pool with the certificates in
+// the PEM file at path added to it.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// newHTTPClient returns a client sending requests through rt with the given
+// User-Agent.
+func newHTTPClient(rt http.RoundTripper, userAgent string) *http.Client {
+	return &http.Client{
+		Transport: &uaRoundTripper{
+			RoundTripper: rt,
+			userAgent:    userAgent,
+		},
+	}
+}
+
+var errReadTimeout = errors.New("timed out waiting for the server to send data")
+
+// readTimeoutTransport cancels a request if its response body stalls for
+// longer than timeout. Waiting for the response headers is bounded by the
+// underlying transport's ResponseHeaderTimeout.
+type readTimeoutTransport struct {
+	http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *readTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(req.Context())
+	resp, err := t.RoundTripper.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel(nil)
+		return nil, err
+	}
+	resp.Body = &readTimeoutBody{
+		ReadCloser: resp.Body,
+		ctx:        ctx,
+		cancel:     cancel,
+		timeout:    t.timeout,
+		timer:      time.AfterFunc(t.timeout, func() { cancel(errReadTimeout) }),
+	}
+	return resp, nil
+}
+
+type readTimeoutBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	timeout time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func (b *readTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && errors.Is(context.Cause(b.ctx), errReadTimeout) {
+		return n, fmt.Errorf("read timed out after %s: %w", b.timeout, errReadTimeout)
+	}
+	if n > 0 {
+		b.mu.Lock()
+		b.timer.Reset(b.timeout)
+		b.mu.Unlock()
+	}
+	return n, err
+}
+
+func (b *readTimeoutBody) Close() error {
+	b.mu.Lock()
+	b.timer.Stop()
+	b.mu.Unlock()
+	err := b.ReadCloser.Close()
+	b.cancel(nil)
+	return err
+}
+
+// NetworkFor returns the network settings for requests to the registry at u:
+// the global [network] section, overridden by the entry for that registry if
+// there is one. It is safe to call on a nil *GlobalConfig.
+func (g *GlobalConfig) NetworkFor(u *url.URL) NetworkConfig {
+	if g == nil {
+		return NetworkConfig{}
+	}
+	n := g.Network
+	if u == nil {
+		return n
+	}
+	for _, e := range g.Registries {
+		if eu, err := parseRegistryURL(e.URL); err == nil && registryURLKey(eu) == registryURLKey(u) {
+			return n.merge(e.Network)
+		}
+	}
+	return n
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveTestIndex(t *testing.T) http.HandlerFunc {
+	indexData, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
+	require.NoError(t, err)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(indexData)
+	}
+}
+
+func TestNetworkCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(serveTestIndex(t))
+	t.Cleanup(srv.Close)
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: srv.Certificate().Raw,
+	}), 0o644))
+
+	search := func(t *testing.T, opts ...dbc.Option) error {
+		c, err := dbc.NewClient(append(opts, dbc.WithIndexCacheDir(""))...)
+		require.NoError(t, err)
+		_, err = c.Search(t.Context(), "")
+		return err
+	}
+
+	t.Run("untrusted", func(t *testing.T) {
+		err := search(t, dbc.WithBaseURL(srv.URL))
+		assert.ErrorContains(t, err, "certificate")
+	})
+
+	t.Run("global", func(t *testing.T) {
+		assert.NoError(t, search(t, dbc.WithBaseURL(srv.URL), dbc.WithNetwork(dbc.NetworkConfig{CABundle: bundle})))
+	})
+
+	t.Run("per registry", func(t *testing.T) {
+		assert.NoError(t, search(t, dbc.WithGlobalConfig(&dbc.GlobalConfig{
+			ReplaceDefaults: true,
+			Registries: []dbc.RegistryEntry{{
+				URL:     srv.URL,
+				Network: dbc.NetworkConfig{CABundle: bundle},
+			}},
+		})))
+	})
+
+	t.Run("invalid bundle", func(t *testing.T) {
+		_, err := dbc.NewClient(dbc.WithNetwork(dbc.NetworkConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")}))
+		assert.ErrorContains(t, err, "failed to read CA bundle")
+	})
+}
+
+func TestNetworkProxy(t *testing.T) {
+	var hosts []string
+	handler := serveTestIndex(t)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host+r.URL.Path)
+		handler(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	c, err := dbc.NewClient(
+		dbc.WithBaseURL("http://registry.invalid"),
+		dbc.WithIndexCacheDir(""),
+		dbc.WithNetwork(dbc.NetworkConfig{Proxy: proxy.URL}),
+	)
+	require.NoError(t, err)
+	drivers, err := c.Search(t.Context(), "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, drivers)
+	assert.Contains(t, hosts, "registry.invalid/index.yaml")
+
+	_, err = dbc.NewClient(dbc.WithNetwork(dbc.NetworkConfig{Proxy: "proxy.example.com"}))
+	assert.ErrorContains(t, err, `invalid proxy "proxy.example.com"`)
+}
+
+func TestNetworkReadTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("drivers:\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+
+	noRetries := 0
+	c, err := dbc.NewClient(
+		dbc.WithBaseURL(srv.URL),
+		dbc.WithIndexCacheDir(""),
+		dbc.WithNetwork(dbc.NetworkConfig{ReadTimeout: dbc.Duration(50 * time.Millisecond), Retries: &noRetries}),
+	)
+	require.NoError(t, err)
+	_, err = c.Search(t.Context(), "")
+	assert.ErrorContains(t, err, "read timed out after 50ms")
+}
+
+func TestLoadGlobalConfigNetwork(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"), []byte(`
+[network]
+proxy = "http://proxy.example.com:3128"
+connect_timeout = "5s"
+read_timeout = "30s"
+
+[[registries]]
+url = "https://private.example.com"
+
+[registries.network]
+proxy = "direct"
+ca_bundle = "certs/corp.pem"
+`), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "certs"), 0o755))
+
+	_, err := dbc.LoadGlobalConfig(dir)
+	assert.ErrorContains(t, err, "failed to read CA bundle")
+
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	srv.Close()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "certs", "corp.pem"), pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: srv.Certificate().Raw,
+	}), 0o644))
+
+	cfg, err := dbc.LoadGlobalConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, dbc.NetworkConfig{
+		Proxy:          "http://proxy.example.com:3128",
+		ConnectTimeout: dbc.Duration(5 * time.Second),
+		ReadTimeout:    dbc.Duration(30 * time.Second),
+	}, cfg.Network)
+	assert.Equal(t, dbc.NetworkConfig{
+		Proxy:    "direct",
+		CABundle: filepath.Join(dir, "certs", "corp.pem"),
+	}, cfg.Registries[0].Network)
+
+	assert.Equal(t, dbc.NetworkConfig{
+		Proxy:          "direct",
+		CABundle:       filepath.Join(dir, "certs", "corp.pem"),
+		ConnectTimeout: dbc.Duration(5 * time.Second),
+		ReadTimeout:    dbc.Duration(30 * time.Second),
+	}, cfg.NetworkFor(mustParseURL("https://private.example.com/")))
+	assert.Equal(t, cfg.Network, cfg.NetworkFor(mustParseURL("https://other.example.com")))
+	assert.Zero(t, (*dbc.GlobalConfig)(nil).NetworkFor(nil))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"), []byte(`
+[network]
+proxy = "::not a url"
+`), 0o644))
+	_, err = dbc.LoadGlobalConfig(dir)
+	assert.ErrorContains(t, err, "[network]: invalid proxy")
+}
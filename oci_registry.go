@@ -0,0 +1,528 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/columnar-tech/dbc/auth"
+)
+
+// Media types of the artifacts dbc reads from OCI registries.
+const (
+	// OCIIndexMediaType is the media type of the layer holding a
+	// registry's index.yaml.
+	OCIIndexMediaType = "application/vnd.columnar.dbc.index.v1+yaml"
+	// OCIIndexJSONMediaType is the media type of the layer holding a
+	// registry's index.json, which may be pushed instead.
+	OCIIndexJSONMediaType = "application/vnd.columnar.dbc.index.v1+json"
+	// OCIIndexSignatureMediaType is the media type of the optional layer
+	// holding the index's detached signature.
+	OCIIndexSignatureMediaType = "application/vnd.columnar.dbc.index.v1.sig"
+	// OCIPackageMediaType is the media type of the layer holding a driver
+	// package tarball.
+	OCIPackageMediaType = "application/vnd.columnar.dbc.package.v1.tar+gzip"
+
+	// ociIndexTag is the tag of the index artifact in the registry's
+	// repository.
+	ociIndexTag = "index"
+)
+
+var ociManifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+func isOCIURL(u *url.URL) bool {
+	return u != nil && u.Scheme == "oci"
+}
+
+// ociRef identifies a manifest in an OCI registry: oci://host/repository
+// followed by :tag or @digest.
+type ociRef struct {
+	host       string
+	user       *url.Userinfo
+	repository string
+	reference  string
+}
+
+func (r ociRef) String() string {
+	sep := ":"
+	if strings.HasPrefix(r.reference, "sha256:") {
+		sep = "@"
+	}
+	return r.host + "/" + r.repository + sep + r.reference
+}
+
+// parseOCIRef parses a package URL of the form oci://host/repo:tag or
+// oci://host/repo@sha256:digest.
+func parseOCIRef(u *url.URL) (ociRef, error) {
+	ref := ociRef{host: u.Host, user: u.User}
+	p := strings.TrimPrefix(u.Path, "/")
+	if i := strings.LastIndex(p, "@"); i >= 0 {
+		ref.repository, ref.reference = p[:i], p[i+1:]
+	} else if i := strings.LastIndex(p, ":"); i > strings.LastIndex(p, "/") {
+		ref.repository, ref.reference = p[:i], p[i+1:]
+	}
+	if ref.repository == "" || ref.reference == "" {
+		return ociRef{}, fmt.Errorf("invalid OCI reference %s: expected repository:tag or repository@digest", u.Redacted())
+	}
+	return ref, nil
+}
+
+// ociIndexRef returns the reference of the index artifact of the registry
+// at base.
+func ociIndexRef(base *url.URL) ociRef {
+	return ociRef{
+		host:       base.Host,
+		user:       base.User,
+		repository: strings.Trim(base.Path, "/"),
+		reference:  ociIndexTag,
+	}
+}
+
+// ociTag returns s with the characters OCI tags don't allow replaced, the
+// same way Helm stores semver build metadata.
+func ociTag(s string) string {
+	return strings.ReplaceAll(s, "+", "_")
+}
+
+// ociPackageURL returns the URL of a package in the registry at base. A
+// reference is resolved against the registry's repository unless it is
+// already a URL; packages without one default to the artifact tagged
+// <version>-<platform> in the repository <repo>/<driver>.
+func ociPackageURL(base *url.URL, reference, driver, version, platform string) (*url.URL, error) {
+	if strings.Contains(reference, "://") {
+		u, err := url.Parse(reference)
+		if err != nil {
+			return nil, fmt.Errorf("invalid package URL %q: %w", reference, err)
+		}
+		return u, nil
+	}
+	if reference == "" {
+		reference = driver + ":" + ociTag(version+"-"+platform)
+	}
+	u := *base
+	u.Path = path.Join("/", base.Path, reference)
+	u.RawPath = ""
+	return &u, nil
+}
+
+// ociDescriptor is an OCI content descriptor.
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// layer returns the layer of m with the given media type, or its only layer
+// if it has just one.
+func (m ociManifest) layer(mediaType string) (ociDescriptor, bool) {
+	for _, l := range m.Layers {
+		if l.MediaType == mediaType {
+			return l, true
+		}
+	}
+	if len(m.Layers) == 1 {
+		return m.Layers[0], true
+	}
+	return ociDescriptor{}, false
+}
+
+// ociScheme returns the scheme used to reach an OCI registry: plain HTTP for
+// loopback hosts, as container tooling does, and HTTPS otherwise.
+func ociScheme(host string) string {
+	h := host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		h = hostname
+	}
+	if h == "localhost" {
+		return "http"
+	}
+	if ip := net.ParseIP(h); ip != nil && ip.IsLoopback() {
+		return "http"
+	}
+	return "https"
+}
+
+// ociGet issues a GET against the distribution API for ref. If the registry
+// challenges for a bearer token, one is requested from its token service
+// with the credentials in ref's URL or stored for its host, if any, cached
+// for later requests, and the request is retried.
+func (c *Client) ociGet(ctx context.Context, ref ociRef, endpoint string, accept ...string) (*http.Response, error) {
+	u := &url.URL{
+		Scheme: ociScheme(ref.host),
+		Host:   ref.host,
+		Path:   "/v2/" + ref.repository + "/" + endpoint,
+	}
+	scope := "repository:" + ref.repository + ":pull"
+	tokenKey := ref.host + " " + scope
+
+	do := func(token string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+		if len(accept) > 0 {
+			req.Header.Set("Accept", strings.Join(accept, ", "))
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return c.requestClient(ctx).Do(req)
+	}
+
+	cached, _ := c.ociTokens.Load(tokenKey)
+	token, _ := cached.(string)
+	resp, err := do(token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	scheme, params := parseAuthChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") || params["realm"] == "" {
+		return nil, fmt.Errorf("%s: %w", ref, ErrUnauthorized)
+	}
+	if params["scope"] == "" {
+		params["scope"] = scope
+	}
+
+	if token, err = c.ociToken(ctx, ref, params); err != nil {
+		return nil, err
+	}
+	c.ociTokens.Store(tokenKey, token)
+
+	if resp, err = do(token); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", ref, ErrUnauthorized)
+	}
+	return resp, nil
+}
+
+// ociToken requests a bearer token from the token service described by a
+// WWW-Authenticate challenge. The credentials in ref's URL, or else those
+// stored for its host, are sent along, but only to a realm that is either
+// served over HTTPS or on the registry's own loopback host.
+func (c *Client) ociToken(ctx context.Context, ref ociRef, challenge map[string]string) (string, error) {
+	realm, err := url.Parse(challenge["realm"])
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %q: %w", challenge["realm"], err)
+	}
+	if err := auth.CheckSecureURL(realm); err != nil {
+		return "", fmt.Errorf("token realm for %s: %w", ref, err)
+	}
+	q := realm.Query()
+	if challenge["service"] != "" {
+		q.Set("service", challenge["service"])
+	}
+	q.Set("scope", challenge["scope"])
+	realm.RawQuery = q.Encode()
+
+	var cred *auth.Credential
+	if ref.user == nil {
+		cred, err = c.getCredential(&url.URL{Scheme: "oci", Host: ref.host})
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read credentials: %w", err)
+		}
+	}
+	if (ref.user != nil || cred != nil) && !strings.EqualFold(realm.Scheme, "https") &&
+		!strings.EqualFold(realm.Host, ref.host) {
+		return "", fmt.Errorf("refusing to send credentials for %s to token realm %s on another host without https",
+			ref, realm.Redacted())
+	}
+
+	buildReq := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build token request: %w", err)
+		}
+		if ref.user != nil {
+			password, _ := ref.user.Password()
+			req.SetBasicAuth(ref.user.Username(), password)
+		} else if cred != nil {
+			if token := cred.GetAuthToken(ctx); token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		return req, nil
+	}
+
+	req, err := buildReq()
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.requestClient(ctx).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch token for %s: %w", ref, err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized && cred != nil {
+		resp.Body.Close()
+		if err := cred.Refresh(ctx); err != nil {
+			return "", fmt.Errorf("failed to refresh auth token: %w", err)
+		}
+		if req, err = buildReq(); err != nil {
+			return "", err
+		}
+		if resp, err = c.requestClient(ctx).Do(req); err != nil {
+			return "", fmt.Errorf("failed to fetch token for %s: %w", ref, err)
+		}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", fmt.Errorf("%s: %w", ref, ErrUnauthorized)
+	default:
+		return "", fmt.Errorf("failed to fetch token for %s: %s", ref, resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token for %s: %w", ref, err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("token service returned no token for %s", ref)
+}
+
+// parseAuthChallenge parses a WWW-Authenticate header such as
+// `Bearer realm="https://auth.example.com/token",service="registry"`.
+func parseAuthChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(value) && value[i] != '"'; i++ {
+				if value[i] == '\\' && i+1 < len(value) {
+					i++
+				}
+				b.WriteByte(value[i])
+			}
+			params[key] = b.String()
+			rest = value[min(i+1, len(value)):]
+		} else {
+			v, after, _ := strings.Cut(value, ",")
+			params[key] = strings.TrimSpace(v)
+			rest = "," + after
+		}
+		rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ","))
+	}
+	return scheme, params
+}
+
+// ociFetchManifest fetches and decodes the image manifest ref points to.
+func (c *Client) ociFetchManifest(ctx context.Context, ref ociRef) (ociManifest, error) {
+	resp, err := c.ociGet(ctx, ref, "manifests/"+ref.reference, ociManifestMediaTypes...)
+	if err != nil {
+		return ociManifest{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ociManifest{}, fmt.Errorf("failed to fetch manifest %s: %s", ref, resp.Status)
+	}
+
+	var m ociManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&m); err != nil {
+		return ociManifest{}, fmt.Errorf("failed to decode manifest %s: %w", ref, err)
+	}
+	if m.MediaType != "" && !strings.HasSuffix(m.MediaType, "manifest.v1+json") &&
+		!strings.HasSuffix(m.MediaType, "manifest.v2+json") {
+		return ociManifest{}, fmt.Errorf("manifest %s has unsupported media type %s", ref, m.MediaType)
+	}
+	return m, nil
+}
+
+// ociOpenBlob opens the blob desc in ref's repository. The stream is
+// verified against the descriptor's digest and size as it is read.
+func (c *Client) ociOpenBlob(ctx context.Context, ref ociRef, desc ociDescriptor) (io.ReadCloser, int64, error) {
+	algo, hex, ok := strings.Cut(desc.Digest, ":")
+	if !ok || algo != "sha256" {
+		return nil, 0, fmt.Errorf("blob %s in %s uses an unsupported digest algorithm", desc.Digest, ref)
+	}
+
+	resp, err := c.ociGet(ctx, ref, "blobs/"+desc.Digest)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("failed to fetch blob %s from %s: %s", desc.Digest, ref, resp.Status)
+	}
+
+	blobURL := &url.URL{Scheme: "oci", Host: ref.host, Path: "/" + ref.repository + "@" + desc.Digest}
+	body, err := verifyPackage(PkgInfo{Path: blobURL, SHA256: hex, Size: desc.Size}, resp.Body, resp.ContentLength)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.ContentLength, nil
+}
+
+// ociReadBlob reads the whole of the blob desc in ref's repository.
+func (c *Client) ociReadBlob(ctx context.Context, ref ociRef, desc ociDescriptor) ([]byte, error) {
+	body, _, err := c.ociOpenBlob(ctx, ref, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// fetchOCIIndex fetches the index of an OCI registry. The index layer's
+// digest serves as the cache validator, so an unchanged index is not
+// downloaded again.
+func (c *Client) fetchOCIIndex(ctx context.Context, index *Registry, cached []byte, meta indexCacheMeta, haveCache bool) ([]byte, error) {
+	ref := ociIndexRef(index.BaseURL)
+	m, err := c.ociFetchManifest(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch drivers: %w", err)
+	}
+
+	var indexLayer, sigLayer ociDescriptor
+	var found, signed bool
+	for _, l := range m.Layers {
+		switch l.MediaType {
+		case OCIIndexMediaType, OCIIndexJSONMediaType:
+			indexLayer, found = l, true
+		case OCIIndexSignatureMediaType:
+			sigLayer, signed = l, true
+		}
+	}
+	if !found && len(m.Layers) == 1 {
+		indexLayer, found = m.Layers[0], true
+	}
+	if !found {
+		return nil, fmt.Errorf("failed to fetch drivers: manifest %s has no %s layer", ref, OCIIndexMediaType)
+	}
+
+	if haveCache && meta.ETag == indexLayer.Digest && (meta.Signed || !signed) {
+		if index.RequireSignature && !meta.Signed {
+			return nil, ErrUnsignedIndex
+		}
+		return cached, nil
+	}
+
+	data, err := c.ociReadBlob(ctx, ref, indexLayer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch drivers: %w", err)
+	}
+
+	var sig []byte
+	if signed {
+		if sig, err = c.ociReadBlob(ctx, ref, sigLayer); err != nil {
+			return nil, fmt.Errorf("failed to fetch index signature: %w", err)
+		}
+	}
+	if err := checkIndexSignature(index, data, sig, haveCache && meta.Signed); err != nil {
+		return nil, err
+	}
+
+	if !isUncachedIndex(ctx) {
+		_ = c.indexCache.store(index.BaseURL, data, indexCacheMeta{ETag: indexLayer.Digest, Signed: signed})
+	}
+	return data, nil
+}
+
+// openOCIPackage resolves pkg's artifact and opens its package layer. If
+// the index lists a digest for pkg, it must match the layer's.
+func (c *Client) openOCIPackage(ctx context.Context, pkg PkgInfo) (io.ReadCloser, int64, error) {
+	ref, layer, err := c.ociPackageLayer(ctx, pkg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	body, size, err := c.ociOpenBlob(ctx, ref, layer)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to download %s: %w", pkg.Path.Redacted(), err)
+	}
+	return body, size, nil
+}
+
+// ociPackageLayer resolves pkg's artifact and returns its package layer,
+// checked against the digest and size the index lists for pkg.
+func (c *Client) ociPackageLayer(ctx context.Context, pkg PkgInfo) (ociRef, ociDescriptor, error) {
+	ref, err := parseOCIRef(pkg.Path)
+	if err != nil {
+		return ociRef{}, ociDescriptor{}, err
+	}
+
+	m, err := c.ociFetchManifest(ctx, ref)
+	if err != nil {
+		return ociRef{}, ociDescriptor{}, fmt.Errorf("failed to download %s: %w", pkg.Path.Redacted(), err)
+	}
+	layer, ok := m.layer(OCIPackageMediaType)
+	if !ok {
+		return ociRef{}, ociDescriptor{}, fmt.Errorf("failed to download %s: manifest has no %s layer", pkg.Path.Redacted(), OCIPackageMediaType)
+	}
+
+	if want := strings.ToLower(pkg.SHA256); want != "" && "sha256:"+want != layer.Digest {
+		return ociRef{}, ociDescriptor{}, &DigestMismatchError{
+			URL:      pkg.Path.Redacted(),
+			Field:    "sha256",
+			Expected: want,
+			Actual:   strings.TrimPrefix(layer.Digest, "sha256:"),
+		}
+	}
+
+	if pkg.Size > 0 && pkg.Size != layer.Size {
+		return ociRef{}, ociDescriptor{}, &DigestMismatchError{
+			URL:      pkg.Path.Redacted(),
+			Field:    "size",
+			Expected: strconv.FormatInt(pkg.Size, 10),
+			Actual:   strconv.FormatInt(layer.Size, 10),
+		}
+	}
+	return ref, layer, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/columnar-tech/dbc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientOffline(t *testing.T) {
+	indexData, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test_index.yaml"))
+	require.NoError(t, err)
+	tarballData, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test-driver-1.tar.gz"))
+	require.NoError(t, err)
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch {
+		case r.URL.Path == "/index.yaml":
+			w.Write(indexData)
+		case strings.HasSuffix(r.URL.Path, ".tar.gz"):
+			w.Write(tarballData)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	indexDir, pkgDir := t.TempDir(), t.TempDir()
+	newClient := func(offline bool) *dbc.Client {
+		c, err := dbc.NewClient(
+			dbc.WithHTTPClient(&http.Client{}),
+			dbc.WithBaseURL(srv.URL),
+			dbc.WithIndexCacheDir(indexDir),
+			dbc.WithPackageCacheDir(pkgDir),
+			dbc.WithOffline(offline),
+		)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("cold cache fails with ErrNotCached", func(t *testing.T) {
+		c := newClient(true)
+		assert.True(t, c.Offline())
+
+		drivers, err := c.Search(t.Context(), "")
+		assert.Empty(t, drivers)
+		require.ErrorIs(t, err, dbc.ErrNotCached)
+		assert.Zero(t, hits.Load(), "offline client must not touch the network")
+	})
+
+	cfg := config.Config{Level: config.ConfigEnv, Location: t.TempDir()}
+	_, err = newClient(false).Install(t.Context(), cfg, "test-driver-1")
+	require.NoError(t, err)
+
+	online := hits.Load()
+	require.NotZero(t, online)
+
+	t.Run("search and install from cache", func(t *testing.T) {
+		c := newClient(true)
+		drivers, err := c.Search(t.Context(), "test-driver")
+		require.NoError(t, err)
+		assert.NotEmpty(t, drivers)
+
+		cfg := config.Config{Level: config.ConfigEnv, Location: t.TempDir()}
+		manifest, err := c.Install(t.Context(), cfg, "test-driver-1")
+		require.NoError(t, err)
+		assert.Equal(t, "test-driver-1", manifest.DriverInfo.ID)
+		assert.Equal(t, online, hits.Load(), "offline client must not touch the network")
+	})
+
+	t.Run("download from cache", func(t *testing.T) {
+		c := newClient(true)
+		drivers, err := c.Search(t.Context(), "test-driver-1")
+		require.NoError(t, err)
+		pkg, err := findDriver(t, drivers, "test-driver-1").GetPackage(nil, config.PlatformTuple(), false)
+		require.NoError(t, err)
+
+		body, err := c.Download(t.Context(), pkg)
+		require.NoError(t, err)
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, tarballData, data)
+	})
+
+	t.Run("uncached package fails with ErrNotCached", func(t *testing.T) {
+		c := newClient(true)
+		pkg := dbc.PkgInfo{
+			Driver: dbc.Driver{Title: "missing"},
+			Path:   mustParseURL(srv.URL + "/missing/1.0.0/missing.tar.gz"),
+		}
+		_, err := c.DownloadPackage(t.Context(), pkg, nil)
+		require.ErrorIs(t, err, dbc.ErrNotCached)
+	})
+}
+
+func TestPackageCacheSkipsTruncatedDownloads(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Promise more bytes than are sent so the client sees an
+		// unexpected EOF.
+		w.Header().Set("Content-Length", "1024")
+		w.Write([]byte("partial"))
+	}))
+	t.Cleanup(srv.Close)
+
+	pkgDir := t.TempDir()
+	c, err := dbc.NewClient(
+		dbc.WithHTTPClient(&http.Client{}),
+		dbc.WithBaseURL(srv.URL),
+		dbc.WithIndexCacheDir(""),
+		dbc.WithPackageCacheDir(pkgDir),
+	)
+	require.NoError(t, err)
+
+	pkg := dbc.PkgInfo{
+		Driver: dbc.Driver{Title: "truncated"},
+		Path:   mustParseURL(srv.URL + "/truncated.tar.gz"),
+	}
+	_, err = c.DownloadPackage(t.Context(), pkg, nil)
+	require.Error(t, err)
+
+	matches, err := filepath.Glob(filepath.Join(pkgDir, "*", "*"))
+	require.NoError(t, err)
+	assert.Empty(t, matches, "a truncated download must not be cached")
+}
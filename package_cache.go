@@ -0,0 +1,137 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/columnar-tech/dbc/internal"
+)
+
+// packageCache keeps a copy of every downloaded driver tarball so it can be
+// installed again without network access. Entries are keyed by the package
+// URL without its query string, so signed or tokenized URLs for the same
+// artifact share one entry. The zero value is a disabled cache.
+type packageCache struct {
+	dir string
+}
+
+// defaultPackageCacheDir returns the cache location used by NewClient, or ""
+// if no user config directory can be located.
+func defaultPackageCacheDir() string {
+	dir, err := internal.GetCachePath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "packages")
+}
+
+func (c packageCache) path(u *url.URL) string {
+	key := *u
+	key.RawQuery = ""
+	sum := sha256.Sum256([]byte(registryURLKey(&key)))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16]), path.Base(u.Path))
+}
+
+// open returns the cached tarball for u, or an error wrapping ErrNotCached.
+func (c packageCache) open(u *url.URL) (*os.File, error) {
+	if c.dir == "" {
+		return nil, fmt.Errorf("no package cache configured for %s: %w", u, ErrNotCached)
+	}
+
+	f, err := os.Open(c.path(u))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("package %s has not been downloaded before: %w", u, ErrNotCached)
+		}
+		return nil, fmt.Errorf("failed to open cached package: %w", err)
+	}
+	return f, nil
+}
+
+// tee wraps a download body so the bytes read through it are also written to
+// the cache. The entry is only committed if the body was read to EOF, so an
+// interrupted download never leaves a truncated tarball behind.
+func (c packageCache) tee(u *url.URL, body io.ReadCloser) io.ReadCloser {
+	if c.dir == "" {
+		return body
+	}
+
+	dest := c.path(u)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return body
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*")
+	if err != nil {
+		return body
+	}
+	return &cachingBody{ReadCloser: body, tmp: tmp, dest: dest}
+}
+
+type cachingBody struct {
+	io.ReadCloser
+
+	tmp  *os.File
+	dest string
+	eof  bool
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && b.tmp != nil {
+		if _, werr := b.tmp.Write(p[:n]); werr != nil {
+			b.discard()
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		b.eof = true
+	}
+	return n, err
+}
+
+func (b *cachingBody) discard() {
+	b.tmp.Close()
+	os.Remove(b.tmp.Name())
+	b.tmp = nil
+}
+
+func (b *cachingBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.tmp == nil {
+		return err
+	}
+	if !b.eof {
+		b.discard()
+		return err
+	}
+
+	name := b.tmp.Name()
+	if cerr := b.tmp.Close(); cerr != nil {
+		os.Remove(name)
+	} else if rerr := os.Rename(name, b.dest); rerr != nil {
+		os.Remove(name)
+	}
+	b.tmp = nil
+	return err
+}
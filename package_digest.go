@@ -0,0 +1,122 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DigestMismatchError is returned when a downloaded package does not match
+// the sha256 digest or size listed for it in the registry index.
+type DigestMismatchError struct {
+	URL string
+	// Field is "sha256" or "size".
+	Field    string
+	Expected string
+	Actual   string
+}
+
+func (e *DigestMismatchError) Error() string {
+	return fmt.Sprintf("package %s does not match the registry index: expected %s %s, got %s",
+		e.URL, e.Field, e.Expected, e.Actual)
+}
+
+// fileDigest returns the hex-encoded sha256 digest and size of r's contents.
+func fileDigest(r io.Reader) (string, int64, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// verifyPackage wraps body, whose length is size or -1 if unknown, so that
+// it is checked against the digest and size the index lists for pkg as it is
+// read. A length that is already known to be wrong fails immediately;
+// otherwise reading past the expected size, or reaching EOF with the wrong
+// size or digest, returns a *DigestMismatchError instead of io.EOF. Packages
+// without a listed digest or size are returned unchanged.
+func verifyPackage(pkg PkgInfo, body io.ReadCloser, size int64) (io.ReadCloser, error) {
+	if pkg.SHA256 == "" && pkg.Size <= 0 {
+		return body, nil
+	}
+
+	v := &verifyingBody{
+		ReadCloser: body,
+		url:        pkg.Path.Redacted(),
+		sha256:     strings.ToLower(pkg.SHA256),
+		size:       pkg.Size,
+		h:          sha256.New(),
+	}
+	if size >= 0 && pkg.Size > 0 && size != pkg.Size {
+		body.Close()
+		return nil, v.sizeMismatch(size)
+	}
+	return v, nil
+}
+
+type verifyingBody struct {
+	io.ReadCloser
+
+	url    string
+	sha256 string
+	size   int64
+
+	h   hash.Hash
+	n   int64
+	err error
+}
+
+func (b *verifyingBody) sizeMismatch(actual int64) error {
+	return &DigestMismatchError{
+		URL:      b.url,
+		Field:    "size",
+		Expected: strconv.FormatInt(b.size, 10),
+		Actual:   strconv.FormatInt(actual, 10),
+	}
+}
+
+func (b *verifyingBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.h.Write(p[:n])
+	b.n += int64(n)
+
+	switch {
+	case b.size > 0 && b.n > b.size:
+		b.err = b.sizeMismatch(b.n)
+	case errors.Is(err, io.EOF):
+		if b.size > 0 && b.n != b.size {
+			b.err = b.sizeMismatch(b.n)
+		} else if actual := hex.EncodeToString(b.h.Sum(nil)); b.sha256 != "" && actual != b.sha256 {
+			b.err = &DigestMismatchError{URL: b.url, Field: "sha256", Expected: b.sha256, Actual: actual}
+		}
+	}
+	if b.err != nil {
+		return n, b.err
+	}
+	return n, err
+}
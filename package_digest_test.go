@@ -0,0 +1,153 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/columnar-tech/dbc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// digestIndex returns a registry index listing one linux_amd64 package of
+// test-driver-1 with the given digest and size.
+func digestIndex(sha string, size int) string {
+	return fmt.Sprintf(`name: digests
+drivers:
+  - name: Test Driver 1
+    path: test-driver-1
+    pkginfo:
+      - version: v1.0.0
+        packages:
+          - platform: linux_amd64
+            url: test-driver-1.tar.gz
+            sha256: %s
+            size: %d
+`, sha, size)
+}
+
+func TestDownloadVerifiesDigest(t *testing.T) {
+	tarball, err := os.ReadFile(filepath.Join("cmd", "dbc", "testdata", "test-driver-1.tar.gz"))
+	require.NoError(t, err)
+	sum := sha256.Sum256(tarball)
+	digest := hex.EncodeToString(sum[:])
+	badDigest := strings.Repeat("0", len(digest))
+
+	newServer := func(t *testing.T, index string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/index.yaml":
+				w.Write([]byte(index))
+			case "/test-driver-1.tar.gz":
+				w.Header().Set("Content-Length", fmt.Sprint(len(tarball)))
+				w.Write(tarball)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	getPackage := func(t *testing.T, c *dbc.Client) dbc.PkgInfo {
+		drivers, err := c.Search(t.Context(), "test-driver-1")
+		require.NoError(t, err)
+		pkg, err := findDriver(t, drivers, "test-driver-1").GetPackage(nil, "linux_amd64", false)
+		require.NoError(t, err)
+		return pkg
+	}
+
+	t.Run("matching digest", func(t *testing.T) {
+		srv := newServer(t, digestIndex(strings.ToUpper(digest), len(tarball)))
+		c, err := dbc.NewClient(dbc.WithBaseURL(srv.URL), dbc.WithIndexCacheDir(""), dbc.WithPackageCacheDir(""))
+		require.NoError(t, err)
+
+		pkg := getPackage(t, c)
+		assert.Equal(t, strings.ToUpper(digest), pkg.SHA256)
+		assert.Equal(t, int64(len(tarball)), pkg.Size)
+
+		body, err := c.Download(t.Context(), pkg)
+		require.NoError(t, err)
+		data, err := io.ReadAll(body)
+		body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, tarball, data)
+
+		f, err := c.DownloadPackage(t.Context(), pkg, nil)
+		require.NoError(t, err)
+		f.Close()
+		os.RemoveAll(filepath.Dir(f.Name()))
+	})
+
+	tests := []struct {
+		name  string
+		index string
+		field string
+	}{
+		{"wrong sha256", digestIndex(badDigest, len(tarball)), "sha256"},
+		{"wrong size", digestIndex(digest, len(tarball)+1), "size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, tt.index)
+			pkgDir := t.TempDir()
+			c, err := dbc.NewClient(dbc.WithBaseURL(srv.URL), dbc.WithIndexCacheDir(""), dbc.WithPackageCacheDir(pkgDir))
+			require.NoError(t, err)
+			pkg := getPackage(t, c)
+
+			_, err = c.DownloadPackage(t.Context(), pkg, nil)
+			var mismatch *dbc.DigestMismatchError
+			require.ErrorAs(t, err, &mismatch)
+			assert.Equal(t, tt.field, mismatch.Field)
+			assert.Contains(t, err.Error(), "does not match the registry index")
+
+			body, err := c.Download(t.Context(), pkg)
+			if err == nil {
+				_, err = io.ReadAll(body)
+				body.Close()
+			}
+			require.ErrorAs(t, err, &mismatch)
+
+			cached, err := filepath.Glob(filepath.Join(pkgDir, "*", "*"))
+			require.NoError(t, err)
+			assert.Empty(t, cached, "mismatched packages are not cached")
+		})
+	}
+
+	t.Run("local registry", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), []byte(digestIndex(badDigest, len(tarball))), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "test-driver-1.tar.gz"), tarball, 0o644))
+
+		c, err := dbc.NewClient(dbc.WithBaseURL(dir), dbc.WithIndexCacheDir(""))
+		require.NoError(t, err)
+		_, err = c.DownloadPackage(t.Context(), getPackage(t, c), nil)
+		var mismatch *dbc.DigestMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, "sha256", mismatch.Field)
+		assert.Equal(t, badDigest, mismatch.Expected)
+		assert.Equal(t, digest, mismatch.Actual)
+	})
+}
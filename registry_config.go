@@ -21,21 +21,69 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
+// Duration is a time.Duration that is written in configuration files as a
+// string such as "30s" or "2m".
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", text, err)
+	}
+	if v < 0 {
+		return fmt.Errorf("invalid duration %q: must not be negative", text)
+	}
+	*d = Duration(v)
+	return nil
+}
+
 // RegistryEntry is a single registry declared in a global config.toml or a
 // project's dbc.toml.
 type RegistryEntry struct {
+	// URL is an http(s) URL, a file:// URL, or a local directory path.
+	// Relative paths must start with "./" or "../".
 	URL  string `toml:"url"`
 	Name string `toml:"name,omitempty"`
+	// Timeout bounds how long fetching this registry's index may take.
+	// Zero inherits the global registry_timeout.
+	Timeout Duration `toml:"timeout,omitempty"`
+	// RequireSignature rejects the registry's index unless it is published
+	// with a valid index.yaml.sig.
+	RequireSignature bool `toml:"require_signature,omitempty"`
+	// SigningKeys are armored OpenPGP public keys, or paths of files
+	// containing them, trusted to sign this registry's index and packages.
+	// Relative paths are resolved like relative registry paths.
+	SigningKeys []string `toml:"signing_keys,omitempty"`
+	// Network overrides the global network settings for this registry's
+	// index and packages, and for authenticating with it.
+	Network NetworkConfig `toml:"network,omitempty"`
+	// ClientCert and ClientKey are the paths of a PEM certificate and
+	// private key to present to the registry's host when it asks for a TLS
+	// client certificate. Relative paths are resolved like relative
+	// registry paths.
+	ClientCert string `toml:"client_cert,omitempty"`
+	ClientKey  string `toml:"client_key,omitempty"`
 }
 
 // GlobalConfig is the schema of a user's global dbc config.toml.
 type GlobalConfig struct {
 	Registries      []RegistryEntry `toml:"registries"`
 	ReplaceDefaults bool            `toml:"replace_defaults,omitempty"`
+	// RegistryTimeout is the default index fetch timeout for registries
+	// that don't set their own. Zero uses the built-in default.
+	RegistryTimeout Duration `toml:"registry_timeout,omitempty"`
+	// Network configures the proxy, CA bundle and timeouts of every
+	// request dbc makes.
+	Network NetworkConfig `toml:"network,omitempty"`
 }
 
 // LoadGlobalConfig reads config.toml from configDir. It returns (nil, nil) if
@@ -60,6 +108,13 @@ func LoadGlobalConfig(configDir string) (*GlobalConfig, error) {
 	// here, because a project's dbc.toml may supply the entries at NewClient
 	// time. The "zero resulting registries" case is enforced after merging in
 	// NewClient so both library and CLI callers share the same semantics.
+	// Relative registry paths are relative to the config file, not to
+	// wherever dbc happens to be run from.
+	cfg.Registries = ResolveRegistryPaths(cfg.Registries, configDir)
+	cfg.Network = cfg.Network.resolvePaths(configDir)
+	if _, err := cfg.Network.transport(nil); err != nil {
+		return nil, fmt.Errorf("%s: [network]: %w", configPath, err)
+	}
 	for _, entry := range cfg.Registries {
 		if err := validateRegistryEntry(entry); err != nil {
 			return nil, fmt.Errorf("%s: %w", configPath, err)
@@ -70,22 +125,39 @@ func LoadGlobalConfig(configDir string) (*GlobalConfig, error) {
 }
 
 func validateRegistryEntry(e RegistryEntry) error {
-	if e.URL == "" {
-		return errors.New("registry entry has empty url")
+	if _, err := parseRegistryURL(e.URL); err != nil {
+		return err
 	}
-	u, err := url.Parse(e.URL)
-	if err != nil {
-		return fmt.Errorf("invalid registry URL %q: %w", e.URL, err)
+	for _, k := range e.SigningKeys {
+		if _, err := loadSigningKey(k); err != nil {
+			return fmt.Errorf("registry %s: %w", e.URL, err)
+		}
 	}
-	if u.Host == "" {
-		return fmt.Errorf("invalid registry URL %q: missing host", e.URL)
+	if _, err := e.Network.transport(nil); err != nil {
+		return fmt.Errorf("registry %s: network: %w", e.URL, err)
 	}
-	if u.Scheme != "http" && u.Scheme != "https" {
-		return fmt.Errorf("invalid registry URL %q: scheme must be http or https", e.URL)
+	if _, err := loadClientCertificate(e.ClientCert, e.ClientKey); err != nil {
+		return fmt.Errorf("registry %s: %w", e.URL, err)
 	}
 	return nil
 }
 
+// registryURLKey returns a canonical form of a registry URL that collapses
+// only truly no-op differences: scheme/host casing, trailing-slash on the
+// path, and fragments. Query, userinfo, and path segments are preserved
+// because they change the effective registry endpoint (tenant selectors,
+// credential-bearing URLs, path-mounted registries) and must be treated as
+// distinct registries.
+func registryURLKey(u *url.URL) string {
+	cp := *u
+	cp.Scheme = strings.ToLower(cp.Scheme)
+	cp.Host = strings.ToLower(cp.Host)
+	cp.Path = strings.TrimRight(cp.Path, "/")
+	cp.Fragment = ""
+	cp.RawFragment = ""
+	return cp.String()
+}
+
 // mergeRegistries combines project, global, and default registries into a
 // deduplicated list in priority order: project first, then global, then
 // built-in defaults (unless either global or project overrides with
@@ -105,34 +177,27 @@ func mergeRegistries(
 	seen := make(map[string]bool)
 	var result []Registry
 
-	// urlKey returns a canonical form that collapses only truly no-op
-	// differences: scheme/host casing, trailing-slash on the path, and
-	// fragments. Query, userinfo, and path segments are preserved because
-	// they change the effective registry endpoint (tenant selectors,
-	// credential-bearing URLs, path-mounted registries) and must be
-	// treated as distinct registries here.
-	urlKey := func(u *url.URL) string {
-		cp := *u
-		cp.Scheme = strings.ToLower(cp.Scheme)
-		cp.Host = strings.ToLower(cp.Host)
-		cp.Path = strings.TrimRight(cp.Path, "/")
-		cp.Fragment = ""
-		cp.RawFragment = ""
-		return cp.String()
-	}
-
 	addEntries := func(entries []RegistryEntry) {
 		for _, e := range entries {
-			u, err := url.Parse(e.URL)
-			if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			u, err := parseRegistryURL(e.URL)
+			if err != nil {
 				continue
 			}
-			key := urlKey(u)
+			key := registryURLKey(u)
 			if seen[key] {
 				continue
 			}
 			seen[key] = true
-			result = append(result, Registry{Name: e.Name, BaseURL: u})
+			result = append(result, Registry{
+				Name:             e.Name,
+				BaseURL:          u,
+				Timeout:          time.Duration(e.Timeout),
+				RequireSignature: e.RequireSignature,
+				SigningKeys:      e.SigningKeys,
+				Network:          e.Network,
+				ClientCert:       e.ClientCert,
+				ClientKey:        e.ClientKey,
+			})
 		}
 	}
 
@@ -144,7 +209,7 @@ func mergeRegistries(
 			if r.BaseURL == nil {
 				continue
 			}
-			key := urlKey(r.BaseURL)
+			key := registryURLKey(r.BaseURL)
 			if seen[key] {
 				continue
 			}
@@ -161,7 +226,8 @@ func mergeRegistries(
 // leave the default registry set untouched.
 //
 // WithBaseURL takes precedence over this option — when set, registries from
-// the global config are ignored.
+// the global config are ignored, though its [network] settings and the
+// client certificates of its registries still apply.
 func WithGlobalConfig(cfg *GlobalConfig) Option {
 	return func(c *clientConfig) {
 		c.globalConfig = cfg
@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/columnar-tech/dbc/config"
 	"github.com/stretchr/testify/assert"
@@ -49,6 +50,7 @@ func TestLoadGlobalConfig(t *testing.T) {
 		wantErr     string          // substring; "" means no error
 		wantEntries []RegistryEntry // expected ordered contents of cfg.Registries on success
 		wantReplace bool
+		wantTimeout Duration
 	}{
 		{
 			name: "two registries, no replace_defaults",
@@ -106,6 +108,32 @@ url = "https://custom.registry.io"
 			toml:    "[[registries]]\nurl = \"https:///onlypath\"\n",
 			wantErr: "missing host",
 		},
+		{
+			name: "timeouts parsed as durations",
+			toml: `
+registry_timeout = "15s"
+
+[[registries]]
+url = "https://slow.example.com"
+timeout = "2m"
+`,
+			wantEntries: []RegistryEntry{
+				{URL: "https://slow.example.com", Timeout: Duration(2 * time.Minute)},
+			},
+			wantTimeout: Duration(15 * time.Second),
+		},
+		{
+			name: "require_signature parsed",
+			toml: "[[registries]]\nurl = \"https://signed.example.com\"\nrequire_signature = true\n",
+			wantEntries: []RegistryEntry{
+				{URL: "https://signed.example.com", RequireSignature: true},
+			},
+		},
+		{
+			name:    "invalid timeout rejected",
+			toml:    "[[registries]]\nurl = \"https://example.com\"\ntimeout = \"soon\"\n",
+			wantErr: "invalid duration",
+		},
 		{
 			name:    "malformed TOML rejected",
 			toml:    "[[registries\nurl = \"https://example.com\"\n",
@@ -133,6 +161,7 @@ url = "https://custom.registry.io"
 			require.NotNil(t, cfg)
 			assert.Equal(t, tc.wantEntries, cfg.Registries)
 			assert.Equal(t, tc.wantReplace, cfg.ReplaceDefaults)
+			assert.Equal(t, tc.wantTimeout, cfg.RegistryTimeout)
 		})
 	}
 }
@@ -581,3 +610,149 @@ func TestSearchPriorityAcrossRegistryTiers(t *testing.T) {
 	assert.True(t, strings.HasPrefix(pkg.Path.String(), projectTier.URL),
 		"resolved package URL must be rooted at the project tier; got %q", pkg.Path.String())
 }
+
+// TestSearchFetchesRegistriesConcurrently proves a hanging registry neither
+// blocks the others nor the overall Search beyond its own timeout, and that
+// partial results keep registry priority order.
+func TestSearchFetchesRegistriesConcurrently(t *testing.T) {
+	index := func(path string) string {
+		return fmt.Sprintf("drivers:\n  - name: %s\n    path: %s\n", path, path)
+	}
+	serve := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/index.yaml" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, body)
+		}))
+	}
+
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	first := serve(index("first-driver"))
+	defer first.Close()
+	last := serve(index("last-driver"))
+	defer last.Close()
+
+	c := &Client{
+		httpClient:      http.DefaultClient,
+		registryTimeout: 5 * time.Second,
+		registries: []Registry{
+			{BaseURL: mustParseURL(first.URL)},
+			{BaseURL: mustParseURL(hanging.URL), Timeout: 200 * time.Millisecond},
+			{BaseURL: mustParseURL(last.URL)},
+		},
+	}
+
+	start := time.Now()
+	drivers, err := c.Search(t.Context(), "")
+	assert.Less(t, time.Since(start), 4*time.Second, "the hanging registry must be bounded by its own timeout")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), hanging.URL)
+	assert.Contains(t, err.Error(), "timed out after 200ms")
+	assert.NotContains(t, err.Error(), first.URL)
+
+	require.Len(t, drivers, 2)
+	assert.Equal(t, "first-driver", drivers[0].Path)
+	assert.Equal(t, "last-driver", drivers[1].Path)
+}
+
+func TestNewClientRegistryTimeout(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		c, err := NewClient(WithBaseURL("https://example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, defaultRegistryTimeout, c.registryTimeout)
+	})
+
+	t.Run("global config", func(t *testing.T) {
+		c, err := NewClient(WithGlobalConfig(&GlobalConfig{
+			Registries:      []RegistryEntry{{URL: "https://g.example.com", Timeout: Duration(time.Second)}},
+			RegistryTimeout: Duration(10 * time.Second),
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Second, c.registryTimeout)
+		assert.Equal(t, time.Second, c.Registries()[0].Timeout)
+	})
+
+	t.Run("option overrides global config", func(t *testing.T) {
+		c, err := NewClient(
+			WithGlobalConfig(&GlobalConfig{RegistryTimeout: Duration(10 * time.Second)}),
+			WithRegistryTimeout(3*time.Second),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, 3*time.Second, c.registryTimeout)
+	})
+}
+
+func TestNewClientRequireSignature(t *testing.T) {
+	c, err := NewClient(
+		WithGlobalConfig(&GlobalConfig{
+			Registries: []RegistryEntry{{URL: "https://g.example.com", RequireSignature: true}},
+		}),
+		WithProjectRegistries([]RegistryEntry{{URL: "https://p.example.com"}}, nil),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://p.example.com", "https://g.example.com"}, urls(c.Registries()[:2]))
+	assert.False(t, c.Registries()[0].RequireSignature)
+	assert.True(t, c.Registries()[1].RequireSignature)
+}
+
+func TestParseRegistryURL(t *testing.T) {
+	abs, err := filepath.Abs("vendor")
+	require.NoError(t, err)
+	wantVendor, err := fileURL(abs)
+	require.NoError(t, err)
+
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr string
+	}{
+		{raw: "https://r.example.com/sub", want: "https://r.example.com/sub"},
+		{raw: "file:///srv/drivers", want: "file:///srv/drivers"},
+		{raw: "file://localhost/srv/drivers", want: "file:///srv/drivers"},
+		{raw: "./vendor", want: wantVendor.String()},
+		{raw: "file://nfs-host/srv/drivers", wantErr: "remote file hosts are not supported"},
+		{raw: "file:relative/dir", wantErr: "absolute path"},
+		{raw: "vendor", wantErr: "scheme must be http or https"},
+		{raw: "ftp://example.com", wantErr: "scheme must be http or https"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.raw, func(t *testing.T) {
+			u, err := parseRegistryURL(tc.raw)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, u.String())
+		})
+	}
+}
+
+func TestLoadGlobalConfigRelativeRegistryPath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"),
+		[]byte("[[registries]]\nurl = \"./mirror\"\n\n[[registries]]\nurl = \"https://r.example.com\"\n"), 0o600))
+
+	cfg, err := LoadGlobalConfig(dir)
+	require.NoError(t, err)
+	require.Len(t, cfg.Registries, 2)
+	assert.Equal(t, filepath.Join(dir, "mirror"), cfg.Registries[0].URL)
+	assert.Equal(t, "https://r.example.com", cfg.Registries[1].URL)
+
+	regs := mergeRegistries(nil, nil, cfg.Registries, true, nil)
+	require.Len(t, regs, 2)
+	assert.Equal(t, "file", regs[0].BaseURL.Scheme)
+	assert.Equal(t, filepath.Join(dir, "mirror"), localPath(regs[0].BaseURL))
+}
@@ -0,0 +1,343 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRetries is how many times a failed request is retried when the
+// network settings don't say otherwise.
+const defaultRetries = 3
+
+var (
+	// retryBaseDelay is the wait before the first retry, doubled for each
+	// one after it.
+	retryBaseDelay = 500 * time.Millisecond
+	// maxRetryDelay caps both the backoff and a server's Retry-After.
+	maxRetryDelay = time.Minute
+)
+
+// RetryEvent describes a request that failed and is about to be retried.
+type RetryEvent struct {
+	// URL is the URL being requested.
+	URL *url.URL
+	// Attempt is the number of this retry, starting at 1, out of at most
+	// MaxRetries.
+	Attempt    int
+	MaxRetries int
+	// Wait is how long dbc waits before retrying.
+	Wait time.Duration
+	// Offset is the number of bytes of the response already received when
+	// a download is resumed partway through, or 0 if the request is being
+	// made again from the start.
+	Offset int64
+	// Err is why the request failed.
+	Err error
+}
+
+type retryNotifyKey struct{}
+
+// WithRetryNotify returns a copy of ctx that makes requests made with it call
+// fn before each retry, so callers can report the retries of a download.
+func WithRetryNotify(ctx context.Context, fn func(RetryEvent)) context.Context {
+	return context.WithValue(ctx, retryNotifyKey{}, fn)
+}
+
+func notifyRetry(ctx context.Context, e RetryEvent) {
+	if fn, ok := ctx.Value(retryNotifyKey{}).(func(RetryEvent)); ok && fn != nil {
+		fn(e)
+	}
+}
+
+// retryTransport retries GET and HEAD requests that fail with a transient
+// error or a 429 or 5xx response, waiting with exponential backoff or as
+// long as the server's Retry-After asks. A response body that fails partway
+// through is resumed with a Range request.
+type retryTransport struct {
+	http.RoundTripper
+	retries int
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.retries <= 0 || (req.Method != http.MethodGet && req.Method != http.MethodHead) || req.Body != nil {
+		return t.RoundTripper.RoundTrip(req)
+	}
+
+	resp, err := t.roundTrip(req, 0)
+	if err != nil || req.Method != http.MethodGet || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	resp.Body = &resumableBody{t: t, req: req, resp: resp, body: resp.Body}
+	return resp, nil
+}
+
+// roundTrip sends req, retrying as needed. A non-zero offset requests the
+// response from that byte on; whether the server honoured it is left to the
+// caller.
+func (t *retryTransport) roundTrip(req *http.Request, offset int64) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		r := req
+		if offset > 0 {
+			r = req.Clone(req.Context())
+			r.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+
+		resp, err := t.RoundTripper.RoundTrip(r)
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if !isTransient(req.Context(), err) {
+				return nil, err
+			}
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			wait = retryAfter(resp.Header.Get("Retry-After"))
+			err = fmt.Errorf("server responded %s", resp.Status)
+		default:
+			return resp, nil
+		}
+
+		if attempt >= t.retries {
+			if resp != nil {
+				return resp, nil
+			}
+			return nil, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		if wait == 0 {
+			wait = backoff(attempt)
+		}
+		notifyRetry(req.Context(), RetryEvent{
+			URL:        req.URL,
+			Attempt:    attempt + 1,
+			MaxRetries: t.retries,
+			Wait:       wait,
+			Offset:     offset,
+			Err:        err,
+		})
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// resumableBody is the body of a GET response that, if reading it fails
+// partway through, requests the rest of it with a Range request and carries
+// on, up to the transport's retry limit.
+type resumableBody struct {
+	t    *retryTransport
+	req  *http.Request
+	resp *http.Response
+	body io.ReadCloser
+
+	read    int64
+	resumes int
+	// err is a failure that arrived with the last bytes read, kept so those
+	// bytes are returned first and the body is resumed on the next Read.
+	err error
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	for {
+		var n int
+		err := b.err
+		if err == nil {
+			n, err = b.body.Read(p)
+			b.read += int64(n)
+		}
+		b.err = nil
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+		if b.resumes >= b.t.retries || !isTransient(b.req.Context(), err) {
+			return n, err
+		}
+		if n > 0 {
+			b.err = err
+			return n, nil
+		}
+		if rerr := b.resume(err); rerr != nil {
+			return 0, rerr
+		}
+	}
+}
+
+// resume replaces the failed body with the rest of the response, after
+// waiting out the backoff. Servers that ignore the Range request send the
+// whole response again, in which case the bytes already read are skipped,
+// unless its ETag shows the resource has changed in the meantime.
+func (b *resumableBody) resume(cause error) error {
+	b.body.Close()
+	b.resumes++
+
+	wait := backoff(b.resumes - 1)
+	notifyRetry(b.req.Context(), RetryEvent{
+		URL:        b.req.URL,
+		Attempt:    b.resumes,
+		MaxRetries: b.t.retries,
+		Wait:       wait,
+		Offset:     b.read,
+		Err:        cause,
+	})
+	if err := sleepContext(b.req.Context(), wait); err != nil {
+		b.body = io.NopCloser(strings.NewReader(""))
+		return err
+	}
+
+	req := b.req.Clone(b.req.Context())
+	// Only accept a partial response if the resource hasn't changed since
+	// the first part was read.
+	if etag := b.resp.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-Range", etag)
+	} else if lm := b.resp.Header.Get("Last-Modified"); lm != "" {
+		req.Header.Set("If-Range", lm)
+	}
+
+	resp, err := b.t.roundTrip(req, b.read)
+	if err != nil {
+		b.body = io.NopCloser(strings.NewReader(""))
+		return fmt.Errorf("failed to resume download after %s: %w", cause, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != b.read {
+			resp.Body.Close()
+			b.body = io.NopCloser(strings.NewReader(""))
+			return fmt.Errorf("failed to resume download: unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		b.body = resp.Body
+	case http.StatusOK:
+		if etag := b.resp.Header.Get("ETag"); etag != "" && resp.Header.Get("ETag") != etag {
+			resp.Body.Close()
+			b.body = io.NopCloser(strings.NewReader(""))
+			return fmt.Errorf("failed to resume download: %s changed on the server", b.req.URL.Redacted())
+		}
+		if _, err := io.CopyN(io.Discard, resp.Body, b.read); err != nil {
+			resp.Body.Close()
+			b.body = io.NopCloser(strings.NewReader(""))
+			return fmt.Errorf("failed to resume download: %w", err)
+		}
+		b.body = resp.Body
+	default:
+		resp.Body.Close()
+		b.body = io.NopCloser(strings.NewReader(""))
+		return fmt.Errorf("failed to resume download: server responded %s", resp.Status)
+	}
+	return nil
+}
+
+func (b *resumableBody) Close() error { return b.body.Close() }
+
+// contentRangeStart returns the first byte position of a Content-Range
+// header such as "bytes 100-199/200".
+func contentRangeStart(h string) (int64, bool) {
+	spec, ok := strings.CutPrefix(h, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	return n, err == nil
+}
+
+// isTransient reports whether err, from a request made with ctx, may succeed
+// if the request is tried again. Cancellation by the caller and TLS
+// verification failures, on either side, are never retried.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		invalidCert      x509.CertificateInvalidError
+		hostname         x509.HostnameError
+		verification     *tls.CertificateVerificationError
+		alert            tls.AlertError
+		opErr            *net.OpError
+		dns              *net.DNSError
+		netErr           net.Error
+	)
+	switch {
+	case errors.As(err, &unknownAuthority), errors.As(err, &invalidCert),
+		errors.As(err, &hostname), errors.As(err, &verification),
+		errors.As(err, &alert):
+		return false
+	case errors.As(err, &opErr) && opErr.Op == "remote error":
+		// A TLS alert from the server, such as one rejecting the client
+		// certificate.
+		return false
+	case errors.As(err, &dns):
+		return !dns.IsNotFound
+	case errors.Is(err, errReadTimeout), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, io.EOF), errors.As(err, &netErr):
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the wait a Retry-After header asks for, given either in
+// seconds or as an HTTP date, capped at maxRetryDelay. It returns 0 if h is
+// empty or invalid.
+func retryAfter(h string) time.Duration {
+	if h == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(h); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(h); err == nil {
+		d = time.Until(at)
+	}
+	return min(max(d, 0), maxRetryDelay)
+}
+
+// backoff returns the wait before retry number attempt+1: retryBaseDelay
+// doubled for each earlier retry, plus up to a quarter again of jitter so
+// clients that failed together don't all retry together.
+func backoff(attempt int) time.Duration {
+	d := min(retryBaseDelay<<min(attempt, 16), maxRetryDelay)
+	return d + rand.N(d/4+1)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
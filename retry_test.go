@@ -0,0 +1,265 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shortRetryDelay(t *testing.T) {
+	saved := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = saved })
+}
+
+// downloadWithRetries downloads the package at u, returning its contents and
+// the retries reported while doing so.
+func downloadWithRetries(t *testing.T, u string, network NetworkConfig) ([]byte, []RetryEvent, error) {
+	c, err := NewClient(WithBaseURL(u), WithIndexCacheDir(""), WithPackageCacheDir(""), WithNetwork(network))
+	require.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		events []RetryEvent
+	)
+	ctx := WithRetryNotify(t.Context(), func(e RetryEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+
+	pkg := PkgInfo{
+		Driver: Driver{Path: "test-driver", Registry: &c.Registries()[0]},
+		Path:   mustParseURL(u + "/test-driver.tar.gz"),
+	}
+	f, err := c.DownloadPackage(ctx, pkg, nil)
+	if err != nil {
+		return nil, events, err
+	}
+	defer os.RemoveAll(f.Name())
+	defer f.Close()
+	f.Seek(0, io.SeekStart)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return data, events, nil
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	shortRetryDelay(t)
+	payload := []byte("driver package contents")
+
+	// The server answers with each of failures in turn, then the payload.
+	var (
+		requests int
+		failures []int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if len(failures) > 0 {
+			w.WriteHeader(failures[0])
+			failures = failures[1:]
+			return
+		}
+		w.Write(payload)
+	}))
+	t.Cleanup(srv.Close)
+
+	failures = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+	data, events, err := downloadWithRetries(t, srv.URL, NetworkConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, payload, data)
+	assert.Equal(t, 3, requests)
+	require.Len(t, events, 2)
+	assert.Equal(t, 1, events[0].Attempt)
+	assert.Equal(t, defaultRetries, events[0].MaxRetries)
+	assert.EqualError(t, events[0].Err, "server responded 503 Service Unavailable")
+	assert.Equal(t, 2, events[1].Attempt)
+	assert.EqualError(t, events[1].Err, "server responded 429 Too Many Requests")
+
+	t.Run("exhausted", func(t *testing.T) {
+		failures = slices.Repeat([]int{http.StatusServiceUnavailable}, defaultRetries+1)
+		_, events, err := downloadWithRetries(t, srv.URL, NetworkConfig{})
+		assert.ErrorContains(t, err, "503 Service Unavailable")
+		assert.Len(t, events, defaultRetries)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		requests = 0
+		failures = []int{http.StatusServiceUnavailable}
+		retries := 0
+		_, events, err := downloadWithRetries(t, srv.URL, NetworkConfig{Retries: &retries})
+		assert.ErrorContains(t, err, "503 Service Unavailable")
+		assert.Empty(t, events)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(srv.Close)
+		_, events, err := downloadWithRetries(t, srv.URL, NetworkConfig{})
+		assert.ErrorContains(t, err, "404 Not Found")
+		assert.Empty(t, events)
+	})
+}
+
+func TestRetryResumesDownload(t *testing.T) {
+	shortRetryDelay(t)
+	payload := bytes.Repeat([]byte("0123456789"), 1000)
+	modtime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name        string
+		honourRange bool
+	}{
+		{"range", true},
+		{"no range", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ranges = append(ranges, r.Header.Get("Range"))
+				if len(ranges) == 1 {
+					// Drop the connection partway through the body.
+					w.Header().Set("Content-Length", "10000")
+					w.Header().Set("ETag", `"v1"`)
+					w.Write(payload[:4000])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler)
+				}
+				if !tt.honourRange {
+					r.Header.Del("Range")
+				}
+				w.Header().Set("ETag", `"v1"`)
+				http.ServeContent(w, r, "", modtime, bytes.NewReader(payload))
+			}))
+			t.Cleanup(srv.Close)
+
+			data, events, err := downloadWithRetries(t, srv.URL, NetworkConfig{})
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(payload, data), "downloaded package differs")
+			assert.Equal(t, []string{"", "bytes=4000-"}, ranges)
+			require.Len(t, events, 1)
+			assert.EqualValues(t, 4000, events[0].Offset)
+			assert.ErrorIs(t, events[0].Err, io.ErrUnexpectedEOF)
+		})
+	}
+
+	t.Run("changed", func(t *testing.T) {
+		var requests int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.Header().Set("Content-Length", "10000")
+				w.Header().Set("ETag", `"v1"`)
+				w.Write(payload[:4000])
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+			// If-Range no longer matches, so the whole new version is sent.
+			w.Header().Set("ETag", `"v2"`)
+			http.ServeContent(w, r, "", modtime, strings.NewReader(strings.Repeat("x", 10000)))
+		}))
+		t.Cleanup(srv.Close)
+
+		_, _, err := downloadWithRetries(t, srv.URL, NetworkConfig{})
+		assert.ErrorContains(t, err, "changed on the server")
+	})
+}
+
+// cutTransport cuts the body of the first response it returns off after
+// cut bytes, with the bytes and the error returned by the same Read, the way
+// a connection dropped in the middle of a body often surfaces.
+type cutTransport struct {
+	http.RoundTripper
+	cut  int64
+	done bool
+}
+
+func (t *cutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil || t.done {
+		return resp, err
+	}
+	t.done = true
+	resp.Body = &cutBody{ReadCloser: resp.Body, left: t.cut}
+	return resp, nil
+}
+
+type cutBody struct {
+	io.ReadCloser
+	left int64
+}
+
+func (b *cutBody) Read(p []byte) (int, error) {
+	if int64(len(p)) > b.left {
+		p = p[:b.left]
+	}
+	n, err := io.ReadFull(b.ReadCloser, p)
+	b.left -= int64(n)
+	if err == nil && b.left == 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func TestRetryResumesCutBody(t *testing.T) {
+	shortRetryDelay(t)
+	payload := bytes.Repeat([]byte("0123456789"), 1000)
+	modtime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "", modtime, bytes.NewReader(payload))
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: &retryTransport{
+		RoundTripper: &cutTransport{RoundTripper: http.DefaultTransport, cut: 4000},
+		retries:      defaultRetries,
+	}}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(payload, data), "downloaded body differs")
+	assert.Equal(t, []string{"", "bytes=4000-"}, ranges)
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 2*time.Second, retryAfter("2"))
+	assert.Equal(t, maxRetryDelay, retryAfter("3600"))
+	assert.Zero(t, retryAfter(""))
+	assert.Zero(t, retryAfter("soon"))
+	assert.Zero(t, retryAfter("-5"))
+
+	d := retryAfter(time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat))
+	assert.InDelta(t, 10*time.Second, d, float64(2*time.Second))
+}
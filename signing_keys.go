@@ -0,0 +1,129 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+)
+
+// isArmoredKey reports whether a signing key entry holds the key itself
+// rather than the path of a file containing it. Any armored block counts, so
+// that a pasted private key is rejected as such instead of being read as a
+// path.
+func isArmoredKey(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "-----BEGIN PGP ")
+}
+
+// resolveSigningKeyPaths returns a copy of keys with relative key file paths
+// made absolute against dir.
+func resolveSigningKeyPaths(keys []string, dir string) []string {
+	if keys == nil {
+		return nil
+	}
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		if !isArmoredKey(k) && !filepath.IsAbs(k) {
+			k = filepath.Join(dir, k)
+		}
+		out[i] = k
+	}
+	return out
+}
+
+// loadSigningKey parses a signing key entry: an armored public key, or the
+// path of a file containing one.
+func loadSigningKey(s string) (*crypto.Key, error) {
+	armored := s
+	if !isArmoredKey(s) {
+		data, err := os.ReadFile(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		armored = string(data)
+	}
+
+	key, err := crypto.NewKeyFromArmored(armored)
+	if err != nil {
+		if armored != s {
+			return nil, fmt.Errorf("invalid signing key %s: %w", s, err)
+		}
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	if key.IsPrivate() {
+		return nil, fmt.Errorf("signing key %s is a private key; configure its public key instead", key.GetHexKeyID())
+	}
+	return key, nil
+}
+
+// verifier returns the verifier for signatures made by r: one trusting the
+// embedded Columnar key plus r's signing keys. A nil registry, or one
+// without signing keys, trusts only the Columnar key.
+func (r *Registry) verifier() (crypto.PGPVerify, error) {
+	if r == nil || len(r.SigningKeys) == 0 {
+		return getVerifier()
+	}
+
+	columnar, err := crypto.NewKeyFromArmored(armoredPubKey)
+	if err != nil {
+		return nil, err
+	}
+	ring, err := crypto.NewKeyRing(columnar)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range r.SigningKeys {
+		key, err := loadSigningKey(s)
+		if err != nil {
+			return nil, err
+		}
+		if err := ring.AddKey(key); err != nil {
+			return nil, fmt.Errorf("failed to add signing key %s: %w", key.GetHexKeyID(), err)
+		}
+	}
+	return crypto.PGP().Verify().VerificationKeys(ring).New()
+}
+
+// VerifySignature returns nil if lib was signed by a key trusted for
+// packages from r, i.e. the Columnar key embedded in dbc or one of r's
+// SigningKeys, or an error otherwise. A nil registry, such as that of a
+// package installed from a local file, trusts only the Columnar key.
+func (r *Registry) VerifySignature(lib, sig io.Reader) error {
+	verifier, err := r.verifier()
+	if err != nil {
+		return err
+	}
+	return verifyDetached(verifier, lib, sig)
+}
+
+// verifyDetached checks the detached signature sig of data with verifier.
+func verifyDetached(verifier crypto.PGPVerify, data, sig io.Reader) error {
+	reader, err := verifier.VerifyingReader(data, sig, crypto.Auto)
+	if err != nil {
+		return err
+	}
+
+	result, err := reader.DiscardAllAndVerifySignature()
+	if err != nil {
+		return err
+	}
+
+	return result.SignatureError()
+}
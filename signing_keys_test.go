@@ -0,0 +1,170 @@
+// Copyright 2026 Columnar Technologies Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// armoredPublicKey returns the armored public half of key.
+func armoredPublicKey(t *testing.T, key *crypto.Key) string {
+	t.Helper()
+	pub, err := key.ToPublic()
+	require.NoError(t, err)
+	armored, err := pub.Armor()
+	require.NoError(t, err)
+	return armored
+}
+
+func TestRegistryVerifySignature(t *testing.T) {
+	key, sign := newSigningKey(t)
+	armored := armoredPublicKey(t, key)
+	otherKey, signOther := newSigningKey(t)
+
+	keyFile := filepath.Join(t.TempDir(), "registry.asc")
+	require.NoError(t, os.WriteFile(keyFile, []byte(armored), 0o644))
+
+	lib := []byte("driver library")
+	verify := func(r *Registry, sig []byte) error {
+		return r.VerifySignature(bytes.NewReader(lib), bytes.NewReader(sig))
+	}
+
+	t.Run("armored key", func(t *testing.T) {
+		assert.NoError(t, verify(&Registry{SigningKeys: []string{armored}}, sign(lib)))
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		assert.NoError(t, verify(&Registry{SigningKeys: []string{keyFile}}, sign(lib)))
+	})
+
+	t.Run("any of several keys", func(t *testing.T) {
+		r := &Registry{SigningKeys: []string{armoredPublicKey(t, otherKey), keyFile}}
+		assert.NoError(t, verify(r, sign(lib)))
+		assert.NoError(t, verify(r, signOther(lib)))
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		assert.Error(t, verify(&Registry{SigningKeys: []string{armored}}, signOther(lib)))
+		assert.Error(t, verify(&Registry{}, sign(lib)), "registries without keys trust only the Columnar key")
+		assert.Error(t, verify(nil, sign(lib)))
+	})
+
+	t.Run("tampered library", func(t *testing.T) {
+		r := &Registry{SigningKeys: []string{armored}}
+		err := r.VerifySignature(bytes.NewReader(append(lib, '!')), bytes.NewReader(sign(lib)))
+		assert.Error(t, err)
+	})
+
+	t.Run("missing key file", func(t *testing.T) {
+		r := &Registry{SigningKeys: []string{filepath.Join(t.TempDir(), "missing.asc")}}
+		assert.ErrorContains(t, verify(r, sign(lib)), "failed to read signing key")
+	})
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	key, _ := newSigningKey(t)
+	armored := armoredPublicKey(t, key)
+
+	got, err := loadSigningKey("\n" + armored)
+	require.NoError(t, err)
+	assert.Equal(t, key.GetFingerprint(), got.GetFingerprint())
+
+	private, err := key.Armor()
+	require.NoError(t, err)
+	_, err = loadSigningKey(private)
+	assert.ErrorContains(t, err, "is a private key")
+
+	notAKey := filepath.Join(t.TempDir(), "not-a-key.asc")
+	require.NoError(t, os.WriteFile(notAKey, []byte("hello"), 0o644))
+	_, err = loadSigningKey(notAKey)
+	assert.ErrorContains(t, err, "invalid signing key "+notAKey)
+}
+
+func TestRegistrySigningKeysConfig(t *testing.T) {
+	key, sign := newSigningKey(t)
+	armored := armoredPublicKey(t, key)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "keys"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keys", "acme.asc"), []byte(armored), 0o644))
+
+	t.Run("key paths are relative to the config file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"), []byte(`
+[[registries]]
+url = "https://acme.example.com"
+signing_keys = ["keys/acme.asc"]
+`), 0o644))
+
+		cfg, err := LoadGlobalConfig(dir)
+		require.NoError(t, err)
+		require.Len(t, cfg.Registries, 1)
+		assert.Equal(t, []string{filepath.Join(dir, "keys", "acme.asc")}, cfg.Registries[0].SigningKeys)
+
+		c, err := NewClient(WithGlobalConfig(cfg))
+		require.NoError(t, err)
+		reg := c.Registries()[0]
+		assert.Equal(t, cfg.Registries[0].SigningKeys, reg.SigningKeys)
+
+		lib := []byte("driver library")
+		assert.NoError(t, reg.VerifySignature(bytes.NewReader(lib), bytes.NewReader(sign(lib))))
+	})
+
+	t.Run("armored keys are kept as is", func(t *testing.T) {
+		entries := ResolveRegistryPaths([]RegistryEntry{{URL: "https://acme.example.com", SigningKeys: []string{armored}}}, dir)
+		assert.Equal(t, []string{armored}, entries[0].SigningKeys)
+	})
+
+	t.Run("invalid key rejected", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"), []byte(`
+[[registries]]
+url = "https://acme.example.com"
+signing_keys = ["keys/missing.asc"]
+`), 0o644))
+
+		_, err := LoadGlobalConfig(dir)
+		assert.ErrorContains(t, err, "registry https://acme.example.com: failed to read signing key")
+
+		_, err = NewClient(WithProjectRegistries([]RegistryEntry{{
+			URL:         "https://acme.example.com",
+			SigningKeys: []string{"-----BEGIN PGP PUBLIC KEY BLOCK-----\nbogus"},
+		}}, nil))
+		assert.ErrorContains(t, err, "invalid signing key")
+	})
+}
+
+func TestIndexSignatureRegistryKey(t *testing.T) {
+	key, sign := newSigningKey(t)
+
+	dir := t.TempDir()
+	index := []byte(signedTestIndex)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), index, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, indexSignatureFile), sign(index), 0o644))
+
+	search := func(keys ...string) error {
+		c := &Client{registries: []Registry{{BaseURL: mustParseURL("file://" + filepath.ToSlash(dir)), SigningKeys: keys}}}
+		_, err := c.Search(t.Context(), "")
+		return err
+	}
+
+	assert.ErrorIs(t, search(), ErrInvalidIndexSignature)
+	assert.NoError(t, search(armoredPublicKey(t, key)))
+}
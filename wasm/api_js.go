@@ -247,6 +247,10 @@ func jsResolve(args []js.Value) func() (any, error) {
 			if d.Path != name {
 				continue
 			}
+			d, err := c.LoadDriver(context.Background(), d)
+			if err != nil {
+				return nil, err
+			}
 			versions := []string{}
 			for _, v := range d.Versions(platform) {
 				versions = append(versions, v.String())